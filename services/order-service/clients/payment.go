@@ -0,0 +1,186 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// RefundRequest asks payment-service to return money for an order; Reference defaults to its cancellation refund
+type RefundRequest struct {
+	Reference  string  `json:"reference,omitempty"`
+	OrderID    int64   `json:"order_id"`
+	UserID     int64   `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+}
+
+// PaymentRequest opens a payment; the same Reference always returns the same payment
+type PaymentRequest struct {
+	Reference   string  `json:"reference"`
+	UserID      int64   `json:"user_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// Payment is payment-service's view of one payment
+type Payment struct {
+	ID        int64   `json:"id"`
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+	Status    string  `json:"status"` // PENDING, PAID, FAILED, CANCELLED
+}
+
+// PaymentClient talks to payment-service, which owns the money movement
+type PaymentClient interface {
+	// RequestRefund hands the refund over; it is safe to call again for the same order
+	RequestRefund(req RefundRequest) error
+	CreatePayment(req PaymentRequest) (*Payment, error)
+	GetPayment(id int64) (*Payment, error)
+	// CancelPayment withdraws a pending payment; the returned payment shows if it was paid meanwhile
+	CancelPayment(id int64) (*Payment, error)
+}
+
+type paymentClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewPaymentClient() PaymentClient {
+	base := os.Getenv("PAYMENT_SERVICE_URL")
+	if base == "" {
+		base = "http://localhost:8082"
+	}
+	return &paymentClient{
+		baseURL: strings.TrimRight(base, "/"),
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+/*
+authorize signs the request as this service acting for userID (0 when for nobody in particular).
+payment-service trusts the SERVICE role to name any user; its idempotency keys are scoped by that user.
+*/
+func authorize(req *http.Request, userID int64) error {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return errors.New("JWT_SECRET not set")
+	}
+	claims := jwt.MapClaims{
+		"sub":  userID,
+		"role": "SERVICE",
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+signed)
+	return nil
+}
+
+func (c *paymentClient) RequestRefund(req RefundRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/refunds", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := authorize(httpReq, req.UserID); err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	// one refund per reference, so retries of the hand-off collapse on the payment side
+	key := req.Reference
+	if key == "" {
+		key = fmt.Sprintf("order-%d-refund", req.OrderID)
+	}
+	httpReq.Header.Set("Idempotency-Key", key)
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("payment-service returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *paymentClient) CreatePayment(req PaymentRequest) (*Payment, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(httpReq, req.UserID); err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", req.Reference)
+	return c.doPayment(httpReq, http.StatusCreated)
+}
+
+func (c *paymentClient) GetPayment(id int64) (*Payment, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(httpReq, 0); err != nil {
+		return nil, err
+	}
+	return c.doPayment(httpReq, http.StatusOK)
+}
+
+func (c *paymentClient) CancelPayment(id int64) (*Payment, error) {
+	httpReq, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(httpReq, 0); err != nil {
+		return nil, err
+	}
+	// 409 means it is no longer pending; the body still says what it is
+	return c.doPayment(httpReq, http.StatusOK, http.StatusConflict)
+}
+
+// doPayment sends req and reads {"payment": {...}} from an accepted status
+func (c *paymentClient) doPayment(req *http.Request, accept ...int) (*Payment, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ok := false
+	for _, code := range accept {
+		if resp.StatusCode == code {
+			ok = true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("payment-service returned %d", resp.StatusCode)
+	}
+	var out struct {
+		Payment *Payment `json:"payment"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Payment == nil {
+		return nil, fmt.Errorf("payment-service returned no payment")
+	}
+	return out.Payment, nil
+}
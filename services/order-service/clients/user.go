@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Address is user-service's view of one saved address
+type Address struct {
+	ID           int64    `json:"id"`
+	UserID       int64    `json:"user_id"`
+	AddressLine1 string   `json:"address_line1,omitempty"`
+	AddressLine2 string   `json:"address_line2,omitempty"`
+	City         string   `json:"city,omitempty"`
+	State        string   `json:"state,omitempty"`
+	Pincode      string   `json:"pincode,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+// UserClient talks to user-service, which owns customers and their addresses
+type UserClient interface {
+	// GetAddress returns nil, nil when the address does not exist
+	GetAddress(id int64) (*Address, error)
+}
+
+type userClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewUserClient() UserClient {
+	base := os.Getenv("USER_SERVICE_URL")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return &userClient{
+		baseURL: strings.TrimRight(base, "/"),
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (c *userClient) GetAddress(id int64) (*Address, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/addresses/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(httpReq, 0); err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service returned %d", resp.StatusCode)
+	}
+	var out struct {
+		Data struct {
+			Address *Address `json:"address"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Data.Address, nil
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CartController struct {
+	svc services.CartService
+}
+
+func NewCartController(s services.CartService) *CartController {
+	return &CartController{svc: s}
+}
+
+type addCartItemReq struct {
+	MenuItemId          int64           `json:"menuItemId" binding:"required"`
+	Qty                 int             `json:"qty" binding:"required"`
+	Options             json.RawMessage `json:"options,omitempty"`
+	SpecialInstructions *string         `json:"specialInstructions,omitempty"`
+	// ReplaceCart empties a cart holding another restaurant's items instead of failing
+	ReplaceCart bool `json:"replaceCart,omitempty"`
+}
+
+type updateCartItemReq struct {
+	Qty                 *int            `json:"qty" binding:"required"`
+	Options             json.RawMessage `json:"options,omitempty"`
+	SpecialInstructions *string         `json:"specialInstructions,omitempty"`
+}
+
+type checkoutCartReq struct {
+	OrderType           string     `json:"orderType,omitempty"`
+	DiningSessionID     *int64     `json:"diningSessionId,omitempty"`
+	TableToken          string     `json:"tableToken,omitempty"` // the table's QR token, required with diningSessionId
+	DeliveryAddress     string     `json:"deliveryAddress"`
+	DeliveryLatitude    *float64   `json:"deliveryLatitude"`
+	DeliveryLongitude   *float64   `json:"deliveryLongitude"`
+	DeliveryAddressId   *int64     `json:"deliveryAddressId,omitempty"`
+	TipAmount           float64    `json:"tipAmount"`
+	TotalAmount         float64    `json:"totalAmount"`
+	PromoCode           string     `json:"promoCode,omitempty"`
+	DeliveryFeeQuoteId  *int64     `json:"deliveryFeeQuoteId,omitempty"`
+	SpecialInstructions *string    `json:"specialInstructions"`
+	ScheduledFor        *time.Time `json:"scheduledFor,omitempty"`
+}
+
+// GET /cart
+func (cc *CartController) Get(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	cart, err := cc.svc.GetCart(tokenUID)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to fetch cart", err.Error())
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cart fetched", gin.H{"cart": cart})
+}
+
+// POST /cart/items
+func (cc *CartController) AddItem(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req addCartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	item := &models.CartItem{
+		MenuItemID:          req.MenuItemId,
+		Quantity:            req.Qty,
+		Options:             req.Options,
+		SpecialInstructions: req.SpecialInstructions,
+	}
+	cart, err := cc.svc.AddItem(tokenUID, item, req.ReplaceCart)
+	if err != nil {
+		sendCartError(c, err, "failed to add item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item added", gin.H{"cart": cart})
+}
+
+// PUT /cart/items/:item_id
+func (cc *CartController) UpdateItem(c *gin.Context) {
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid item id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req updateCartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	item := &models.CartItem{
+		ID:                  itemID,
+		Quantity:            *req.Qty,
+		Options:             req.Options,
+		SpecialInstructions: req.SpecialInstructions,
+	}
+	cart, err := cc.svc.UpdateItem(tokenUID, item)
+	if err != nil {
+		sendCartError(c, err, "failed to update item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item updated", gin.H{"cart": cart})
+}
+
+// DELETE /cart/items/:item_id
+func (cc *CartController) RemoveItem(c *gin.Context) {
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid item id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	cart, err := cc.svc.RemoveItem(tokenUID, itemID)
+	if err != nil {
+		sendCartError(c, err, "failed to remove item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item removed", gin.H{"cart": cart})
+}
+
+// DELETE /cart
+func (cc *CartController) Clear(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	if err := cc.svc.Clear(tokenUID); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to clear cart", err.Error())
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cart cleared", nil)
+}
+
+// POST /cart/checkout
+func (cc *CartController) Checkout(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req checkoutCartReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	order, err := cc.svc.Checkout(tokenUID, services.CartCheckout{
+		OrderType:           req.OrderType,
+		DiningSessionID:     req.DiningSessionID,
+		TableToken:          req.TableToken,
+		DeliveryAddress:     req.DeliveryAddress,
+		DeliveryLatitude:    req.DeliveryLatitude,
+		DeliveryLongitude:   req.DeliveryLongitude,
+		DeliveryAddressID:   req.DeliveryAddressId,
+		TipAmount:           req.TipAmount,
+		TotalAmount:         req.TotalAmount,
+		PromoCode:           req.PromoCode,
+		DeliveryFeeQuoteID:  req.DeliveryFeeQuoteId,
+		SpecialInstructions: req.SpecialInstructions,
+		ScheduledFor:        req.ScheduledFor,
+	})
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "cart does not match current menu", verr.Problems)
+			return
+		}
+		sendCartError(c, err, "failed to place order")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "order placed", gin.H{
+		"orderId":               order.ID,
+		"orderNumber":           order.OrderNumber,
+		"status":                order.OrderStatus,
+		"scheduledFor":          order.ScheduledFor,
+		"estimatedDeliveryTime": order.EstimatedDeliveryAt,
+		"createdAt":             order.CreatedAt,
+		"subtotal":              order.SubtotalAmount,
+		"taxAmount":             order.TaxAmount,
+		"totalAmount":           order.TotalAmount,
+		"items":                 order.Items,
+	})
+}
+
+type reorderReq struct {
+	Target        string `json:"target,omitempty"` // CART (default) or ORDER
+	ReplaceCart   bool   `json:"replaceCart,omitempty"`
+	AcceptChanges bool   `json:"acceptChanges,omitempty"`
+	checkoutCartReq
+}
+
+/*
+POST /orders/:id/reorder - order a past order again.
+By default the lines go into the cart; target ORDER places the order straight away with
+the checkout fields. Lines that are gone, out of stock or whose options no longer fit are
+listed in skipped, repriced lines in priceChanges.
+*/
+func (cc *CartController) Reorder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req reorderReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	res, err := cc.svc.Reorder(tokenUID, orderID, services.ReorderRequest{
+		Target:        strings.ToUpper(strings.TrimSpace(req.Target)),
+		ReplaceCart:   req.ReplaceCart,
+		AcceptChanges: req.AcceptChanges,
+		Checkout: services.CartCheckout{
+			OrderType:           req.OrderType,
+			DiningSessionID:     req.DiningSessionID,
+			TableToken:          req.TableToken,
+			DeliveryAddress:     req.DeliveryAddress,
+			DeliveryLatitude:    req.DeliveryLatitude,
+			DeliveryLongitude:   req.DeliveryLongitude,
+			DeliveryAddressID:   req.DeliveryAddressId,
+			TipAmount:           req.TipAmount,
+			TotalAmount:         req.TotalAmount,
+			PromoCode:           req.PromoCode,
+			DeliveryFeeQuoteID:  req.DeliveryFeeQuoteId,
+			SpecialInstructions: req.SpecialInstructions,
+			ScheduledFor:        req.ScheduledFor,
+		},
+	})
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "order does not match current menu", verr.Problems)
+			return
+		}
+		switch err.Error() {
+		case "invalid_target":
+			utils.SendError(c, http.StatusBadRequest, "target must be CART or ORDER", nil)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "nothing_to_reorder":
+			utils.SendError(c, http.StatusUnprocessableEntity, "none of the items can be ordered any more", res)
+		case "reorder_changed":
+			utils.SendError(c, http.StatusConflict, "some items changed since this order; send acceptChanges to order the rest", res)
+		case "restaurant_mismatch":
+			utils.SendError(c, http.StatusConflict, "cart holds items from another restaurant; send replaceCart to start over", res)
+		default:
+			sendCartError(c, err, "failed to reorder")
+		}
+		return
+	}
+	if res.Order != nil {
+		utils.SendSuccess(c, http.StatusCreated, "order placed", res)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "items added to cart", res)
+}
+
+// sendCartError maps cart service errors to responses
+func sendCartError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "invalid_quantity":
+		utils.SendError(c, http.StatusBadRequest, "qty must be between 1 and 50", nil)
+	case "invalid_options":
+		utils.SendError(c, http.StatusBadRequest, "options must be valid JSON", nil)
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "item not found", nil)
+	case "item_unavailable":
+		utils.SendError(c, http.StatusUnprocessableEntity, "menu item is not available", nil)
+	case "restaurant_mismatch":
+		utils.SendError(c, http.StatusConflict, "cart holds items from another restaurant; send replaceCart to start over", nil)
+	case "cart_empty":
+		utils.SendError(c, http.StatusBadRequest, "cart is empty", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type DeliveryFeeController struct {
+	svc services.DeliveryFeeService
+}
+
+func NewDeliveryFeeController(s services.DeliveryFeeService) *DeliveryFeeController {
+	return &DeliveryFeeController{svc: s}
+}
+
+type deliveryFeeQuoteReq struct {
+	RestaurantId      int64    `json:"restaurantId" binding:"required"`
+	DeliveryLatitude  *float64 `json:"deliveryLatitude"`
+	DeliveryLongitude *float64 `json:"deliveryLongitude"`
+	Subtotal          float64  `json:"subtotal"` // basket value; decides the small order surcharge and free delivery
+}
+
+type putDeliveryFeeConfigReq struct {
+	City                  string                   `json:"city"` // "" for the default
+	Slabs                 []models.DeliveryFeeSlab `json:"slabs" binding:"required"`
+	SmallOrderThreshold   float64                  `json:"smallOrderThreshold"`
+	SmallOrderSurcharge   float64                  `json:"smallOrderSurcharge"`
+	FreeDeliveryThreshold *float64                 `json:"freeDeliveryThreshold,omitempty"`
+	SurgeMaxMultiplier    float64                  `json:"surgeMaxMultiplier"` // omit or 1 to turn surge off
+}
+
+type riderAvailabilityReq struct {
+	City        string   `json:"city" binding:"required"`
+	IsAvailable bool     `json:"isAvailable"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+}
+
+/*
+POST /delivery-fee/quote - what delivering to a drop point costs right now. Pass the
+returned quote id as deliveryFeeQuoteId when placing the order to be charged this fee.
+*/
+func (dc *DeliveryFeeController) Quote(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req deliveryFeeQuoteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	q, fee, err := dc.svc.Quote(tokenUID, req.RestaurantId, req.DeliveryLatitude, req.DeliveryLongitude, req.Subtotal)
+	if err != nil {
+		sendDeliveryFeeError(c, err, "failed to quote delivery fee")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "delivery fee quoted", gin.H{
+		"quoteId":   q.ID,
+		"quote":     q,
+		"breakdown": fee,
+		"fee":       fee.Fee,
+		"expiresAt": q.ExpiresAt,
+	})
+}
+
+// GET /delivery-fee/configs (admin)
+func (dc *DeliveryFeeController) ListConfigs(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	list, err := dc.svc.ListConfigs(tokenUID, roleStr)
+	if err != nil {
+		sendDeliveryFeeError(c, err, "failed to fetch delivery fee configs")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "delivery fee configs fetched", gin.H{"configs": list})
+}
+
+// PUT /delivery-fee/configs (admin) - create or replace a city's config
+func (dc *DeliveryFeeController) PutConfig(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req putDeliveryFeeConfigReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	cfg, err := dc.svc.PutConfig(&models.DeliveryFeeConfig{
+		City:                  req.City,
+		Slabs:                 req.Slabs,
+		SmallOrderThreshold:   req.SmallOrderThreshold,
+		SmallOrderSurcharge:   req.SmallOrderSurcharge,
+		FreeDeliveryThreshold: req.FreeDeliveryThreshold,
+		SurgeMaxMultiplier:    req.SurgeMaxMultiplier,
+	}, tokenUID, roleStr)
+	if err != nil {
+		sendDeliveryFeeError(c, err, "failed to save delivery fee config")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "delivery fee config saved", gin.H{"config": cfg})
+}
+
+// PUT /riders/availability - rider heartbeat; riders not heard from for a few minutes count as offline
+func (dc *DeliveryFeeController) UpdateAvailability(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req riderAvailabilityReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	p := &models.RiderPresence{
+		City:        req.City,
+		IsAvailable: req.IsAvailable,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+	}
+	if err := dc.svc.UpdateRiderPresence(p, tokenUID, roleStr); err != nil {
+		sendDeliveryFeeError(c, err, "failed to update availability")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "availability updated", gin.H{"presence": p})
+}
+
+// sendDeliveryFeeError maps delivery fee service errors to responses
+func sendDeliveryFeeError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "restaurant not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "out_of_delivery_area":
+		utils.SendError(c, http.StatusUnprocessableEntity, "address is outside the delivery area", nil)
+	case "invalid_location":
+		utils.SendError(c, http.StatusBadRequest, "latitude and longitude go together", nil)
+	case "location_required":
+		utils.SendError(c, http.StatusBadRequest, "deliveryLatitude and deliveryLongitude required", nil)
+	case "restaurant_unlocated":
+		utils.SendError(c, http.StatusUnprocessableEntity, "restaurant has no location to deliver from", nil)
+	case "invalid_subtotal":
+		utils.SendError(c, http.StatusBadRequest, "subtotal must be >= 0", nil)
+	case "invalid_slabs":
+		utils.SendError(c, http.StatusBadRequest, "slabs must be non-empty, with up_to_km positive and ascending and fees not negative", nil)
+	case "invalid_amount":
+		utils.SendError(c, http.StatusBadRequest, "thresholds and surcharge must be >= 0", nil)
+	case "invalid_surge":
+		utils.SendError(c, http.StatusBadRequest, "surgeMaxMultiplier must be between 1 and 5", nil)
+	case "city_required":
+		utils.SendError(c, http.StatusBadRequest, "city required", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
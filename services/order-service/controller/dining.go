@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type DiningController struct {
+	svc services.DiningService
+}
+
+func NewDiningController(s services.DiningService) *DiningController {
+	return &DiningController{svc: s}
+}
+
+/*
+POST /dine-in/:qr_token/session - what the table's QR code opens.
+Returns the open session (creating it on the first scan) with the tab so far; orders for
+the table are then placed with POST /orders or /cart/checkout and its diningSessionId.
+*/
+func (dc *DiningController) Scan(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	session, err := dc.svc.Scan(c.Param("qr_token"), tokenUID)
+	if err != nil {
+		sendDiningError(c, err, "failed to open dining session")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "dining session open", gin.H{"session": session})
+}
+
+// GET /dining-sessions/:id - running tab for staff
+func (dc *DiningController) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid session id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	session, err := dc.svc.GetSession(id, tokenUID, roleStr)
+	if err != nil {
+		sendDiningError(c, err, "failed to fetch dining session")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "dining session fetched", gin.H{"session": session})
+}
+
+// GET /restaurants/:id/dining-sessions?status=OPEN
+func (dc *DiningController) ListForRestaurant(c *gin.Context) {
+	restaurantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	list, err := dc.svc.ListSessions(restaurantID, c.Query("status"), tokenUID, roleStr)
+	if err != nil {
+		sendDiningError(c, err, "failed to list dining sessions")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "dining sessions fetched", gin.H{"sessions": list})
+}
+
+// POST /dining-sessions/:id/close - staff close the table and get the consolidated bill
+func (dc *DiningController) Close(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid session id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	session, err := dc.svc.CloseSession(id, tokenUID, roleStr)
+	if err != nil {
+		sendDiningError(c, err, "failed to close dining session")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "dining session closed", gin.H{"session": session, "bill": session.Bill})
+}
+
+type splitShareReq struct {
+	Label       string             `json:"label"`
+	PayerUserId *int64             `json:"payerUserId,omitempty"`
+	Lines       []models.ShareLine `json:"lines,omitempty"`
+	Amount      float64            `json:"amount,omitempty"`
+}
+
+type splitBillReq struct {
+	Mode   string          `json:"mode" binding:"required"` // EVEN, ITEM or CUSTOM
+	Parts  int             `json:"parts,omitempty"`
+	Shares []splitShareReq `json:"shares,omitempty"`
+}
+
+/*
+POST /dining-sessions/:id/split - split a closed session's bill.
+EVEN takes parts; ITEM takes shares with bill lines ({line, quantity} indexes into bill.lines);
+CUSTOM takes shares with amounts that add up to the bill total. Each share gets a payment.
+*/
+func (dc *DiningController) Split(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid session id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req splitBillReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	split := services.SplitRequest{Mode: req.Mode, Parts: req.Parts}
+	for _, sh := range req.Shares {
+		split.Shares = append(split.Shares, services.SplitShare{
+			Label:       sh.Label,
+			PayerUserID: sh.PayerUserId,
+			Lines:       sh.Lines,
+			Amount:      sh.Amount,
+		})
+	}
+	session, err := dc.svc.SplitBill(id, split, tokenUID, roleStr)
+	if err != nil {
+		sendDiningError(c, err, "failed to split bill")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "bill split", gin.H{"session": session, "shares": session.Shares})
+}
+
+// sendDiningError maps dining service errors to responses
+func sendDiningError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "invalid_status":
+		utils.SendError(c, http.StatusBadRequest, "status must be OPEN, CLOSED or SETTLED", nil)
+	case "table_inactive":
+		utils.SendError(c, http.StatusConflict, "this table is not taking orders", nil)
+	case "session_closed":
+		utils.SendError(c, http.StatusConflict, "dining session is already closed", nil)
+	case "orders_in_progress":
+		utils.SendError(c, http.StatusConflict, "serve or cancel all orders before closing", nil)
+	case "session_open":
+		utils.SendError(c, http.StatusConflict, "close the dining session before splitting the bill", nil)
+	case "session_settled":
+		utils.SendError(c, http.StatusConflict, "dining session is already settled", nil)
+	case "split_locked":
+		utils.SendError(c, http.StatusConflict, "a share is already paid; the split cannot change", nil)
+	case "nothing_to_pay":
+		utils.SendError(c, http.StatusUnprocessableEntity, "the bill has nothing to pay", nil)
+	case "invalid_split_mode":
+		utils.SendError(c, http.StatusBadRequest, "mode must be EVEN, ITEM or CUSTOM", nil)
+	case "invalid_parts":
+		utils.SendError(c, http.StatusBadRequest, "parts must be between 1 and 20", nil)
+	case "invalid_shares":
+		utils.SendError(c, http.StatusBadRequest, "between 1 and 20 shares required", nil)
+	case "invalid_share_line":
+		utils.SendError(c, http.StatusBadRequest, "share lines must reference bill lines with a positive quantity", nil)
+	case "items_unassigned":
+		utils.SendError(c, http.StatusUnprocessableEntity, "every bill line must be assigned exactly once in full", nil)
+	case "invalid_amount":
+		utils.SendError(c, http.StatusUnprocessableEntity, "every share must pay a positive amount", nil)
+	case "amounts_mismatch":
+		utils.SendError(c, http.StatusUnprocessableEntity, "share amounts must add up to the bill total", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
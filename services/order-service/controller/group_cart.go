@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// guests identify themselves with the token they received on join
+const participantTokenHeader = "X-Participant-Token"
+
+type GroupCartController struct {
+	svc services.GroupCartService
+}
+
+func NewGroupCartController(s services.GroupCartService) *GroupCartController {
+	return &GroupCartController{svc: s}
+}
+
+type createGroupCartReq struct {
+	RestaurantId int64  `json:"restaurantId" binding:"required"`
+	Nickname     string `json:"nickname,omitempty"`
+}
+
+type joinGroupCartReq struct {
+	Nickname string `json:"nickname"`
+}
+
+type addGroupItemReq struct {
+	MenuItemId          int64           `json:"menuItemId" binding:"required"`
+	Qty                 int             `json:"qty" binding:"required"`
+	Options             json.RawMessage `json:"options,omitempty"`
+	SpecialInstructions *string         `json:"specialInstructions,omitempty"`
+}
+
+// POST /group-carts
+func (gc *GroupCartController) Create(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req createGroupCartReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	cart, err := gc.svc.Create(tokenUID, req.RestaurantId, req.Nickname)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to create group cart")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "group cart created", gin.H{"groupCart": cart})
+}
+
+// GET /group-carts/:token
+func (gc *GroupCartController) Get(c *gin.Context) {
+	cart, err := gc.svc.Get(c.Param("token"))
+	if err != nil {
+		sendGroupCartError(c, err, "failed to fetch group cart")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "group cart fetched", gin.H{"groupCart": cart})
+}
+
+// POST /group-carts/:token/join - signed in or as a guest with a nickname
+func (gc *GroupCartController) Join(c *gin.Context) {
+	var req joinGroupCartReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	cart, p, err := gc.svc.Join(c.Param("token"), groupCaller(c), req.Nickname)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to join group cart")
+		return
+	}
+	resp := gin.H{"groupCart": cart, "participant": p}
+	if p.UserID == nil {
+		// the only time a guest sees their token; it goes in X-Participant-Token from now on
+		resp["participantToken"] = p.Token
+	}
+	utils.SendSuccess(c, http.StatusOK, "joined group cart", resp)
+}
+
+// POST /group-carts/:token/items
+func (gc *GroupCartController) AddItem(c *gin.Context) {
+	var req addGroupItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	item := &models.GroupCartItem{CartItem: models.CartItem{
+		MenuItemID:          req.MenuItemId,
+		Quantity:            req.Qty,
+		Options:             req.Options,
+		SpecialInstructions: req.SpecialInstructions,
+	}}
+	cart, err := gc.svc.AddItem(c.Param("token"), groupCaller(c), item)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to add item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item added", gin.H{"groupCart": cart})
+}
+
+// PUT /group-carts/:token/items/:item_id
+func (gc *GroupCartController) UpdateItem(c *gin.Context) {
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid item id", err.Error())
+		return
+	}
+	var req updateCartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	item := &models.GroupCartItem{CartItem: models.CartItem{
+		ID:                  itemID,
+		Quantity:            *req.Qty,
+		Options:             req.Options,
+		SpecialInstructions: req.SpecialInstructions,
+	}}
+	cart, err := gc.svc.UpdateItem(c.Param("token"), groupCaller(c), item)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to update item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item updated", gin.H{"groupCart": cart})
+}
+
+// DELETE /group-carts/:token/items/:item_id
+func (gc *GroupCartController) RemoveItem(c *gin.Context) {
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid item id", err.Error())
+		return
+	}
+	cart, err := gc.svc.RemoveItem(c.Param("token"), groupCaller(c), itemID)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to remove item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "item removed", gin.H{"groupCart": cart})
+}
+
+// POST /group-carts/:token/lock
+func (gc *GroupCartController) Lock(c *gin.Context) {
+	cart, err := gc.svc.Lock(c.Param("token"), groupCaller(c).UserID)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to lock group cart")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "group cart locked", gin.H{"groupCart": cart})
+}
+
+// POST /group-carts/:token/unlock
+func (gc *GroupCartController) Unlock(c *gin.Context) {
+	cart, err := gc.svc.Unlock(c.Param("token"), groupCaller(c).UserID)
+	if err != nil {
+		sendGroupCartError(c, err, "failed to unlock group cart")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "group cart unlocked", gin.H{"groupCart": cart})
+}
+
+// DELETE /group-carts/:token
+func (gc *GroupCartController) Cancel(c *gin.Context) {
+	if err := gc.svc.Cancel(c.Param("token"), groupCaller(c).UserID); err != nil {
+		sendGroupCartError(c, err, "failed to cancel group cart")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "group cart cancelled", nil)
+}
+
+// POST /group-carts/:token/checkout
+func (gc *GroupCartController) Checkout(c *gin.Context) {
+	var req checkoutCartReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	order, err := gc.svc.Checkout(c.Param("token"), groupCaller(c).UserID, services.CartCheckout{
+		OrderType:           req.OrderType,
+		DiningSessionID:     req.DiningSessionID,
+		TableToken:          req.TableToken,
+		DeliveryAddress:     req.DeliveryAddress,
+		DeliveryLatitude:    req.DeliveryLatitude,
+		DeliveryLongitude:   req.DeliveryLongitude,
+		DeliveryAddressID:   req.DeliveryAddressId,
+		TipAmount:           req.TipAmount,
+		TotalAmount:         req.TotalAmount,
+		PromoCode:           req.PromoCode,
+		DeliveryFeeQuoteID:  req.DeliveryFeeQuoteId,
+		SpecialInstructions: req.SpecialInstructions,
+		ScheduledFor:        req.ScheduledFor,
+	})
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "group cart does not match current menu", verr.Problems)
+			return
+		}
+		sendGroupCartError(c, err, "failed to place order")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "order placed", gin.H{
+		"orderId":               order.ID,
+		"orderNumber":           order.OrderNumber,
+		"status":                order.OrderStatus,
+		"scheduledFor":          order.ScheduledFor,
+		"estimatedDeliveryTime": order.EstimatedDeliveryAt,
+		"createdAt":             time.Now().UTC(),
+		"subtotal":              order.SubtotalAmount,
+		"taxAmount":             order.TaxAmount,
+		"totalAmount":           order.TotalAmount,
+		"items":                 order.Items,
+	})
+}
+
+// groupCaller reads the signed-in user (if any) and the guest participant token
+func groupCaller(c *gin.Context) services.GroupCaller {
+	caller := services.GroupCaller{Token: c.GetHeader(participantTokenHeader)}
+	if raw, ok := c.Get(middleware.ContextUserIDKey); ok && raw != nil {
+		caller.UserID = raw.(int64)
+	}
+	return caller
+}
+
+// sendGroupCartError maps group cart service errors to responses
+func sendGroupCartError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+	case "restaurant_not_found":
+		utils.SendError(c, http.StatusNotFound, "restaurant not found", nil)
+	case "item_not_found":
+		utils.SendError(c, http.StatusNotFound, "item not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "invalid_nickname":
+		utils.SendError(c, http.StatusBadRequest, "nickname required (max 40 characters)", nil)
+	case "invalid_quantity":
+		utils.SendError(c, http.StatusBadRequest, "qty must be between 1 and 50", nil)
+	case "invalid_options":
+		utils.SendError(c, http.StatusBadRequest, "options must be valid JSON", nil)
+	case "item_unavailable":
+		utils.SendError(c, http.StatusUnprocessableEntity, "menu item is not available", nil)
+	case "group_full":
+		utils.SendError(c, http.StatusConflict, "group cart is full", nil)
+	case "not_open":
+		utils.SendError(c, http.StatusConflict, "group cart is locked or closed", nil)
+	case "not_locked":
+		utils.SendError(c, http.StatusConflict, "lock the group cart before checkout", nil)
+	case "invalid_state", "conflict":
+		utils.SendError(c, http.StatusConflict, "group cart state changed, reload and retry", nil)
+	case "cart_empty":
+		utils.SendError(c, http.StatusBadRequest, "group cart is empty", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
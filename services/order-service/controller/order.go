@@ -0,0 +1,565 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/realtime"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderController struct {
+	svc services.OrderService
+	hub *realtime.Hub
+}
+
+func NewOrderController(s services.OrderService, hub *realtime.Hub) *OrderController {
+	return &OrderController{svc: s, hub: hub}
+}
+
+// prices sent by the client are optional and only checked against the server-side computation
+type placeOrderItemReq struct {
+	MenuItemId *int64          `json:"menuItemId" binding:"required"`
+	Name       string          `json:"name"`
+	Qty        int             `json:"qty"`
+	UnitPrice  float64         `json:"unitPrice"` // with the picked options' price deltas
+	TotalPrice float64         `json:"totalPrice"`
+	Options    json.RawMessage `json:"options,omitempty"` // [{"group_id": 1, "option_ids": [3]}]
+}
+
+type placeOrderReq struct {
+	RestaurantId        int64               `json:"restaurantId" binding:"required"`
+	Items               []placeOrderItemReq `json:"items" binding:"required,dive"`
+	DeliveryAddress     string              `json:"deliveryAddress"`
+	DeliveryLatitude    *float64            `json:"deliveryLatitude"`
+	DeliveryLongitude   *float64            `json:"deliveryLongitude"`
+	DeliveryAddressId   *int64              `json:"deliveryAddressId,omitempty"` // a saved address; required for delivery
+	Subtotal            float64             `json:"subtotal"`
+	TaxAmount           float64             `json:"taxAmount"`
+	DeliveryFee         float64             `json:"deliveryFee"`
+	TipAmount           float64             `json:"tipAmount"`
+	DiscountAmount      float64             `json:"discountAmount"` // optional: checked against the promotion
+	TotalAmount         float64             `json:"totalAmount"`
+	PromoCode           *string             `json:"promoCode,omitempty"`
+	DeliveryFeeQuoteId  *int64              `json:"deliveryFeeQuoteId,omitempty"` // from POST /delivery-fee/quote
+	SpecialInstructions *string             `json:"specialInstructions"`
+	DiningSessionID     *int64              `json:"diningSessionId,omitempty"`
+	TableToken          string              `json:"tableToken,omitempty"` // the table's QR token, required with diningSessionId
+	OrderType           string              `json:"orderType,omitempty"`
+	ScheduledFor        *time.Time          `json:"scheduledFor,omitempty"` // RFC3339; omit to order now
+}
+
+// POST /orders
+func (oc *OrderController) PlaceOrder(c *gin.Context) {
+	var req placeOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+
+	// the customer is always the caller; a customerId in the payload is ignored
+	var tokenUserID int64
+	if raw, ok := c.Get(middleware.ContextUserIDKey); ok && raw != nil {
+		tokenUserID = raw.(int64)
+	}
+	if tokenUserID == 0 {
+		utils.SendError(c, http.StatusUnauthorized, "unauthenticated", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	order := &models.Order{
+		UserID:              tokenUserID,
+		RestaurantID:        req.RestaurantId,
+		DiningSessionID:     req.DiningSessionID,
+		TableToken:          req.TableToken,
+		OrderType:           req.OrderType,
+		OrderStatus:         models.OrderStatusPlaced,
+		PaymentStatus:       models.PaymentStatusPending,
+		SubtotalAmount:      req.Subtotal,
+		TaxAmount:           req.TaxAmount,
+		DeliveryFee:         req.DeliveryFee,
+		TipAmount:           req.TipAmount,
+		DiscountAmount:      req.DiscountAmount,
+		TotalAmount:         req.TotalAmount,
+		PromoCode:           req.PromoCode,
+		DeliveryFeeQuoteID:  req.DeliveryFeeQuoteId,
+		DeliveryAddress:     req.DeliveryAddress,
+		DeliveryLatitude:    req.DeliveryLatitude,
+		DeliveryLongitude:   req.DeliveryLongitude,
+		DeliveryAddressID:   req.DeliveryAddressId,
+		SpecialInstructions: req.SpecialInstructions,
+		ScheduledFor:        req.ScheduledFor,
+		CreatedAt:           &now,
+		UpdatedAt:           &now,
+	}
+
+	var items []models.OrderItem
+	for _, it := range req.Items {
+		// marshal options if present
+		var raw []byte
+		if it.Options != nil {
+			raw, _ = json.Marshal(it.Options)
+		}
+		item := models.OrderItem{
+			MenuItemID: nil,
+			Name:       it.Name,
+			Quantity:   it.Qty,
+			UnitPrice:  it.UnitPrice,
+			TotalPrice: it.TotalPrice,
+			CreatedAt:  &now,
+		}
+		if it.MenuItemId != nil {
+			item.MenuItemID = it.MenuItemId
+		}
+		if raw != nil {
+			item.Options = raw
+		}
+		items = append(items, item)
+	}
+
+	orderID, err := oc.svc.PlaceOrder(order, items)
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "order does not match current menu", verr.Problems)
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "failed to place order", err.Error())
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "order placed", gin.H{
+		"orderId":               orderID,
+		"orderNumber":           order.OrderNumber,
+		"status":                order.OrderStatus,
+		"scheduledFor":          order.ScheduledFor,
+		"estimatedDeliveryTime": order.EstimatedDeliveryAt,
+		"createdAt":             now,
+		"subtotal":              order.SubtotalAmount,
+		"taxAmount":             order.TaxAmount,
+		"discountAmount":        order.DiscountAmount,
+		"promotionId":           order.PromotionID,
+		"deliveryFee":           order.DeliveryFee,
+		"deliveryFeeQuoteId":    order.DeliveryFeeQuoteID,
+		"totalAmount":           order.TotalAmount,
+		"items":                 items,
+	})
+}
+
+// GET /orders/:id/status
+func (oc *OrderController) GetStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	status, err := oc.svc.GetOrderStatus(id)
+	if err != nil {
+		utils.SendError(c, http.StatusNotFound, "order not found", err.Error())
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order status fetched", gin.H{"orderId": id, "status": status})
+}
+
+// GET /orders/:id
+func (oc *OrderController) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	order, err := oc.svc.GetOrder(id, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to fetch order", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order fetched", gin.H{"order": order})
+}
+
+/*
+GET /orders - the caller's own orders.
+?number=R42-261017-0031 instead looks an order up by number (for kitchen staff and support too).
+*/
+func (oc *OrderController) List(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if number := c.Query("number"); number != "" {
+		list, err := oc.svc.FindOrdersByNumber(number, tokenUID, roleStr)
+		if err != nil {
+			utils.SendError(c, http.StatusInternalServerError, "failed to search orders", err.Error())
+			return
+		}
+		utils.SendSuccess(c, http.StatusOK, "orders fetched", gin.H{"items": list})
+		return
+	}
+
+	params, err := parseListOrdersQuery(c)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid query", err.Error())
+		return
+	}
+	list, next, err := oc.svc.ListCustomerOrders(params, c.Query("cursor"), tokenUID)
+	if err != nil {
+		switch err.Error() {
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "invalid_cursor":
+			utils.SendError(c, http.StatusBadRequest, "invalid cursor", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to list orders", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "orders fetched", gin.H{"items": list, "meta": gin.H{"nextCursor": next, "limit": params.Limit}})
+}
+
+// GET /restaurants/:id/orders
+func (oc *OrderController) ListForRestaurant(c *gin.Context) {
+	ridStr := c.Param("id")
+	rid, err := strconv.ParseInt(ridStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	params, err := parseListOrdersQuery(c)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid query", err.Error())
+		return
+	}
+	params.Number = c.Query("number")
+	list, next, err := oc.svc.ListRestaurantOrders(rid, params, c.Query("cursor"), tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "restaurant not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "invalid_cursor":
+			utils.SendError(c, http.StatusBadRequest, "invalid cursor", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to list orders", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "orders fetched", gin.H{"items": list, "meta": gin.H{"nextCursor": next, "limit": params.Limit}})
+}
+
+/*
+parseListOrdersQuery reads the shared listing filters:
+status, order_type, payment_status, from, to (RFC3339 or YYYY-MM-DD, "to" is inclusive for plain dates) and limit.
+*/
+func parseListOrdersQuery(c *gin.Context) (repository.ListOrdersParams, error) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params := repository.ListOrdersParams{
+		Status:        c.Query("status"),
+		OrderType:     c.Query("order_type"),
+		PaymentStatus: c.Query("payment_status"),
+		Limit:         limit,
+	}
+	if v := c.Query("from"); v != "" {
+		t, _, err := parseDateOrTime(v)
+		if err != nil {
+			return params, err
+		}
+		params.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, dateOnly, err := parseDateOrTime(v)
+		if err != nil {
+			return params, err
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		params.To = &t
+	}
+	return params, nil
+}
+
+func parseDateOrTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	return t, true, err
+}
+
+type updateStatusReq struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PUT /orders/:id/status
+func (oc *OrderController) UpdateStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req updateStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	if err := oc.svc.UpdateOrderStatus(id, req.Status, req.Reason, tokenUID, roleStr); err != nil {
+		switch err.Error() {
+		case "invalid_status":
+			utils.SendError(c, http.StatusBadRequest, "unknown order status", req.Status)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "invalid_transition":
+			utils.SendError(c, http.StatusConflict, "status transition not allowed", req.Status)
+		case "cancel_window_closed":
+			utils.SendError(c, http.StatusConflict, "order can no longer be cancelled by the customer", nil)
+		case "conflict":
+			utils.SendError(c, http.StatusConflict, "order status changed concurrently, retry", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to update status", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order status updated", nil)
+}
+
+type cancelOrderReq struct {
+	ReasonCode string `json:"reasonCode" binding:"required"`
+	Note       string `json:"note,omitempty"`
+}
+
+// POST /orders/:id/cancel
+func (oc *OrderController) Cancel(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req cancelOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	order, err := oc.svc.CancelOrder(id, req.ReasonCode, req.Note, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "reason required":
+			utils.SendError(c, http.StatusBadRequest, "reasonCode required", nil)
+		case "note required":
+			utils.SendError(c, http.StatusBadRequest, "note required when reasonCode is OTHER", nil)
+		case "invalid_reason":
+			utils.SendError(c, http.StatusBadRequest, "reasonCode not allowed", req.ReasonCode)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "cancel_window_closed":
+			utils.SendError(c, http.StatusConflict, "order can no longer be cancelled by the customer", nil)
+		case "invalid_transition":
+			utils.SendError(c, http.StatusConflict, "order can no longer be cancelled", nil)
+		case "conflict":
+			utils.SendError(c, http.StatusConflict, "order status changed concurrently, retry", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to cancel order", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order cancelled", gin.H{"order": order})
+}
+
+// GET /orders/:id/history
+func (oc *OrderController) GetHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	history, err := oc.svc.GetStatusHistory(id, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to fetch order history", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order history fetched", gin.H{"orderId": id, "items": history})
+}
+
+type assignRiderReq struct {
+	RiderID *int64 `json:"riderId"`
+}
+
+// PUT /orders/:id/rider - restaurant/admin assign a rider, or a rider claims the order
+func (oc *OrderController) AssignRider(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req assignRiderReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	order, err := oc.svc.AssignRider(id, req.RiderID, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "rider_id required":
+			utils.SendError(c, http.StatusBadRequest, "riderId required", nil)
+		case "not_delivery":
+			utils.SendError(c, http.StatusBadRequest, "only delivery orders take a rider", nil)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "invalid_transition":
+			utils.SendError(c, http.StatusConflict, "rider can no longer be assigned to this order", nil)
+		case "rider_taken":
+			utils.SendError(c, http.StatusConflict, "another rider already took this order", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to assign rider", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "rider assigned", gin.H{"orderId": order.ID, "riderId": order.RiderID})
+}
+
+type riderLocationReq struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+// POST /orders/:id/rider/location - assigned rider reports their position
+func (oc *OrderController) UpdateRiderLocation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req riderLocationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	if err := oc.svc.UpdateRiderLocation(id, *req.Latitude, *req.Longitude, tokenUID, roleStr); err != nil {
+		switch err.Error() {
+		case "invalid_location":
+			utils.SendError(c, http.StatusBadRequest, "latitude/longitude out of range", nil)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "invalid_transition":
+			utils.SendError(c, http.StatusConflict, "order is not out for delivery", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to update location", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "location updated", nil)
+}
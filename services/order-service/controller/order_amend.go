@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// amendOrderLineReq changes an existing line (itemId, qty 0 removes it) or adds one (menuItemId)
+type amendOrderLineReq struct {
+	ItemId              *int64          `json:"itemId,omitempty"`
+	MenuItemId          *int64          `json:"menuItemId,omitempty"`
+	Qty                 *int            `json:"qty" binding:"required"`
+	Options             json.RawMessage `json:"options,omitempty"`
+	SpecialInstructions *string         `json:"specialInstructions,omitempty"`
+}
+
+type amendOrderReq struct {
+	Items []amendOrderLineReq `json:"items" binding:"required,dive"`
+}
+
+// POST /orders/:id/amend - change lines while the order is still PLACED
+func (oc *OrderController) Amend(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req amendOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	lines := make([]services.OrderAmendLine, 0, len(req.Items))
+	for _, it := range req.Items {
+		lines = append(lines, services.OrderAmendLine{
+			ItemID:              it.ItemId,
+			MenuItemID:          it.MenuItemId,
+			Quantity:            *it.Qty,
+			Options:             it.Options,
+			SpecialInstructions: it.SpecialInstructions,
+		})
+	}
+	order, amendment, err := oc.svc.AmendOrder(id, lines, tokenUID, roleStr)
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "amended order does not match current menu", verr.Problems)
+			return
+		}
+		switch err.Error() {
+		case "no_changes":
+			utils.SendError(c, http.StatusBadRequest, "items required", nil)
+		case "invalid_quantity":
+			utils.SendError(c, http.StatusBadRequest, "qty must be between 0 and 50 (new lines at least 1)", nil)
+		case "invalid_options":
+			utils.SendError(c, http.StatusBadRequest, "options must be valid JSON", nil)
+		case "menu_item_required":
+			utils.SendError(c, http.StatusBadRequest, "itemId or menuItemId required", nil)
+		case "item_not_found":
+			utils.SendError(c, http.StatusNotFound, "order line not found", nil)
+		case "order_empty":
+			utils.SendError(c, http.StatusUnprocessableEntity, "an order needs at least one line; cancel it instead", nil)
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "amend_window_closed":
+			utils.SendError(c, http.StatusConflict, "order can only be changed until the restaurant confirms it", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to amend order", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "order amended", gin.H{"order": order, "amendment": amendment})
+}
+
+// GET /orders/:id/amendments
+func (oc *OrderController) ListAmendments(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	list, err := oc.svc.ListAmendments(id, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to fetch amendments", err.Error())
+		}
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "amendments fetched", gin.H{"amendments": list})
+}
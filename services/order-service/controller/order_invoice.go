@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /orders/:id/invoice?format=html|pdf|json - html unless asked otherwise
+func (oc *OrderController) Invoice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "html"))
+	if format != "html" && format != "pdf" && format != "json" {
+		utils.SendError(c, http.StatusBadRequest, "format must be html, pdf or json", nil)
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	inv, err := oc.svc.GetInvoice(id, tokenUID, roleStr)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		case "not_delivered":
+			utils.SendError(c, http.StatusConflict, "invoice is available once the order is delivered", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to fetch invoice", err.Error())
+		}
+		return
+	}
+
+	switch format {
+	case "json":
+		utils.SendSuccess(c, http.StatusOK, "invoice fetched", gin.H{"invoice": inv})
+	case "pdf":
+		c.Header("Content-Disposition", `attachment; filename="`+inv.InvoiceNumber+`.pdf"`)
+		c.Data(http.StatusOK, "application/pdf", services.RenderInvoicePDF(inv))
+	default:
+		page, err := services.RenderInvoiceHTML(inv)
+		if err != nil {
+			utils.SendError(c, http.StatusInternalServerError, "failed to render invoice", err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// comment lines keep the connection alive through the load balancer's idle timeout
+const sseHeartbeat = 15 * time.Second
+
+/*
+POST /stream-tickets - a ticket for opening order streams from a browser, whose EventSource
+cannot send the Authorization header: GET <stream>?ticket=<ticket>. It is valid for a minute,
+so a stream that has to reconnect after that asks for a new one.
+*/
+func (oc *OrderController) IssueStreamTicket(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	ticket, expiresAt, err := middleware.IssueStreamTicket(tokenUID, roleStr)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to issue stream ticket", err.Error())
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "stream ticket issued", gin.H{"ticket": ticket, "expiresAt": expiresAt.UTC()})
+}
+
+// GET /restaurants/:id/orders/stream - Server-Sent Events for kitchen displays
+func (oc *OrderController) StreamForRestaurant(c *gin.Context) {
+	ridStr := c.Param("id")
+	rid, err := strconv.ParseInt(ridStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if err := oc.svc.AuthorizeRestaurantStaff(rid, tokenUID, roleStr); err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "restaurant not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to open stream", err.Error())
+		}
+		return
+	}
+
+	sub := oc.hub.Subscribe(func(e models.OrderEvent) bool { return e.RestaurantID == rid })
+	defer oc.hub.Unsubscribe(sub)
+
+	streamOrderEvents(c, sub.C, orderStream{
+		replay: func(after int64) ([]models.OrderEvent, error) {
+			// a fresh kitchen screen loads the board separately; only resume after a drop
+			if after == 0 {
+				return nil, nil
+			}
+			return oc.svc.RestaurantEventsSince(rid, after)
+		},
+	})
+}
+
+// GET /orders/:id/track - Server-Sent Events for the customer following their order
+func (oc *OrderController) TrackOrder(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+
+	// subscribe first: the status read below must not miss a change made right after it
+	sub := oc.hub.Subscribe(func(e models.OrderEvent) bool { return e.OrderID == id })
+	defer oc.hub.Unsubscribe(sub)
+
+	order, err := oc.svc.AuthorizeOrderTracking(id, tokenUID)
+	if err != nil {
+		switch err.Error() {
+		case "not_found":
+			utils.SendError(c, http.StatusNotFound, "order not found", nil)
+		case "forbidden":
+			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "failed to open stream", err.Error())
+		}
+		return
+	}
+
+	streamOrderEvents(c, sub.C, orderStream{
+		// the whole timeline is short, so a new subscriber gets all of it
+		replay: func(after int64) ([]models.OrderEvent, error) {
+			return oc.svc.OrderEventsSince(id, after)
+		},
+		closeAfterReplay: services.IsTerminalOrderStatus(order.OrderStatus),
+		done: func(e models.OrderEvent) bool {
+			if e.Type != models.OrderEventStatusChanged {
+				return false
+			}
+			var p struct {
+				ToStatus string `json:"to_status"`
+			}
+			_ = json.Unmarshal(e.Payload, &p)
+			return services.IsTerminalOrderStatus(p.ToStatus)
+		},
+	})
+}
+
+// orderStream configures streamOrderEvents
+type orderStream struct {
+	// replay returns the events after the client's Last-Event-ID (0 on a first connect)
+	replay func(after int64) ([]models.OrderEvent, error)
+	// closeAfterReplay ends the stream once the backlog is sent, e.g. for a finished order
+	closeAfterReplay bool
+	// done reports the event after which the stream is complete
+	done func(models.OrderEvent) bool
+}
+
+/*
+streamOrderEvents writes SSE frames until the client goes away, the subscription is
+dropped (the client then reconnects with Last-Event-ID) or opts.done reports true for an event.
+The subscription must be opened before calling so nothing falls between replay and live events.
+*/
+func streamOrderEvents(c *gin.Context, live <-chan models.OrderEvent, opts orderStream) {
+	lastID := lastEventID(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	sent := make(map[int64]struct{})
+	write := func(e models.OrderEvent) bool {
+		if _, dup := sent[e.ID]; dup {
+			return true
+		}
+		sent[e.ID] = struct{}{}
+		data := e.Payload
+		if len(data) == 0 {
+			data = []byte("{}")
+		}
+		_, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: {\"order_id\":%d,\"payload\":%s}\n\n", e.Seq, e.Type, e.OrderID, data)
+		c.Writer.Flush()
+		return err == nil
+	}
+
+	missed, err := opts.replay(lastID)
+	if err != nil {
+		fmt.Fprintf(c.Writer, "event: error\ndata: {\"message\":\"replay failed\"}\n\n")
+		c.Writer.Flush()
+		return
+	}
+	for _, e := range missed {
+		if !write(e) {
+			return
+		}
+		if opts.done != nil && opts.done(e) {
+			return
+		}
+	}
+	if opts.closeAfterReplay {
+		return
+	}
+	// tell the client the stream is live
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-live:
+			if !ok {
+				return
+			}
+			if !write(e) {
+				return
+			}
+			if opts.done != nil && opts.done(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// lastEventID reads the resume point from the Last-Event-ID header (set by EventSource) or ?last_event_id=
+func lastEventID(c *gin.Context) int64 {
+	v := c.GetHeader("Last-Event-ID")
+	if v == "" {
+		v = c.Query("last_event_id")
+	}
+	id, _ := strconv.ParseInt(v, 10, 64)
+	return id
+}
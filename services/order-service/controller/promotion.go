@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionController struct {
+	svc services.PromotionService
+}
+
+func NewPromotionController(s services.PromotionService) *PromotionController {
+	return &PromotionController{svc: s}
+}
+
+type createPromotionReq struct {
+	Code           *string    `json:"code,omitempty"` // omit for an automatic offer
+	Name           string     `json:"name" binding:"required"`
+	Description    string     `json:"description,omitempty"`
+	DiscountType   string     `json:"discountType" binding:"required"` // FLAT | PERCENT
+	DiscountValue  float64    `json:"discountValue" binding:"required"`
+	MaxDiscount    *float64   `json:"maxDiscount,omitempty"`
+	MinSubtotal    float64    `json:"minSubtotal"`
+	FirstOrderOnly bool       `json:"firstOrderOnly"`
+	RestaurantIds  []int64    `json:"restaurantIds,omitempty"` // platform promotions only
+	Tags           []string   `json:"tags,omitempty"`
+	StartsAt       *time.Time `json:"startsAt,omitempty"`
+	EndsAt         *time.Time `json:"endsAt,omitempty"`
+	DailyStart     string     `json:"dailyStart,omitempty"` // "HH:MM"
+	DailyEnd       string     `json:"dailyEnd,omitempty"`
+	UsageLimit     *int       `json:"usageLimit,omitempty"`
+	PerUserLimit   *int       `json:"perUserLimit,omitempty"`
+}
+
+type validatePromotionReq struct {
+	RestaurantId      int64               `json:"restaurantId" binding:"required"`
+	Items             []placeOrderItemReq `json:"items" binding:"required,dive"`
+	PromoCode         *string             `json:"promoCode,omitempty"` // omit to see the automatic offer
+	OrderType         string              `json:"orderType,omitempty"`
+	DeliveryAddressId *int64              `json:"deliveryAddressId,omitempty"`
+	DeliveryFee       float64             `json:"deliveryFee"`
+	TipAmount         float64             `json:"tipAmount"`
+	DiscountAmount    float64             `json:"discountAmount"`
+}
+
+// promotionScope is the restaurant in the path, nil on the platform routes
+func promotionScope(c *gin.Context) (*int64, bool) {
+	raw := c.Param("id")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return nil, false
+	}
+	return &id, true
+}
+
+// GET /restaurants/:id/promotions, GET /promotions (platform)
+func (pc *PromotionController) List(c *gin.Context) {
+	restaurantID, ok := promotionScope(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	list, err := pc.svc.ListPromotions(restaurantID, tokenUID, roleStr)
+	if err != nil {
+		sendPromotionError(c, err, "failed to fetch promotions")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "promotions fetched", gin.H{"promotions": list})
+}
+
+// POST /restaurants/:id/promotions, POST /promotions (platform)
+func (pc *PromotionController) Create(c *gin.Context) {
+	restaurantID, ok := promotionScope(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req createPromotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	p, err := pc.svc.CreatePromotion(&models.Promotion{
+		RestaurantID:   restaurantID,
+		Code:           req.Code,
+		Name:           req.Name,
+		Description:    req.Description,
+		DiscountType:   req.DiscountType,
+		DiscountValue:  req.DiscountValue,
+		MaxDiscount:    req.MaxDiscount,
+		MinSubtotal:    req.MinSubtotal,
+		FirstOrderOnly: req.FirstOrderOnly,
+		RestaurantIDs:  req.RestaurantIds,
+		Tags:           req.Tags,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		DailyStart:     req.DailyStart,
+		DailyEnd:       req.DailyEnd,
+		UsageLimit:     req.UsageLimit,
+		PerUserLimit:   req.PerUserLimit,
+	}, tokenUID, roleStr)
+	if err != nil {
+		sendPromotionError(c, err, "failed to create promotion")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "promotion created", gin.H{"promotion": p})
+}
+
+// DELETE /restaurants/:id/promotions/:promotion_id, DELETE /promotions/:promotion_id (platform)
+func (pc *PromotionController) Deactivate(c *gin.Context) {
+	restaurantID, ok := promotionScope(c)
+	if !ok {
+		return
+	}
+	promotionID, err := strconv.ParseInt(c.Param("promotion_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid promotion id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if err := pc.svc.DeactivatePromotion(restaurantID, promotionID, tokenUID, roleStr); err != nil {
+		sendPromotionError(c, err, "failed to deactivate promotion")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "promotion deactivated", nil)
+}
+
+/*
+POST /promotions/validate - preview a coupon, or the automatic offer, on a basket.
+The basket is priced exactly as POST /orders would price it; nothing is reserved.
+*/
+func (pc *PromotionController) Validate(c *gin.Context) {
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	var req validatePromotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	order := &models.Order{
+		UserID:            tokenUID,
+		RestaurantID:      req.RestaurantId,
+		OrderType:         req.OrderType,
+		DeliveryAddressID: req.DeliveryAddressId,
+		DeliveryFee:       req.DeliveryFee,
+		TipAmount:         req.TipAmount,
+		DiscountAmount:    req.DiscountAmount,
+		PromoCode:         req.PromoCode,
+	}
+	items := make([]models.OrderItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		item := models.OrderItem{
+			MenuItemID: it.MenuItemId,
+			Quantity:   it.Qty,
+			UnitPrice:  it.UnitPrice,
+			TotalPrice: it.TotalPrice,
+		}
+		if it.Options != nil {
+			item.Options, _ = json.Marshal(it.Options)
+		}
+		items = append(items, item)
+	}
+	priced, promotion, err := pc.svc.Validate(order, items)
+	if err != nil {
+		var verr *services.OrderValidationError
+		if errors.As(err, &verr) {
+			utils.SendError(c, http.StatusUnprocessableEntity, "promotion cannot be applied to this order", verr.Problems)
+			return
+		}
+		sendPromotionError(c, err, "failed to validate promotion")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "promotion validated", gin.H{
+		"promotion":      promotion,
+		"promoCode":      priced.PromoCode,
+		"subtotal":       priced.SubtotalAmount,
+		"discountAmount": priced.DiscountAmount,
+		"taxAmount":      priced.TaxAmount,
+		"totalAmount":    priced.TotalAmount,
+		"items":          items,
+	})
+}
+
+// sendPromotionError maps promotion service errors to responses
+func sendPromotionError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "items_required":
+		utils.SendError(c, http.StatusBadRequest, "items required", nil)
+	case "name_required":
+		utils.SendError(c, http.StatusBadRequest, "name required", nil)
+	case "invalid_discount_type":
+		utils.SendError(c, http.StatusBadRequest, "discountType must be FLAT or PERCENT", nil)
+	case "invalid_discount":
+		utils.SendError(c, http.StatusBadRequest, "discountValue and maxDiscount must be positive, percentages at most 100, minSubtotal not negative", nil)
+	case "invalid_code":
+		utils.SendError(c, http.StatusBadRequest, "code must be 3-32 letters, digits, '-' or '_'", nil)
+	case "code_taken":
+		utils.SendError(c, http.StatusConflict, "another live promotion uses this code", nil)
+	case "invalid_window":
+		utils.SendError(c, http.StatusBadRequest, "endsAt must be after startsAt; dailyStart and dailyEnd go together as HH:MM", nil)
+	case "invalid_limit":
+		utils.SendError(c, http.StatusBadRequest, "usage limits must be at least 1", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
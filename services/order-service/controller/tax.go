@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxController struct {
+	svc services.TaxService
+}
+
+func NewTaxController(s services.TaxService) *TaxController {
+	return &TaxController{svc: s}
+}
+
+type createTaxRuleReq struct {
+	CategoryId  *int64   `json:"categoryId,omitempty"`
+	Tag         *string  `json:"tag,omitempty"`
+	Label       string   `json:"label" binding:"required"`
+	RatePercent *float64 `json:"ratePercent" binding:"required"`
+}
+
+// GET /restaurants/:id/tax-rules - the restaurant's rules and the platform defaults
+func (tc *TaxController) List(c *gin.Context) {
+	restaurantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	rules, err := tc.svc.ListRules(restaurantID, tokenUID, roleStr)
+	if err != nil {
+		sendTaxError(c, err, "failed to fetch tax rules")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "tax rules fetched", gin.H{"rules": rules})
+}
+
+// POST /restaurants/:id/tax-rules - a GST rate for a category, a tag, or every other item (neither)
+func (tc *TaxController) Create(c *gin.Context) {
+	restaurantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req createTaxRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	rule, err := tc.svc.CreateRule(&models.TaxRule{
+		RestaurantID: &restaurantID,
+		CategoryID:   req.CategoryId,
+		Tag:          req.Tag,
+		Label:        req.Label,
+		RatePercent:  *req.RatePercent,
+	}, tokenUID, roleStr)
+	if err != nil {
+		sendTaxError(c, err, "failed to create tax rule")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "tax rule created", gin.H{"rule": rule})
+}
+
+// DELETE /restaurants/:id/tax-rules/:rule_id
+func (tc *TaxController) Delete(c *gin.Context) {
+	restaurantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	ruleID, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid rule id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if err := tc.svc.DeleteRule(restaurantID, ruleID, tokenUID, roleStr); err != nil {
+		sendTaxError(c, err, "failed to delete tax rule")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "tax rule deleted", nil)
+}
+
+// sendTaxError maps tax service errors to responses
+func sendTaxError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "label_required":
+		utils.SendError(c, http.StatusBadRequest, "label required", nil)
+	case "invalid_rate":
+		utils.SendError(c, http.StatusBadRequest, "ratePercent must be between 0 and 100", nil)
+	case "category_or_tag":
+		utils.SendError(c, http.StatusBadRequest, "a rule matches a category or a tag, not both", nil)
+	case "category_not_found":
+		utils.SendError(c, http.StatusUnprocessableEntity, "category does not belong to this restaurant", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
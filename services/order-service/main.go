@@ -2,8 +2,10 @@ package main
 
 import (
 	"log"
-	"net/http"
+	"time"
 
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/routes"
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -15,32 +17,23 @@ func init() {
 }
 
 func main() {
+	database := InitDB()
+	defer database.Close()
+
 	r := gin.Default()
-	InitDB()
-	// Health check endpoint
-	// r.GET("/health", func(c *gin.Context) {
-	// 	healthCheckHandler(database)
-	// 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	// })
 
-	r.GET("/order/:id", getOrder)
-	r.PUT("/order/:id", updateOrder)
-	r.DELETE("/order/:id", deleteOrder)
+	// CORS
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key", "Last-Event-ID", "X-Participant-Token"},
+		ExposeHeaders: []string{"Content-Length", "Idempotent-Replayed"},
+		MaxAge:        12 * time.Hour,
+	}))
 
-	r.Run(":8081")
-}
+	r.GET("/health", healthCheckHandler(database))
 
-func getOrder(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "name": "example"})
-}
+	routes.Setup(r, database)
 
-func updateOrder(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"message": "order updated"})
-}
-
-func deleteOrder(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"message": "order deleted"})
+	r.Run(":8081")
 }
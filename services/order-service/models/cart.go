@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Cart is a user's basket kept on the server so it follows them across devices.
+// A cart holds items from one restaurant at a time.
+type Cart struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	RestaurantID *int64     `json:"restaurant_id,omitempty"` // nil while empty
+	Items        []CartItem `json:"items"`
+	Subtotal     float64    `json:"subtotal"` // available lines at current menu prices
+	Checkout     bool       `json:"checkout"` // every line can be ordered as is
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// CartItem is one line of a cart. Name and prices are filled from the menu on every read.
+type CartItem struct {
+	ID                  int64           `json:"id"`
+	CartID              int64           `json:"cart_id"`
+	MenuItemID          int64           `json:"menu_item_id"`
+	Name                string          `json:"name,omitempty"`
+	Quantity            int             `json:"quantity"`
+	UnitPrice           float64         `json:"unit_price"`
+	TotalPrice          float64         `json:"total_price"`
+	Options             json.RawMessage `json:"options,omitempty"`
+	SpecialInstructions *string         `json:"special_instructions,omitempty"`
+	Available           bool            `json:"available"`
+	Problem             string          `json:"problem,omitempty"` // why the line cannot be ordered
+	CreatedAt           *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt           *time.Time      `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+/*
+DeliveryFeeConfig is how delivery is charged in a city; the config with an empty City is
+the default for cities without their own. The distance fee comes from the first slab the
+road distance fits in, and distances past the last slab are not delivered to.
+*/
+type DeliveryFeeConfig struct {
+	ID                    int64             `json:"id"`
+	City                  string            `json:"city"` // "" for the default
+	Slabs                 []DeliveryFeeSlab `json:"slabs"`
+	SmallOrderThreshold   float64           `json:"small_order_threshold"` // subtotals below this pay the surcharge
+	SmallOrderSurcharge   float64           `json:"small_order_surcharge"`
+	FreeDeliveryThreshold *float64          `json:"free_delivery_threshold,omitempty"` // subtotals from this on pay no distance fee
+	SurgeMaxMultiplier    float64           `json:"surge_max_multiplier"`              // 1 turns surge off
+	UpdatedAt             *time.Time        `json:"updated_at,omitempty"`
+}
+
+// DeliveryFeeSlab charges Fee up to UpToKm of road distance
+type DeliveryFeeSlab struct {
+	UpToKm float64 `json:"up_to_km"`
+	Fee    float64 `json:"fee"`
+}
+
+/*
+DeliveryFeeQuote is a priced drop point. The distance fee and surge are fixed when the
+quote is made and hold until ExpiresAt; the small order surcharge and free delivery
+follow the basket at placement. An order uses a quote at most once.
+*/
+type DeliveryFeeQuote struct {
+	ID                    int64     `json:"id"`
+	UserID                int64     `json:"user_id"`
+	RestaurantID          int64     `json:"restaurant_id"`
+	City                  string    `json:"city"` // config the quote was priced with
+	Latitude              *float64  `json:"latitude,omitempty"`
+	Longitude             *float64  `json:"longitude,omitempty"`
+	DistanceKm            *float64  `json:"distance_km,omitempty"` // road estimate; nil when a location is missing
+	DistanceFee           float64   `json:"distance_fee"`
+	SurgeMultiplier       float64   `json:"surge_multiplier"`
+	OpenOrders            int       `json:"open_orders"`      // waiting for a rider when quoted
+	AvailableRiders       int       `json:"available_riders"` // free riders when quoted
+	SmallOrderThreshold   float64   `json:"small_order_threshold"`
+	SmallOrderSurcharge   float64   `json:"small_order_surcharge"`
+	FreeDeliveryThreshold *float64  `json:"free_delivery_threshold,omitempty"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// RiderPresence is a rider's latest availability heartbeat
+type RiderPresence struct {
+	RiderID     int64     `json:"rider_id"`
+	City        string    `json:"city"`
+	IsAvailable bool      `json:"is_available"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// DeliveryFeeBreakdown is how a quote comes to the fee charged for a basket
+type DeliveryFeeBreakdown struct {
+	DistanceFee         float64 `json:"distance_fee"`
+	SurgeMultiplier     float64 `json:"surge_multiplier"`
+	SurgeAmount         float64 `json:"surge_amount"`
+	FreeDelivery        bool    `json:"free_delivery"` // distance fee and surge waived
+	SmallOrderSurcharge float64 `json:"small_order_surcharge"`
+	Fee                 float64 `json:"fee"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Dining session statuses
+const (
+	DiningSessionOpen    = "OPEN"    // guests at the table can keep ordering
+	DiningSessionClosed  = "CLOSED"  // billed, no more orders
+	DiningSessionSettled = "SETTLED" // every share of the bill is paid
+)
+
+// How a closed session's bill is split between the guests
+const (
+	SplitEven   = "EVEN"   // N equal shares
+	SplitByItem = "ITEM"   // each share pays for the dishes it had
+	SplitCustom = "CUSTOM" // amounts agreed at the table
+)
+
+// Bill share payment statuses, mirrored from payment-service
+const (
+	SharePaymentPending = "PENDING"
+	SharePaymentPaid    = "PAID"
+	SharePaymentFailed  = "FAILED"
+)
+
+// DiningSession is one sitting at a restaurant table, opened by scanning the table's QR code
+type DiningSession struct {
+	ID              int64       `json:"id"`
+	RestaurantID    int64       `json:"restaurant_id"`
+	TableID         int64       `json:"table_id"`
+	TableQRToken    string      `json:"-"` // only ever compared, never sent back
+	TableIdentifier string      `json:"table_identifier,omitempty"`
+	Status          string      `json:"status"`
+	OpenedBy        *int64      `json:"opened_by,omitempty"`
+	ClosedBy        *int64      `json:"closed_by,omitempty"`
+	OpenedAt        *time.Time  `json:"opened_at,omitempty"`
+	ClosedAt        *time.Time  `json:"closed_at,omitempty"`
+	SettledAt       *time.Time  `json:"settled_at,omitempty"`
+	SplitMode       string      `json:"split_mode,omitempty"`
+	Orders          []Order     `json:"orders,omitempty"` // the running tab, every round ordered so far
+	Bill            *DiningBill `json:"bill,omitempty"`
+	Shares          []BillShare `json:"shares,omitempty"`
+}
+
+// DiningBill is the consolidated bill of a session: all rounds merged, cancelled orders left out
+type DiningBill struct {
+	OrderCount     int              `json:"order_count"`
+	Lines          []DiningBillLine `json:"lines"`
+	SubtotalAmount float64          `json:"subtotal_amount"`
+	TaxAmount      float64          `json:"tax_amount"`
+	DiscountAmount float64          `json:"discount_amount"`
+	TipAmount      float64          `json:"tip_amount"`
+	TotalAmount    float64          `json:"total_amount"`
+}
+
+// DiningBillLine is the same dish at the same price summed over all rounds
+type DiningBillLine struct {
+	MenuItemID *int64  `json:"menu_item_id,omitempty"`
+	Name       string  `json:"name"`
+	UnitPrice  float64 `json:"unit_price"`
+	Quantity   int     `json:"quantity"`
+	TotalPrice float64 `json:"total_price"`
+}
+
+/*
+BillShare is the part of a closed session's bill one guest pays. Tax, tip and discount are
+the bill's, in the same proportion as the share's Amount; SubtotalAmount is the rest.
+Each share is collected as its own payment in payment-service.
+*/
+type BillShare struct {
+	ID             int64       `json:"id"`
+	SessionID      int64       `json:"session_id"`
+	Label          string      `json:"label"`
+	PayerUserID    *int64      `json:"payer_user_id,omitempty"`
+	Lines          []ShareLine `json:"lines,omitempty"` // ITEM splits only
+	SubtotalAmount float64     `json:"subtotal_amount"`
+	TaxAmount      float64     `json:"tax_amount"`
+	TipAmount      float64     `json:"tip_amount"`
+	DiscountAmount float64     `json:"discount_amount"`
+	Amount         float64     `json:"amount"`
+	PaymentID      *int64      `json:"payment_id,omitempty"`
+	PaymentAttempt int         `json:"payment_attempt"` // bumped when a payment fails and a new one is opened
+	PaymentStatus  string      `json:"payment_status"`
+	PaidAt         *time.Time  `json:"paid_at,omitempty"`
+	CreatedAt      *time.Time  `json:"created_at,omitempty"`
+}
+
+// ShareLine is how much of one bill line a share takes
+type ShareLine struct {
+	Line     int    `json:"line"` // index into DiningBill.Lines
+	Name     string `json:"name,omitempty"`
+	Quantity int    `json:"quantity"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Group cart statuses
+const (
+	GroupCartOpen       = "OPEN"        // participants can add items
+	GroupCartLocked     = "LOCKED"      // host is reviewing, no more changes
+	GroupCartCheckedOut = "CHECKED_OUT" // turned into OrderID
+	GroupCartCancelled  = "CANCELLED"
+)
+
+// GroupCart is a shared cart for one restaurant, opened by a host and joined through a link
+type GroupCart struct {
+	ID           int64                  `json:"id"`
+	RestaurantID int64                  `json:"restaurant_id"`
+	HostUserID   int64                  `json:"host_user_id"`
+	JoinToken    string                 `json:"join_token"`
+	JoinURL      string                 `json:"join_url,omitempty"`
+	Status       string                 `json:"status"`
+	OrderID      *int64                 `json:"order_id,omitempty"`
+	Participants []GroupCartParticipant `json:"participants,omitempty"`
+	Items        []GroupCartItem        `json:"items,omitempty"`
+	Subtotal     float64                `json:"subtotal"`
+	Checkout     bool                   `json:"checkout"` // every line can be ordered as is
+	CreatedAt    *time.Time             `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time             `json:"updated_at,omitempty"`
+}
+
+// GroupCartParticipant is someone in a group cart; guests have no UserID and act with their Token
+type GroupCartParticipant struct {
+	ID          int64      `json:"id"`
+	GroupCartID int64      `json:"group_cart_id"`
+	UserID      *int64     `json:"user_id,omitempty"`
+	Nickname    string     `json:"nickname"`
+	IsHost      bool       `json:"is_host"`
+	Token       string     `json:"-"` // handed out once on join
+	Subtotal    float64    `json:"subtotal"`
+	JoinedAt    *time.Time `json:"joined_at,omitempty"`
+}
+
+// GroupCartItem is a cart line owned by one participant
+type GroupCartItem struct {
+	CartItem
+	ParticipantID   int64  `json:"participant_id"`
+	ParticipantName string `json:"participant_name,omitempty"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Invoice kinds: registered restaurants issue tax invoices, the rest receipts
+const (
+	InvoiceKindTax     = "TAX_INVOICE"
+	InvoiceKindReceipt = "RECEIPT"
+)
+
+/*
+Invoice is issued once when an order is DELIVERED. Everything printed on it is a snapshot
+taken at that moment, so later changes to the restaurant or the menu never alter an
+invoice that was already handed out.
+*/
+type Invoice struct {
+	ID            int64         `json:"id"`
+	OrderID       int64         `json:"order_id"`
+	RestaurantID  int64         `json:"restaurant_id"`
+	InvoiceNumber string        `json:"invoice_number"` // I42-2526-00031: restaurant, financial year, sequence
+	FinancialYear string        `json:"financial_year"` // 2526 for April 2025 - March 2026
+	Kind          string        `json:"kind"`
+	IssuedAt      time.Time     `json:"issued_at"`
+	OrderNumber   string        `json:"order_number"`
+	OrderType     string        `json:"order_type"`
+	OrderedAt     *time.Time    `json:"ordered_at,omitempty"`
+	Supplier      InvoiceParty  `json:"supplier"`
+	Customer      InvoiceParty  `json:"customer"`
+	PlaceOfSupply string        `json:"place_of_supply"`
+	InterState    bool          `json:"inter_state"` // IGST instead of CGST + SGST
+	Lines         []InvoiceLine `json:"lines"`
+	Totals        InvoiceTotals `json:"totals"`
+}
+
+// InvoiceParty is the restaurant or the customer as printed on the invoice
+type InvoiceParty struct {
+	UserID       *int64 `json:"user_id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	AddressLine1 string `json:"address_line1,omitempty"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+	Pincode      string `json:"pincode,omitempty"`
+	GSTIN        string `json:"gstin,omitempty"`
+}
+
+type InvoiceLine struct {
+	Name          string  `json:"name"`
+	Quantity      int     `json:"quantity"`
+	UnitPrice     float64 `json:"unit_price"`
+	Amount        float64 `json:"amount"`
+	Discount      float64 `json:"discount"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxLabel      string  `json:"tax_label,omitempty"`
+	RatePercent   float64 `json:"rate_percent"`
+	CGSTAmount    float64 `json:"cgst_amount"`
+	SGSTAmount    float64 `json:"sgst_amount"`
+	IGSTAmount    float64 `json:"igst_amount"`
+}
+
+type InvoiceTotals struct {
+	Subtotal      float64 `json:"subtotal"`
+	Discount      float64 `json:"discount"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	CGSTAmount    float64 `json:"cgst_amount"`
+	SGSTAmount    float64 `json:"sgst_amount"`
+	IGSTAmount    float64 `json:"igst_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+	DeliveryFee   float64 `json:"delivery_fee"`
+	Tip           float64 `json:"tip"`
+	Total         float64 `json:"total"`
+}
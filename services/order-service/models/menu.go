@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// menus belong to restaurant-service; orders read them from the shared database to price lines
+
+// Menu item availability values
+const (
+	AvailabilityInStock    = "IN_STOCK"
+	AvailabilityOutOfStock = "OUT_OF_STOCK" // sold out: listed, not orderable
+	AvailabilityHidden     = "HIDDEN"       // off the menu
+)
+
+type MenuCategory struct {
+	ID           int64           `json:"id"`
+	RestaurantID int64           `json:"restaurant_id"`
+	Name         string          `json:"name"`
+	Slug         string          `json:"slug,omitempty"`
+	ExternalSKU  string          `json:"external_sku,omitempty"` // set by menu imports
+	ParentID     *int64          `json:"parent_id,omitempty"`
+	SortOrder    int             `json:"sort_order,omitempty"`
+	IsActive     bool            `json:"is_active,omitempty"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	Windows      []MenuWindow    `json:"windows,omitempty"` // served only then; none means all day
+	CreatedAt    *time.Time      `json:"created_at,omitempty"`
+}
+
+type MenuItem struct {
+	ID              int64           `json:"id"`
+	RestaurantID    int64           `json:"restaurant_id"`
+	CategoryID      *int64          `json:"category_id,omitempty"`
+	ExternalSKU     string          `json:"external_sku,omitempty"` // set by menu imports
+	Name            string          `json:"name"`
+	Description     string          `json:"description,omitempty"`
+	Price           float64         `json:"price"`
+	Currency        string          `json:"currency,omitempty"`     // e.g. "INR"
+	Availability    string          `json:"availability,omitempty"` // e.g. "IN_STOCK"
+	RestockAt       *time.Time      `json:"restock_at,omitempty"`   // an OUT_OF_STOCK item is back IN_STOCK from then
+	IsVeg           bool            `json:"is_veg,omitempty"`
+	SpiceLevel      int             `json:"spice_level,omitempty"`
+	PrepTimeMinutes int             `json:"prep_time_minutes,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`  // free-form json (ingredients etc)
+	ImageURL        string          `json:"image_url,omitempty"` // optional
+	ModifierGroups  []ModifierGroup `json:"modifier_groups,omitempty"`
+	Windows         []MenuWindow    `json:"windows,omitempty"`          // served only then; none means all day
+	CategoryWindows []MenuWindow    `json:"category_windows,omitempty"` // the category's, which apply as well
+	CreatedAt       *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty"`
+}
+
+/*
+MenuWindow is a weekly time a category or item is served, read in the business timezone.
+An end at or before the start runs past midnight into the next day.
+*/
+type MenuWindow struct {
+	ID         int64  `json:"id"`
+	CategoryID *int64 `json:"category_id,omitempty"`
+	MenuItemID *int64 `json:"menu_item_id,omitempty"`
+	Weekday    int    `json:"weekday"`    // 0 = Sunday
+	StartTime  string `json:"start_time"` // "15:04:05"
+	EndTime    string `json:"end_time"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+/*
+ModifierGroup is a choice made when ordering a menu item, e.g. "Size" with MinSelect and
+MaxSelect 1, or "Toppings" with 0 and 3. MinSelect 0 makes the group optional.
+*/
+type ModifierGroup struct {
+	ID         int64            `json:"id"`
+	MenuItemID int64            `json:"menu_item_id"`
+	Name       string           `json:"name"`
+	MinSelect  int              `json:"min_select"`
+	MaxSelect  int              `json:"max_select"`
+	SortOrder  int              `json:"sort_order"`
+	Options    []ModifierOption `json:"options"`
+	CreatedAt  *time.Time       `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time       `json:"updated_at,omitempty"`
+}
+
+// ModifierOption is one choice of a group; PriceDelta is added to the item's unit price
+type ModifierOption struct {
+	ID          int64   `json:"id"`
+	GroupID     int64   `json:"group_id"`
+	Name        string  `json:"name"`
+	PriceDelta  float64 `json:"price_delta"`
+	IsAvailable bool    `json:"is_available"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+/*
+ModifierSelection is what order, cart and amendment lines carry in Options: a JSON array
+with one entry per group picked from. Clients send GroupID and OptionIDs; the server
+fills in the names and prices the line was priced with.
+*/
+type ModifierSelection struct {
+	GroupID   int64              `json:"group_id"`
+	OptionIDs []int64            `json:"option_ids"`
+	Group     string             `json:"group,omitempty"`
+	Options   []SelectedModifier `json:"options,omitempty"`
+}
+
+// SelectedModifier is a picked option as it was priced
+type SelectedModifier struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+}
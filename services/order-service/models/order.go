@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Order statuses
+const (
+	OrderStatusScheduled      = "SCHEDULED" // accepted for later, not yet sent to the kitchen
+	OrderStatusPlaced         = "PLACED"
+	OrderStatusConfirmed      = "CONFIRMED"
+	OrderStatusPreparing      = "PREPARING"
+	OrderStatusReady          = "READY"
+	OrderStatusOutForDelivery = "OUT_FOR_DELIVERY"
+	OrderStatusDelivered      = "DELIVERED"
+	OrderStatusCancelled      = "CANCELLED"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending       = "PENDING"
+	PaymentStatusPaid          = "PAID"
+	PaymentStatusFailed        = "FAILED"
+	PaymentStatusRefundPending = "REFUND_PENDING" // order cancelled, refund handed to payment-service
+	PaymentStatusRefunded      = "REFUNDED"
+)
+
+// Cancellation reason codes; which actor may use which is decided in services
+const (
+	CancelReasonChangedMind      = "CHANGED_MIND"
+	CancelReasonOrderedByMistake = "ORDERED_BY_MISTAKE"
+	CancelReasonTakingTooLong    = "TAKING_TOO_LONG"
+	CancelReasonItemUnavailable  = "ITEM_UNAVAILABLE"
+	CancelReasonRestaurantClosed = "RESTAURANT_CLOSED"
+	CancelReasonRestaurantBusy   = "RESTAURANT_BUSY"
+	CancelReasonAddressIssue     = "ADDRESS_ISSUE"
+	CancelReasonPaymentIssue     = "PAYMENT_ISSUE"
+	CancelReasonFraudSuspected   = "FRAUD_SUSPECTED"
+	CancelReasonOther            = "OTHER"
+)
+
+// Order represents an order placed by a user
+type Order struct {
+	ID                  int64             `json:"id"`
+	OrderNumber         string            `json:"order_number,omitempty"`      // optional human-friendly number
+	UserID              int64             `json:"user_id"`                     // customer / buyer
+	RestaurantID        int64             `json:"restaurant_id"`               // restaurant
+	DiningSessionID     *int64            `json:"dining_session_id,omitempty"` // optional for QR / dine-in
+	TableToken          string            `json:"-"`                           // the table's QR token, proof a dine-in round comes from the table
+	OrderType           string            `json:"order_type,omitempty"`        // DELIVERY | PICKUP | DINE_IN
+	OrderStatus         string            `json:"order_status,omitempty"`      // SCHEDULED, PLACED, CONFIRMED, PREPARING, READY, OUT_FOR_DELIVERY, DELIVERED, CANCELLED
+	PaymentStatus       string            `json:"payment_status,omitempty"`    // PENDING, PAID, FAILED, REFUND_PENDING, REFUNDED
+	SubtotalAmount      float64           `json:"subtotal_amount,omitempty"`
+	TaxAmount           float64           `json:"tax_amount,omitempty"`
+	DeliveryFee         float64           `json:"delivery_fee,omitempty"`
+	DeliveryFeeQuoteID  *int64            `json:"delivery_fee_quote_id,omitempty"` // the quote DeliveryFee was locked from
+	PendingFeeQuote     *DeliveryFeeQuote `json:"-"`                               // quoted while pricing, stored with the order when placed
+	TipAmount           float64           `json:"tip_amount,omitempty"`
+	DiscountAmount      float64           `json:"discount_amount,omitempty"`
+	PromotionID         *int64            `json:"promotion_id,omitempty"` // the promotion behind DiscountAmount
+	PromoCode           *string           `json:"promo_code,omitempty"`   // coupon code the customer entered
+	TotalAmount         float64           `json:"total_amount,omitempty"`
+	DeliveryAddressID   *int64            `json:"delivery_address_id,omitempty"` // the customer's saved address in user-service
+	DeliveryAddress     string            `json:"delivery_address,omitempty"`    // denormalized snapshot
+	DeliveryLatitude    *float64          `json:"delivery_latitude,omitempty"`
+	DeliveryLongitude   *float64          `json:"delivery_longitude,omitempty"`
+	DeliveryState       string            `json:"delivery_state,omitempty"`   // place of supply for GST, from the saved address; empty means the restaurant's state
+	RestaurantGSTIN     *string           `json:"restaurant_gstin,omitempty"` // snapshot for invoices
+	SpecialInstructions *string           `json:"special_instructions,omitempty"`
+	ScheduledFor        *time.Time        `json:"scheduled_for,omitempty"`           // requested delivery / pickup time
+	ReleaseAt           *time.Time        `json:"release_at,omitempty"`              // when a SCHEDULED order goes to the kitchen
+	EstimatedDeliveryAt *time.Time        `json:"estimated_delivery_time,omitempty"` // latest ETA, refreshed on every status change
+	DeliveredAt         *time.Time        `json:"actual_delivery_time,omitempty"`    // when it reached DELIVERED
+	RiderID             *int64            `json:"rider_id,omitempty"`                // assigned delivery partner
+	RiderLatitude       *float64          `json:"rider_latitude,omitempty"`
+	RiderLongitude      *float64          `json:"rider_longitude,omitempty"`
+	RiderLocationAt     *time.Time        `json:"rider_location_at,omitempty"`
+	CancelReasonCode    *string           `json:"cancel_reason_code,omitempty"`
+	CancelNote          *string           `json:"cancel_note,omitempty"`
+	CancelledBy         *string           `json:"cancelled_by,omitempty"` // actor role
+	CancelledAt         *time.Time        `json:"cancelled_at,omitempty"`
+	RefundRequestedAt   *time.Time        `json:"refund_requested_at,omitempty"` // payment-service accepted the refund
+	Metadata            json.RawMessage   `json:"metadata,omitempty"`            // JSONB for extra info
+	Items               []OrderItem       `json:"items,omitempty"`               // loaded on demand
+	CreatedAt           *time.Time        `json:"created_at,omitempty"`
+	UpdatedAt           *time.Time        `json:"updated_at,omitempty"`
+}
+
+// OrderItem represents items inside an order
+type OrderItem struct {
+	ID                  int64           `json:"id"`
+	OrderID             int64           `json:"order_id,omitempty"`
+	MenuItemID          *int64          `json:"menu_item_id,omitempty"` // optional snapshot if menu_item deleted later
+	Name                string          `json:"name"`
+	Quantity            int             `json:"quantity"`
+	UnitPrice           float64         `json:"unit_price"`
+	TotalPrice          float64         `json:"total_price"`
+	Options             json.RawMessage `json:"options,omitempty"` // JSON array of selected options/modifiers
+	SpecialInstructions *string         `json:"special_instructions,omitempty"`
+	ParticipantID       *int64          `json:"participant_id,omitempty"`   // group orders: who added the line
+	ParticipantName     *string         `json:"participant_name,omitempty"` // nickname snapshot for split bills
+	Tax                 *LineTax        `json:"tax,omitempty"`              // GST breakdown, nil on lines priced before the tax engine
+	CreatedAt           *time.Time      `json:"created_at,omitempty"`
+	// PriceLocked keeps UnitPrice through re-pricing: an amendment does not touch the line
+	PriceLocked bool `json:"-"`
+}
+
+// OrderStatusHistory is one entry of an order's status timeline
+type OrderStatusHistory struct {
+	ID          int64      `json:"id"`
+	OrderID     int64      `json:"order_id"`
+	FromStatus  string     `json:"from_status,omitempty"` // empty for the initial PLACED entry
+	ToStatus    string     `json:"to_status"`
+	ActorUserID *int64     `json:"actor_user_id,omitempty"`
+	ActorRole   string     `json:"actor_role,omitempty"` // CUSTOMER | RESTAURANT | RIDER | ADMIN | SYSTEM
+	Reason      *string    `json:"reason,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
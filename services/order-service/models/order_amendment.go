@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// What an amendment of a paid order does with the price difference
+const (
+	AmendPaymentNone   = "NONE"   // not paid yet, or the total did not change
+	AmendPaymentCharge = "CHARGE" // the customer pays the difference
+	AmendPaymentRefund = "REFUND" // the difference goes back to the customer
+)
+
+// What became of an amendment charge when its order was cancelled
+const (
+	AmendChargeCaptured = "CAPTURED" // the customer had paid it, so it is refunded with the order
+	AmendChargeVoided   = "VOIDED"   // withdrawn before it was paid
+)
+
+// OrderSnapshot is an order's lines and amounts at one point in time
+type OrderSnapshot struct {
+	Items          []OrderItem `json:"items"`
+	SubtotalAmount float64     `json:"subtotal_amount"`
+	TaxAmount      float64     `json:"tax_amount"`
+	DeliveryFee    float64     `json:"delivery_fee"`
+	TipAmount      float64     `json:"tip_amount"`
+	DiscountAmount float64     `json:"discount_amount"`
+	TotalAmount    float64     `json:"total_amount"`
+}
+
+// OrderAmendment records one change to an order's lines before the restaurant confirmed it
+type OrderAmendment struct {
+	ID                 int64          `json:"id"`
+	OrderID            int64          `json:"order_id"`
+	ActorUserID        *int64         `json:"actor_user_id,omitempty"`
+	ActorRole          string         `json:"actor_role"`
+	Before             *OrderSnapshot `json:"before"`
+	After              *OrderSnapshot `json:"after"`
+	PriceDifference    float64        `json:"price_difference"` // after - before
+	PaymentAction      string         `json:"payment_action"`
+	PaymentReference   string         `json:"payment_reference,omitempty"`    // payment-service reference of the charge or refund
+	PaymentRequestedAt *time.Time     `json:"payment_requested_at,omitempty"` // payment-service accepted the charge or refund
+	ChargeOutcome      string         `json:"charge_outcome,omitempty"`       // CAPTURED or VOIDED, once the order is cancelled
+	CreatedAt          *time.Time     `json:"created_at,omitempty"`
+}
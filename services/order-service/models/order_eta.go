@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+/*
+OrderETA is one prediction of when an order reaches the customer, with the parts it was
+built from. A new one is stored at placement and on every status change; together with
+orders.actual_delivery_time they are what the estimator is calibrated against offline.
+*/
+type OrderETA struct {
+	ID                    int64     `json:"id"`
+	OrderID               int64     `json:"order_id"`
+	OrderStatus           string    `json:"order_status"` // status the prediction was made in
+	EstimatedDeliveryTime time.Time `json:"estimated_delivery_time"`
+	PrepMinutes           int       `json:"prep_minutes"`
+	QueueDepth            int       `json:"queue_depth"` // other orders in the kitchen
+	QueueMinutes          int       `json:"queue_minutes"`
+	TravelMinutes         int       `json:"travel_minutes"`
+	DistanceKm            *float64  `json:"distance_km,omitempty"` // nil when coordinates are missing
+	CreatedAt             time.Time `json:"created_at"`
+}
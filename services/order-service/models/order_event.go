@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Order event types pushed to live streams
+const (
+	OrderEventPlaced        = "ORDER_PLACED"
+	OrderEventScheduled     = "ORDER_SCHEDULED"
+	OrderEventStatusChanged = "ORDER_STATUS_CHANGED"
+	OrderEventRiderAssigned = "RIDER_ASSIGNED"
+	OrderEventRiderLocation = "RIDER_LOCATION"
+	OrderEventAmended       = "ORDER_AMENDED"
+)
+
+/*
+OrderEvent is one entry of the order event log that backs the live streams. Seq numbers
+events in the order their transactions committed; streams send it as the event id and
+resume after it.
+*/
+type OrderEvent struct {
+	ID           int64           `json:"id"`
+	Seq          int64           `json:"seq"`
+	RestaurantID int64           `json:"restaurant_id"`
+	OrderID      int64           `json:"order_id"`
+	UserID       int64           `json:"user_id"` // the order's customer
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	CreatedAt    *time.Time      `json:"created_at,omitempty"`
+}
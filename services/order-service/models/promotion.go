@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Promotion discount types
+const (
+	PromotionFlat    = "FLAT"    // DiscountValue rupees off
+	PromotionPercent = "PERCENT" // DiscountValue percent off, up to MaxDiscount
+)
+
+/*
+Promotion is a coupon (with a Code) or an automatic offer (without one). Restaurant
+promotions apply to that restaurant only; platform promotions (no RestaurantID) apply
+everywhere or to RestaurantIDs. With Tags only lines whose menu item carries one of them
+are discounted. An order gets at most one promotion.
+*/
+type Promotion struct {
+	ID             int64      `json:"id"`
+	RestaurantID   *int64     `json:"restaurant_id,omitempty"` // nil for platform promotions
+	Code           *string    `json:"code,omitempty"`          // nil for automatic offers
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	DiscountType   string     `json:"discount_type"` // FLAT | PERCENT
+	DiscountValue  float64    `json:"discount_value"`
+	MaxDiscount    *float64   `json:"max_discount,omitempty"` // cap for PERCENT
+	MinSubtotal    float64    `json:"min_subtotal"`
+	FirstOrderOnly bool       `json:"first_order_only"`
+	RestaurantIDs  []int64    `json:"restaurant_ids,omitempty"` // platform promotions limited to these restaurants
+	Tags           []string   `json:"tags,omitempty"`           // menu item tags the discount applies to
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	DailyStart     string     `json:"daily_start,omitempty"` // "15:04" business time, e.g. lunch offers
+	DailyEnd       string     `json:"daily_end,omitempty"`
+	UsageLimit     *int       `json:"usage_limit,omitempty"`    // redemptions in total
+	PerUserLimit   *int       `json:"per_user_limit,omitempty"` // redemptions per customer
+	IsActive       bool       `json:"is_active"`
+	Redemptions    int        `json:"redemptions"` // orders that used it, cancelled ones not counted
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// PromotionRedemption records the discount an order got; written with the order
+type PromotionRedemption struct {
+	ID             int64      `json:"id"`
+	PromotionID    int64      `json:"promotion_id"`
+	OrderID        int64      `json:"order_id"`
+	UserID         int64      `json:"user_id"`
+	Code           *string    `json:"code,omitempty"`
+	DiscountAmount float64    `json:"discount_amount"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+}
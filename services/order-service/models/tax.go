@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+/*
+TaxRule is a GST rate for menu items. A rule matches by category, by tag, or is the
+catch-all when it has neither. Rules without a RestaurantID are platform defaults;
+a restaurant's own rules win over them.
+*/
+type TaxRule struct {
+	ID           int64      `json:"id"`
+	RestaurantID *int64     `json:"restaurant_id,omitempty"` // nil for platform defaults
+	CategoryID   *int64     `json:"category_id,omitempty"`
+	Tag          *string    `json:"tag,omitempty"`
+	Label        string     `json:"label"`        // shown on invoices, e.g. "GST food"
+	RatePercent  float64    `json:"rate_percent"` // total GST rate, e.g. 5 or 18
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// LineTax is the GST charged on one order line. Intra-state supplies split the rate
+// evenly into CGST and SGST, inter-state supplies pay it all as IGST.
+type LineTax struct {
+	Label         string  `json:"label,omitempty"`
+	RatePercent   float64 `json:"rate_percent"`
+	TaxableAmount float64 `json:"taxable_amount"` // line total less its share of the discount
+	CGSTAmount    float64 `json:"cgst_amount"`
+	SGSTAmount    float64 `json:"sgst_amount"`
+	IGSTAmount    float64 `json:"igst_amount"`
+	TotalAmount   float64 `json:"total_amount"`
+}
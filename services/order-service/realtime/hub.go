@@ -0,0 +1,63 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// subscriber buffer; a client that falls this far behind is dropped and resyncs via Last-Event-ID
+const subscriberBuffer = 64
+
+// Subscription receives the events accepted by its filter until it is closed
+type Subscription struct {
+	C      chan models.OrderEvent
+	filter func(models.OrderEvent) bool
+}
+
+// Hub fans order events out to the streams connected to this replica
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+func (h *Hub) Subscribe(filter func(models.OrderEvent) bool) *Subscription {
+	sub := &Subscription{C: make(chan models.OrderEvent, subscriberBuffer), filter: filter}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe is safe to call more than once
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.C)
+	}
+	h.mu.Unlock()
+}
+
+func (h *Hub) Publish(e models.OrderEvent) {
+	var slow []*Subscription
+	h.mu.RLock()
+	for sub := range h.subs {
+		if !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.C <- e:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+	for _, sub := range slow {
+		h.Unsubscribe(sub)
+	}
+}
@@ -0,0 +1,77 @@
+package realtime
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/lib/pq"
+)
+
+/*
+Listen relays order events committed by any replica to this replica's hub.
+Writers NOTIFY the event id inside their transaction (see OrderEventRepo.Insert),
+so every replica behind the load balancer sees every event. After a dropped
+connection it catches up from the last position (OrderEvent.Seq) it saw. Listen blocks; run it in a goroutine.
+*/
+func Listen(connStr string, events repository.OrderEventRepo, hub *Hub) {
+	listener := pq.NewListener(connStr, 2*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("order events listener:", err)
+		}
+	})
+	if err := listener.Listen(repository.OrderEventsChannel); err != nil {
+		log.Println("order events listener: failed to LISTEN:", err)
+		return
+	}
+
+	// start from the current tail; streams replay older events themselves
+	lastSeen, err := events.LatestSeq()
+	if err != nil {
+		log.Println("order events listener: failed to read latest event position:", err)
+	}
+
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				// reconnected: notifications may have been lost in between
+				lastSeen = catchUp(events, hub, lastSeen)
+				continue
+			}
+			id, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				continue
+			}
+			e, err := events.GetByID(id)
+			if err != nil || e == nil {
+				log.Println("order events listener: failed to load event", id, err)
+				continue
+			}
+			hub.Publish(*e)
+			if e.Seq > lastSeen {
+				lastSeen = e.Seq
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+func catchUp(events repository.OrderEventRepo, hub *Hub, lastSeen int64) int64 {
+	for {
+		batch, err := events.ListSince(lastSeen, 500)
+		if err != nil {
+			log.Println("order events listener: catch up failed:", err)
+			return lastSeen
+		}
+		for _, e := range batch {
+			hub.Publish(e)
+			lastSeen = e.Seq
+		}
+		if len(batch) < 500 {
+			return lastSeen
+		}
+	}
+}
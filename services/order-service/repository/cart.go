@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+type CartRepo interface {
+	// GetOrCreate returns the user's cart, creating an empty one on first use
+	GetOrCreate(userID int64) (*models.Cart, error)
+	SetRestaurant(cartID int64, restaurantID *int64) error
+	GetItems(cartID int64) ([]models.CartItem, error)
+	GetItem(cartID, itemID int64) (*models.CartItem, error)
+	AddItem(item *models.CartItem) (*models.CartItem, error)
+	UpdateItem(item *models.CartItem) error
+	DeleteItem(cartID, itemID int64) error
+	// Clear empties the cart; pass the checkout tx so the cart only empties if the order commits
+	Clear(tx *sql.Tx, cartID int64) error
+}
+
+type cartRepo struct {
+	db *sql.DB
+}
+
+func NewCartRepo(db *sql.DB) CartRepo {
+	return &cartRepo{db: db}
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (r *cartRepo) GetOrCreate(userID int64) (*models.Cart, error) {
+	now := time.Now().UTC()
+	var c models.Cart
+	var restaurantID sql.NullInt64
+	var createdAt, updatedAt time.Time
+	// the no-op update makes RETURNING yield the existing row as well
+	err := r.db.QueryRow(`
+		INSERT INTO carts (user_id, created_at, updated_at) VALUES ($1,$2,$2)
+		ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING id, user_id, restaurant_id, created_at, updated_at
+	`, userID, now).Scan(&c.ID, &c.UserID, &restaurantID, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if restaurantID.Valid {
+		v := restaurantID.Int64
+		c.RestaurantID = &v
+	}
+	c.CreatedAt = &createdAt
+	c.UpdatedAt = &updatedAt
+	return &c, nil
+}
+
+func (r *cartRepo) SetRestaurant(cartID int64, restaurantID *int64) error {
+	_, err := r.db.Exec(`UPDATE carts SET restaurant_id=$1, updated_at=$2 WHERE id=$3`,
+		nullableInt64(restaurantID), time.Now().UTC(), cartID)
+	return err
+}
+
+const cartItemColumns = `id, cart_id, menu_item_id, quantity, options, special_instructions, created_at, updated_at`
+
+func scanCartItem(sc rowScanner) (*models.CartItem, error) {
+	var it models.CartItem
+	var options, special sql.NullString
+	var createdAt, updatedAt time.Time
+	if err := sc.Scan(&it.ID, &it.CartID, &it.MenuItemID, &it.Quantity, &options, &special, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if options.Valid {
+		it.Options = []byte(options.String)
+	}
+	if special.Valid {
+		v := special.String
+		it.SpecialInstructions = &v
+	}
+	it.CreatedAt = &createdAt
+	it.UpdatedAt = &updatedAt
+	return &it, nil
+}
+
+func (r *cartRepo) GetItems(cartID int64) ([]models.CartItem, error) {
+	rows, err := r.db.Query(`SELECT `+cartItemColumns+` FROM cart_items WHERE cart_id=$1 ORDER BY id`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.CartItem{}
+	for rows.Next() {
+		it, err := scanCartItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+func (r *cartRepo) GetItem(cartID, itemID int64) (*models.CartItem, error) {
+	it, err := scanCartItem(r.db.QueryRow(`SELECT `+cartItemColumns+` FROM cart_items WHERE cart_id=$1 AND id=$2`, cartID, itemID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return it, nil
+}
+
+func (r *cartRepo) AddItem(item *models.CartItem) (*models.CartItem, error) {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`
+		INSERT INTO cart_items (cart_id, menu_item_id, quantity, options, special_instructions, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$6) RETURNING id
+	`, item.CartID, item.MenuItemID, item.Quantity, rawMessageOrNil(item.Options), nullStringPtr(item.SpecialInstructions), now).Scan(&item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.CreatedAt = &now
+	item.UpdatedAt = &now
+	r.touch(item.CartID, now)
+	return item, nil
+}
+
+func (r *cartRepo) UpdateItem(item *models.CartItem) error {
+	now := time.Now().UTC()
+	res, err := r.db.Exec(`
+		UPDATE cart_items SET quantity=$1, options=$2, special_instructions=$3, updated_at=$4
+		WHERE cart_id=$5 AND id=$6
+	`, item.Quantity, rawMessageOrNil(item.Options), nullStringPtr(item.SpecialInstructions), now, item.CartID, item.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	item.UpdatedAt = &now
+	r.touch(item.CartID, now)
+	return nil
+}
+
+func (r *cartRepo) DeleteItem(cartID, itemID int64) error {
+	res, err := r.db.Exec(`DELETE FROM cart_items WHERE cart_id=$1 AND id=$2`, cartID, itemID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	r.touch(cartID, time.Now().UTC())
+	return nil
+}
+
+func (r *cartRepo) Clear(tx *sql.Tx, cartID int64) error {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	if _, err := ex.Exec(`DELETE FROM cart_items WHERE cart_id=$1`, cartID); err != nil {
+		return err
+	}
+	_, err := ex.Exec(`UPDATE carts SET restaurant_id=NULL, updated_at=$1 WHERE id=$2`, time.Now().UTC(), cartID)
+	return err
+}
+
+// touch bumps the cart's updated_at; it is informational so errors are ignored
+func (r *cartRepo) touch(cartID int64, now time.Time) {
+	_, _ = r.db.Exec(`UPDATE carts SET updated_at=$1 WHERE id=$2`, now, cartID)
+}
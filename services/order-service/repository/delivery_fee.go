@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+type DeliveryFeeRepo interface {
+	// GetConfig returns the city's config, or the default when it has none
+	GetConfig(city string) (*models.DeliveryFeeConfig, error)
+	ListConfigs() ([]models.DeliveryFeeConfig, error)
+	// UpsertConfig creates or replaces the config of cfg.City
+	UpsertConfig(cfg *models.DeliveryFeeConfig) error
+
+	// InsertQuote stores q, within tx when given
+	InsertQuote(tx *sql.Tx, q *models.DeliveryFeeQuote) error
+	// GetQuote returns nil when there is no such quote
+	GetQuote(id int64) (*models.DeliveryFeeQuote, error)
+	// LockQuote holds the quote until tx ends and reports whether an order already used it
+	LockQuote(tx *sql.Tx, id int64) (bool, error)
+
+	// surge inputs
+	// CountAwaitingRider is how many of the city's delivery orders are not cooked or collected yet and have no rider
+	CountAwaitingRider(city string) (int, error)
+	// RiderSupply counts the city's riders seen since: all of them, and those free for a new order
+	RiderSupply(city string, since time.Time) (online int, available int, err error)
+	UpsertPresence(p *models.RiderPresence) error
+}
+
+type deliveryFeeRepo struct {
+	db *sql.DB
+}
+
+func NewDeliveryFeeRepo(db *sql.DB) DeliveryFeeRepo {
+	return &deliveryFeeRepo{db: db}
+}
+
+const deliveryFeeConfigColumns = `id, city, slabs, small_order_threshold, small_order_surcharge, free_delivery_threshold,
+	       surge_max_multiplier, updated_at`
+
+func scanDeliveryFeeConfig(sc rowScanner) (*models.DeliveryFeeConfig, error) {
+	var cfg models.DeliveryFeeConfig
+	var slabs []byte
+	var free sql.NullFloat64
+	var updatedAt time.Time
+	if err := sc.Scan(&cfg.ID, &cfg.City, &slabs, &cfg.SmallOrderThreshold, &cfg.SmallOrderSurcharge, &free,
+		&cfg.SurgeMaxMultiplier, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(slabs, &cfg.Slabs); err != nil {
+		return nil, err
+	}
+	if free.Valid {
+		v := free.Float64
+		cfg.FreeDeliveryThreshold = &v
+	}
+	cfg.UpdatedAt = &updatedAt
+	return &cfg, nil
+}
+
+func (r *deliveryFeeRepo) GetConfig(city string) (*models.DeliveryFeeConfig, error) {
+	cfg, err := scanDeliveryFeeConfig(r.db.QueryRow(`
+		SELECT `+deliveryFeeConfigColumns+` FROM delivery_fee_configs
+		WHERE LOWER(city) = LOWER($1) OR city = ''
+		ORDER BY city = '' LIMIT 1
+	`, city))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return cfg, err
+}
+
+func (r *deliveryFeeRepo) ListConfigs() ([]models.DeliveryFeeConfig, error) {
+	rows, err := r.db.Query(`SELECT ` + deliveryFeeConfigColumns + ` FROM delivery_fee_configs ORDER BY LOWER(city)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.DeliveryFeeConfig{}
+	for rows.Next() {
+		cfg, err := scanDeliveryFeeConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *cfg)
+	}
+	return out, rows.Err()
+}
+
+func (r *deliveryFeeRepo) UpsertConfig(cfg *models.DeliveryFeeConfig) error {
+	slabs, err := json.Marshal(cfg.Slabs)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	cfg.UpdatedAt = &now
+	return r.db.QueryRow(`
+		INSERT INTO delivery_fee_configs (city, slabs, small_order_threshold, small_order_surcharge, free_delivery_threshold,
+			surge_max_multiplier, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (LOWER(city)) DO UPDATE SET slabs=EXCLUDED.slabs, small_order_threshold=EXCLUDED.small_order_threshold,
+			small_order_surcharge=EXCLUDED.small_order_surcharge, free_delivery_threshold=EXCLUDED.free_delivery_threshold,
+			surge_max_multiplier=EXCLUDED.surge_max_multiplier, updated_at=EXCLUDED.updated_at
+		RETURNING id
+	`, cfg.City, slabs, cfg.SmallOrderThreshold, cfg.SmallOrderSurcharge, cfg.FreeDeliveryThreshold,
+		cfg.SurgeMaxMultiplier, now).Scan(&cfg.ID)
+}
+
+func (r *deliveryFeeRepo) InsertQuote(tx *sql.Tx, q *models.DeliveryFeeQuote) error {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	if q.CreatedAt.IsZero() {
+		q.CreatedAt = time.Now().UTC()
+	}
+	return ex.QueryRow(`
+		INSERT INTO delivery_fee_quotes (
+			user_id, restaurant_id, city, latitude, longitude, distance_km, distance_fee, surge_multiplier,
+			open_orders, available_riders, small_order_threshold, small_order_surcharge, free_delivery_threshold,
+			expires_at, created_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+		RETURNING id
+	`, q.UserID, q.RestaurantID, q.City, q.Latitude, q.Longitude, q.DistanceKm, q.DistanceFee, q.SurgeMultiplier,
+		q.OpenOrders, q.AvailableRiders, q.SmallOrderThreshold, q.SmallOrderSurcharge, q.FreeDeliveryThreshold,
+		q.ExpiresAt, q.CreatedAt).Scan(&q.ID)
+}
+
+func (r *deliveryFeeRepo) GetQuote(id int64) (*models.DeliveryFeeQuote, error) {
+	var q models.DeliveryFeeQuote
+	var lat, lon, distance, free sql.NullFloat64
+	err := r.db.QueryRow(`
+		SELECT id, user_id, restaurant_id, city, latitude, longitude, distance_km, distance_fee, surge_multiplier,
+		       open_orders, available_riders, small_order_threshold, small_order_surcharge, free_delivery_threshold,
+		       expires_at, created_at
+		FROM delivery_fee_quotes WHERE id=$1
+	`, id).Scan(&q.ID, &q.UserID, &q.RestaurantID, &q.City, &lat, &lon, &distance, &q.DistanceFee, &q.SurgeMultiplier,
+		&q.OpenOrders, &q.AvailableRiders, &q.SmallOrderThreshold, &q.SmallOrderSurcharge, &free,
+		&q.ExpiresAt, &q.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lat.Valid && lon.Valid {
+		la, lo := lat.Float64, lon.Float64
+		q.Latitude, q.Longitude = &la, &lo
+	}
+	if distance.Valid {
+		v := distance.Float64
+		q.DistanceKm = &v
+	}
+	if free.Valid {
+		v := free.Float64
+		q.FreeDeliveryThreshold = &v
+	}
+	return &q, nil
+}
+
+func (r *deliveryFeeRepo) LockQuote(tx *sql.Tx, id int64) (bool, error) {
+	if tx == nil {
+		return false, errors.New("transaction required")
+	}
+	var used bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM orders WHERE delivery_fee_quote_id = q.id)
+		FROM delivery_fee_quotes q WHERE q.id=$1 FOR UPDATE OF q
+	`, id).Scan(&used)
+	return used, err
+}
+
+func (r *deliveryFeeRepo) CountAwaitingRider(city string) (int, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM orders o JOIN restaurants r ON r.id = o.restaurant_id
+		WHERE LOWER(r.city) = LOWER($1) AND o.rider_id IS NULL
+		  AND COALESCE(o.order_type, 'DELIVERY') = 'DELIVERY'
+		  AND o.order_status IN ($2, $3, $4, $5)
+	`, city, models.OrderStatusPlaced, models.OrderStatusConfirmed, models.OrderStatusPreparing, models.OrderStatusReady).Scan(&n)
+	return n, err
+}
+
+func (r *deliveryFeeRepo) RiderSupply(city string, since time.Time) (int, int, error) {
+	var online, available int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE p.is_available AND NOT EXISTS (
+		           SELECT 1 FROM orders o WHERE o.rider_id = p.rider_id AND o.order_status IN ($3, $4, $5, $6)))
+		FROM rider_presence p
+		WHERE LOWER(p.city) = LOWER($1) AND p.last_seen_at >= $2
+	`, city, since, models.OrderStatusConfirmed, models.OrderStatusPreparing, models.OrderStatusReady,
+		models.OrderStatusOutForDelivery).Scan(&online, &available)
+	return online, available, err
+}
+
+func (r *deliveryFeeRepo) UpsertPresence(p *models.RiderPresence) error {
+	_, err := r.db.Exec(`
+		INSERT INTO rider_presence (rider_id, city, is_available, latitude, longitude, last_seen_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (rider_id) DO UPDATE SET city=EXCLUDED.city, is_available=EXCLUDED.is_available,
+			latitude=EXCLUDED.latitude, longitude=EXCLUDED.longitude, last_seen_at=EXCLUDED.last_seen_at
+	`, p.RiderID, p.City, p.IsAvailable, p.Latitude, p.Longitude, p.LastSeenAt)
+	return err
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+type DiningSessionRepo interface {
+	// Open returns the table's OPEN session, creating it if there is none; created tells which
+	Open(table *models.RestaurantTable, openedBy *int64) (s *models.DiningSession, created bool, err error)
+	GetByID(id int64) (*models.DiningSession, error)
+	ListByRestaurant(restaurantID int64, status string) ([]models.DiningSession, error)
+	// LockForOrder share-locks the session so it cannot be closed while an order is added to it
+	LockForOrder(tx *sql.Tx, id int64) (s *models.DiningSession, tableActive bool, err error)
+	// LockForClose locks the session exclusively, waiting for orders being added to it
+	LockForClose(tx *sql.Tx, id int64) (*models.DiningSession, error)
+	// Close moves the session to CLOSED and stores the bill; sql.ErrNoRows unless it was OPEN
+	Close(tx *sql.Tx, id, closedBy int64, bill *models.DiningBill) error
+	// ListOrders returns every order placed in the session, oldest round first
+	ListOrders(tx *sql.Tx, id int64) ([]models.Order, error)
+
+	// bill shares
+	// ReplaceShares drops the session's shares and stores the new split
+	ReplaceShares(tx *sql.Tx, sessionID int64, mode string, shares []models.BillShare) error
+	ListShares(sessionID int64) ([]models.BillShare, error)
+	// SetSharePayment records the payment opened for the share's current attempt; sql.ErrNoRows if the share is gone
+	SetSharePayment(shareID, paymentID int64) error
+	MarkSharePaid(shareID int64) error
+	// MarkShareFailed forgets the failed payment so a new attempt can be opened
+	MarkShareFailed(shareID int64) error
+	// Settle marks a CLOSED session SETTLED and its orders PAID; sql.ErrNoRows unless every share is paid
+	Settle(tx *sql.Tx, sessionID int64) error
+	// ListSettling returns CLOSED sessions that have been split and wait for payments
+	ListSettling(limit int) ([]int64, error)
+}
+
+type diningSessionRepo struct {
+	db *sql.DB
+}
+
+func NewDiningSessionRepo(db *sql.DB) DiningSessionRepo {
+	return &diningSessionRepo{db: db}
+}
+
+const diningSessionColumns = `s.id, s.restaurant_id, s.table_id, t.table_identifier, s.status, s.opened_by, s.closed_by,
+	s.opened_at, s.closed_at, s.settled_at, s.split_mode`
+
+func scanDiningSession(sc rowScanner) (*models.DiningSession, error) {
+	var s models.DiningSession
+	var tableIdentifier sql.NullString
+	var openedBy, closedBy sql.NullInt64
+	var openedAt time.Time
+	var closedAt, settledAt sql.NullTime
+	var splitMode sql.NullString
+	if err := sc.Scan(&s.ID, &s.RestaurantID, &s.TableID, &tableIdentifier, &s.Status, &openedBy, &closedBy,
+		&openedAt, &closedAt, &settledAt, &splitMode); err != nil {
+		return nil, err
+	}
+	if tableIdentifier.Valid {
+		s.TableIdentifier = tableIdentifier.String
+	}
+	if openedBy.Valid {
+		v := openedBy.Int64
+		s.OpenedBy = &v
+	}
+	if closedBy.Valid {
+		v := closedBy.Int64
+		s.ClosedBy = &v
+	}
+	s.OpenedAt = &openedAt
+	if closedAt.Valid {
+		v := closedAt.Time
+		s.ClosedAt = &v
+	}
+	if settledAt.Valid {
+		v := settledAt.Time
+		s.SettledAt = &v
+	}
+	if splitMode.Valid {
+		s.SplitMode = splitMode.String
+	}
+	return &s, nil
+}
+
+func (r *diningSessionRepo) Open(table *models.RestaurantTable, openedBy *int64) (*models.DiningSession, bool, error) {
+	// two guests scanning at once: the partial unique index lets only one insert win
+	var id int64
+	err := r.db.QueryRow(`
+		INSERT INTO dining_sessions (restaurant_id, table_id, status, opened_by, opened_at)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (table_id) WHERE status = 'OPEN' DO NOTHING
+		RETURNING id
+	`, table.RestaurantID, table.ID, models.DiningSessionOpen, nullableInt64(openedBy), time.Now().UTC()).Scan(&id)
+	created := true
+	if errors.Is(err, sql.ErrNoRows) {
+		created = false
+		err = r.db.QueryRow(`SELECT id FROM dining_sessions WHERE table_id=$1 AND status=$2`, table.ID, models.DiningSessionOpen).Scan(&id)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	s, err := r.GetByID(id)
+	if err != nil {
+		return nil, false, err
+	}
+	return s, created, nil
+}
+
+func (r *diningSessionRepo) GetByID(id int64) (*models.DiningSession, error) {
+	s, err := scanDiningSession(r.db.QueryRow(`
+		SELECT `+diningSessionColumns+`
+		FROM dining_sessions s LEFT JOIN restaurant_tables t ON t.id = s.table_id
+		WHERE s.id=$1
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *diningSessionRepo) ListByRestaurant(restaurantID int64, status string) ([]models.DiningSession, error) {
+	rows, err := r.db.Query(`
+		SELECT `+diningSessionColumns+`
+		FROM dining_sessions s LEFT JOIN restaurant_tables t ON t.id = s.table_id
+		WHERE s.restaurant_id=$1 AND ($2 = '' OR s.status = $2)
+		ORDER BY s.opened_at DESC, s.id DESC
+		LIMIT 200
+	`, restaurantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.DiningSession
+	for rows.Next() {
+		s, err := scanDiningSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+func (r *diningSessionRepo) LockForOrder(tx *sql.Tx, id int64) (*models.DiningSession, bool, error) {
+	return r.lock(tx, id, "FOR SHARE OF s")
+}
+
+func (r *diningSessionRepo) LockForClose(tx *sql.Tx, id int64) (*models.DiningSession, error) {
+	s, _, err := r.lock(tx, id, "FOR UPDATE OF s")
+	return s, err
+}
+
+func (r *diningSessionRepo) lock(tx *sql.Tx, id int64, mode string) (*models.DiningSession, bool, error) {
+	if tx == nil {
+		return nil, false, errors.New("transaction required")
+	}
+	var s models.DiningSession
+	var active sql.NullBool
+	var qrToken sql.NullString
+	err := tx.QueryRow(`
+		SELECT s.id, s.restaurant_id, s.table_id, s.status, t.is_active, t.qr_token
+		FROM dining_sessions s LEFT JOIN restaurant_tables t ON t.id = s.table_id
+		WHERE s.id=$1
+		`+mode, id).Scan(&s.ID, &s.RestaurantID, &s.TableID, &s.Status, &active, &qrToken)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	s.TableQRToken = qrToken.String
+	// a deleted table counts as inactive
+	return &s, active.Valid && active.Bool, nil
+}
+
+func (r *diningSessionRepo) Close(tx *sql.Tx, id, closedBy int64, bill *models.DiningBill) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	res, err := tx.Exec(`
+		UPDATE dining_sessions
+		SET status=$1, closed_by=$2, closed_at=$3,
+		    subtotal_amount=$4, tax_amount=$5, discount_amount=$6, tip_amount=$7, total_amount=$8
+		WHERE id=$9 AND status=$10
+	`, models.DiningSessionClosed, closedBy, time.Now().UTC(),
+		bill.SubtotalAmount, bill.TaxAmount, bill.DiscountAmount, bill.TipAmount, bill.TotalAmount,
+		id, models.DiningSessionOpen)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *diningSessionRepo) ListOrders(tx *sql.Tx, id int64) ([]models.Order, error) {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	rows, err := ex.Query(`SELECT `+orderColumns+` FROM orders WHERE dining_session_id=$1 ORDER BY created_at, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+/* ---------- bill shares ---------- */
+
+const billShareColumns = `id, session_id, label, payer_user_id, lines, subtotal_amount, tax_amount, tip_amount, discount_amount,
+	amount, payment_id, payment_attempt, payment_status, paid_at, created_at`
+
+func (r *diningSessionRepo) ReplaceShares(tx *sql.Tx, sessionID int64, mode string, shares []models.BillShare) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if _, err := tx.Exec(`DELETE FROM dining_bill_shares WHERE session_id=$1`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE dining_sessions SET split_mode=$1 WHERE id=$2`, mode, sessionID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i := range shares {
+		sh := &shares[i]
+		var lines interface{}
+		if len(sh.Lines) > 0 {
+			raw, err := json.Marshal(sh.Lines)
+			if err != nil {
+				return err
+			}
+			lines = string(raw)
+		}
+		err := tx.QueryRow(`
+			INSERT INTO dining_bill_shares (session_id, label, payer_user_id, lines, subtotal_amount, tax_amount, tip_amount,
+				discount_amount, amount, payment_status, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING id
+		`, sessionID, sh.Label, nullableInt64(sh.PayerUserID), lines, sh.SubtotalAmount, sh.TaxAmount, sh.TipAmount,
+			sh.DiscountAmount, sh.Amount, models.SharePaymentPending, now).Scan(&sh.ID)
+		if err != nil {
+			return err
+		}
+		sh.SessionID = sessionID
+		sh.PaymentStatus = models.SharePaymentPending
+		sh.CreatedAt = &now
+	}
+	return nil
+}
+
+func (r *diningSessionRepo) ListShares(sessionID int64) ([]models.BillShare, error) {
+	rows, err := r.db.Query(`SELECT `+billShareColumns+` FROM dining_bill_shares WHERE session_id=$1 ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.BillShare
+	for rows.Next() {
+		var sh models.BillShare
+		var payer, paymentID sql.NullInt64
+		var lines sql.NullString
+		var paidAt sql.NullTime
+		var createdAt time.Time
+		if err := rows.Scan(&sh.ID, &sh.SessionID, &sh.Label, &payer, &lines, &sh.SubtotalAmount, &sh.TaxAmount, &sh.TipAmount,
+			&sh.DiscountAmount, &sh.Amount, &paymentID, &sh.PaymentAttempt, &sh.PaymentStatus, &paidAt, &createdAt); err != nil {
+			return nil, err
+		}
+		if payer.Valid {
+			v := payer.Int64
+			sh.PayerUserID = &v
+		}
+		if lines.Valid {
+			if err := json.Unmarshal([]byte(lines.String), &sh.Lines); err != nil {
+				return nil, err
+			}
+		}
+		if paymentID.Valid {
+			v := paymentID.Int64
+			sh.PaymentID = &v
+		}
+		if paidAt.Valid {
+			v := paidAt.Time
+			sh.PaidAt = &v
+		}
+		sh.CreatedAt = &createdAt
+		out = append(out, sh)
+	}
+	return out, rows.Err()
+}
+
+func (r *diningSessionRepo) SetSharePayment(shareID, paymentID int64) error {
+	res, err := r.db.Exec(`
+		UPDATE dining_bill_shares SET payment_id=$1, payment_status=$2 WHERE id=$3 AND payment_status <> $4
+	`, paymentID, models.SharePaymentPending, shareID, models.SharePaymentPaid)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *diningSessionRepo) MarkSharePaid(shareID int64) error {
+	_, err := r.db.Exec(`
+		UPDATE dining_bill_shares SET payment_status=$1, paid_at=$2 WHERE id=$3 AND payment_status <> $1
+	`, models.SharePaymentPaid, time.Now().UTC(), shareID)
+	return err
+}
+
+func (r *diningSessionRepo) MarkShareFailed(shareID int64) error {
+	_, err := r.db.Exec(`
+		UPDATE dining_bill_shares SET payment_status=$1, payment_id=NULL, payment_attempt=payment_attempt+1
+		WHERE id=$2 AND payment_status=$3
+	`, models.SharePaymentFailed, shareID, models.SharePaymentPending)
+	return err
+}
+
+func (r *diningSessionRepo) Settle(tx *sql.Tx, sessionID int64) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	res, err := tx.Exec(`
+		UPDATE dining_sessions SET status=$1, settled_at=$2
+		WHERE id=$3 AND status=$4
+		  AND EXISTS (SELECT 1 FROM dining_bill_shares WHERE session_id=$3)
+		  AND NOT EXISTS (SELECT 1 FROM dining_bill_shares WHERE session_id=$3 AND payment_status <> $5)
+	`, models.DiningSessionSettled, now, sessionID, models.DiningSessionClosed, models.SharePaymentPaid)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	_, err = tx.Exec(`
+		UPDATE orders SET payment_status=$1, updated_at=$2
+		WHERE dining_session_id=$3 AND order_status <> $4
+	`, models.PaymentStatusPaid, now, sessionID, models.OrderStatusCancelled)
+	return err
+}
+
+func (r *diningSessionRepo) ListSettling(limit int) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id FROM dining_sessions s
+		WHERE s.status=$1 AND EXISTS (SELECT 1 FROM dining_bill_shares b WHERE b.session_id = s.id)
+		ORDER BY s.closed_at
+		LIMIT $2
+	`, models.DiningSessionClosed, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// ErrGroupCartClosed is returned by item writes once the group cart is no longer OPEN
+var ErrGroupCartClosed = errors.New("group cart is not open")
+
+type GroupCartRepo interface {
+	// Create inserts the cart and its host participant
+	Create(tx *sql.Tx, gc *models.GroupCart, host *models.GroupCartParticipant) error
+	GetByToken(joinToken string) (*models.GroupCart, error)
+	// SetStatus moves the cart from -> to; sql.ErrNoRows when it is not in from any more
+	SetStatus(tx *sql.Tx, cartID int64, from, to string, orderID *int64) error
+
+	// participants
+	AddParticipant(p *models.GroupCartParticipant) error
+	ListParticipants(cartID int64) ([]models.GroupCartParticipant, error)
+	GetParticipantByUser(cartID, userID int64) (*models.GroupCartParticipant, error)
+	GetParticipantByToken(cartID int64, token string) (*models.GroupCartParticipant, error)
+
+	// items; writes fail with ErrGroupCartClosed unless the cart is OPEN
+	ListItems(cartID int64) ([]models.GroupCartItem, error)
+	GetItem(cartID, itemID int64) (*models.GroupCartItem, error)
+	AddItem(item *models.GroupCartItem) error
+	UpdateItem(item *models.GroupCartItem) error
+	DeleteItem(cartID, itemID int64) error
+}
+
+type groupCartRepo struct {
+	db *sql.DB
+}
+
+func NewGroupCartRepo(db *sql.DB) GroupCartRepo {
+	return &groupCartRepo{db: db}
+}
+
+func (r *groupCartRepo) Create(tx *sql.Tx, gc *models.GroupCart, host *models.GroupCartParticipant) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	err := tx.QueryRow(`
+		INSERT INTO group_carts (restaurant_id, host_user_id, join_token, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$5) RETURNING id
+	`, gc.RestaurantID, gc.HostUserID, gc.JoinToken, gc.Status, now).Scan(&gc.ID)
+	if err != nil {
+		return err
+	}
+	gc.CreatedAt = &now
+	gc.UpdatedAt = &now
+
+	host.GroupCartID = gc.ID
+	if err := r.insertParticipant(tx, host, now); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *groupCartRepo) GetByToken(joinToken string) (*models.GroupCart, error) {
+	var gc models.GroupCart
+	var orderID sql.NullInt64
+	var createdAt, updatedAt time.Time
+	err := r.db.QueryRow(`
+		SELECT id, restaurant_id, host_user_id, join_token, status, order_id, created_at, updated_at
+		FROM group_carts WHERE join_token=$1
+	`, joinToken).Scan(&gc.ID, &gc.RestaurantID, &gc.HostUserID, &gc.JoinToken, &gc.Status, &orderID, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if orderID.Valid {
+		v := orderID.Int64
+		gc.OrderID = &v
+	}
+	gc.CreatedAt = &createdAt
+	gc.UpdatedAt = &updatedAt
+	return &gc, nil
+}
+
+func (r *groupCartRepo) SetStatus(tx *sql.Tx, cartID int64, from, to string, orderID *int64) error {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	res, err := ex.Exec(`
+		UPDATE group_carts SET status=$1, order_id=COALESCE($2, order_id), updated_at=$3
+		WHERE id=$4 AND status=$5
+	`, to, nullableInt64(orderID), time.Now().UTC(), cartID, from)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+/* ---------- participants ---------- */
+
+func (r *groupCartRepo) AddParticipant(p *models.GroupCartParticipant) error {
+	return r.insertParticipant(r.db, p, time.Now().UTC())
+}
+
+func (r *groupCartRepo) insertParticipant(ex dbtx, p *models.GroupCartParticipant, now time.Time) error {
+	err := ex.QueryRow(`
+		INSERT INTO group_cart_participants (group_cart_id, user_id, nickname, is_host, token, joined_at)
+		VALUES ($1,$2,$3,$4,$5,$6) RETURNING id
+	`, p.GroupCartID, nullableInt64(p.UserID), p.Nickname, p.IsHost, p.Token, now).Scan(&p.ID)
+	if err != nil {
+		return err
+	}
+	p.JoinedAt = &now
+	return nil
+}
+
+const participantColumns = `id, group_cart_id, user_id, nickname, is_host, token, joined_at`
+
+func scanParticipant(sc rowScanner) (*models.GroupCartParticipant, error) {
+	var p models.GroupCartParticipant
+	var userID sql.NullInt64
+	var joinedAt time.Time
+	if err := sc.Scan(&p.ID, &p.GroupCartID, &userID, &p.Nickname, &p.IsHost, &p.Token, &joinedAt); err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		v := userID.Int64
+		p.UserID = &v
+	}
+	p.JoinedAt = &joinedAt
+	return &p, nil
+}
+
+func (r *groupCartRepo) ListParticipants(cartID int64) ([]models.GroupCartParticipant, error) {
+	rows, err := r.db.Query(`SELECT `+participantColumns+` FROM group_cart_participants WHERE group_cart_id=$1 ORDER BY id`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.GroupCartParticipant{}
+	for rows.Next() {
+		p, err := scanParticipant(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func (r *groupCartRepo) GetParticipantByUser(cartID, userID int64) (*models.GroupCartParticipant, error) {
+	return r.getParticipant(`SELECT `+participantColumns+` FROM group_cart_participants WHERE group_cart_id=$1 AND user_id=$2`, cartID, userID)
+}
+
+func (r *groupCartRepo) GetParticipantByToken(cartID int64, token string) (*models.GroupCartParticipant, error) {
+	return r.getParticipant(`SELECT `+participantColumns+` FROM group_cart_participants WHERE group_cart_id=$1 AND token=$2`, cartID, token)
+}
+
+func (r *groupCartRepo) getParticipant(query string, args ...interface{}) (*models.GroupCartParticipant, error) {
+	p, err := scanParticipant(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+/* ---------- items ---------- */
+
+const groupItemColumns = `i.id, i.group_cart_id, i.participant_id, p.nickname, i.menu_item_id, i.quantity, i.options, i.special_instructions, i.created_at, i.updated_at`
+
+func scanGroupItem(sc rowScanner) (*models.GroupCartItem, error) {
+	var it models.GroupCartItem
+	var options, special sql.NullString
+	var createdAt, updatedAt time.Time
+	err := sc.Scan(&it.ID, &it.CartID, &it.ParticipantID, &it.ParticipantName, &it.MenuItemID, &it.Quantity,
+		&options, &special, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if options.Valid {
+		it.Options = []byte(options.String)
+	}
+	if special.Valid {
+		v := special.String
+		it.SpecialInstructions = &v
+	}
+	it.CreatedAt = &createdAt
+	it.UpdatedAt = &updatedAt
+	return &it, nil
+}
+
+func (r *groupCartRepo) ListItems(cartID int64) ([]models.GroupCartItem, error) {
+	rows, err := r.db.Query(`
+		SELECT `+groupItemColumns+`
+		FROM group_cart_items i JOIN group_cart_participants p ON p.id = i.participant_id
+		WHERE i.group_cart_id=$1 ORDER BY i.participant_id, i.id
+	`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.GroupCartItem{}
+	for rows.Next() {
+		it, err := scanGroupItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+func (r *groupCartRepo) GetItem(cartID, itemID int64) (*models.GroupCartItem, error) {
+	it, err := scanGroupItem(r.db.QueryRow(`
+		SELECT `+groupItemColumns+`
+		FROM group_cart_items i JOIN group_cart_participants p ON p.id = i.participant_id
+		WHERE i.group_cart_id=$1 AND i.id=$2
+	`, cartID, itemID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return it, nil
+}
+
+// item writes are conditional on the cart still being OPEN so they cannot race the host's lock
+
+func (r *groupCartRepo) AddItem(item *models.GroupCartItem) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`
+		INSERT INTO group_cart_items (group_cart_id, participant_id, menu_item_id, quantity, options, special_instructions, created_at, updated_at)
+		SELECT $1,$2,$3,$4,$5,$6,$7,$7
+		WHERE EXISTS (SELECT 1 FROM group_carts WHERE id=$1 AND status='OPEN')
+		RETURNING id
+	`, item.CartID, item.ParticipantID, item.MenuItemID, item.Quantity, rawMessageOrNil(item.Options), nullStringPtr(item.SpecialInstructions), now).Scan(&item.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGroupCartClosed
+		}
+		return err
+	}
+	item.CreatedAt = &now
+	item.UpdatedAt = &now
+	return nil
+}
+
+func (r *groupCartRepo) UpdateItem(item *models.GroupCartItem) error {
+	now := time.Now().UTC()
+	res, err := r.db.Exec(`
+		UPDATE group_cart_items SET quantity=$1, options=$2, special_instructions=$3, updated_at=$4
+		WHERE group_cart_id=$5 AND id=$6
+		  AND EXISTS (SELECT 1 FROM group_carts WHERE id=$5 AND status='OPEN')
+	`, item.Quantity, rawMessageOrNil(item.Options), nullStringPtr(item.SpecialInstructions), now, item.CartID, item.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrGroupCartClosed
+	}
+	item.UpdatedAt = &now
+	return nil
+}
+
+func (r *groupCartRepo) DeleteItem(cartID, itemID int64) error {
+	res, err := r.db.Exec(`
+		DELETE FROM group_cart_items
+		WHERE group_cart_id=$1 AND id=$2
+		  AND EXISTS (SELECT 1 FROM group_carts WHERE id=$1 AND status='OPEN')
+	`, cartID, itemID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrGroupCartClosed
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+/*
+NextInvoiceSequence hands out the next invoice sequence of a restaurant's financial year.
+Like NextOrderSequence the upsert row lock makes concurrent issuers queue up, and a rolled
+back issue gives its number back, so invoice numbers have no gaps.
+*/
+func (r *orderRepo) NextInvoiceSequence(tx *sql.Tx, restaurantID int64, financialYear string) (int, error) {
+	if tx == nil {
+		return 0, errors.New("transaction required")
+	}
+	var seq int
+	err := tx.QueryRow(`
+		INSERT INTO invoice_sequences (restaurant_id, financial_year, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (restaurant_id, financial_year) DO UPDATE
+			SET last_value = invoice_sequences.last_value + 1
+		RETURNING last_value
+	`, restaurantID, financialYear).Scan(&seq)
+	return seq, err
+}
+
+// InsertInvoice stores an issued invoice; sql.ErrNoRows when the order already has one
+func (r *orderRepo) InsertInvoice(tx *sql.Tx, inv *models.Invoice) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	snapshot, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(`
+		INSERT INTO invoices (order_id, restaurant_id, invoice_number, financial_year, kind, snapshot, issued_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (order_id) DO NOTHING
+		RETURNING id
+	`, inv.OrderID, inv.RestaurantID, inv.InvoiceNumber, inv.FinancialYear, inv.Kind, snapshot, inv.IssuedAt).Scan(&inv.ID)
+	return err
+}
+
+func (r *orderRepo) HasInvoice(tx *sql.Tx, orderID int64) (bool, error) {
+	if tx == nil {
+		return false, errors.New("transaction required")
+	}
+	var exists bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM invoices WHERE order_id=$1)`, orderID).Scan(&exists)
+	return exists, err
+}
+
+func (r *orderRepo) GetInvoiceByOrder(orderID int64) (*models.Invoice, error) {
+	var id int64
+	var snapshot []byte
+	err := r.db.QueryRow(`SELECT id, snapshot FROM invoices WHERE order_id=$1`, orderID).Scan(&id, &snapshot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var inv models.Invoice
+	if err := json.Unmarshal(snapshot, &inv); err != nil {
+		return nil, err
+	}
+	inv.ID = id
+	return &inv, nil
+}
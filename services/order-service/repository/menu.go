@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/lib/pq"
+)
+
+// MenuRepo reads the menus restaurant-service writes, so orders can price and check their lines
+type MenuRepo interface {
+	GetCategories(restaurantID int64) ([]models.MenuCategory, error)
+	// GetMenuItemsByIDs loads items with their modifier groups and serving windows
+	GetMenuItemsByIDs(ids []int64) ([]models.MenuItem, error)
+}
+
+type menuRepo struct {
+	db *sql.DB
+}
+
+func NewMenuRepo(db *sql.DB) MenuRepo {
+	return &menuRepo{db: db}
+}
+
+/* ---------- Categories ---------- */
+
+func (m *menuRepo) GetCategories(restaurantID int64) ([]models.MenuCategory, error) {
+	rows, err := m.db.Query(`
+		SELECT id, restaurant_id, name, slug, parent_id, sort_order, is_active, metadata, created_at, external_sku
+		FROM categories
+		WHERE restaurant_id = $1
+		ORDER BY sort_order, created_at
+	`, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.MenuCategory
+	for rows.Next() {
+		var c models.MenuCategory
+		var slug sql.NullString
+		var parentID sql.NullInt64
+		var isActive sql.NullBool
+		var metadata sql.NullString
+		var createdAt time.Time
+		var sku sql.NullString
+
+		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.Name, &slug, &parentID, &c.SortOrder, &isActive, &metadata, &createdAt, &sku); err != nil {
+			return nil, err
+		}
+		c.ExternalSKU = sku.String
+		if slug.Valid {
+			c.Slug = slug.String
+		}
+		if parentID.Valid {
+			v := parentID.Int64
+			c.ParentID = &v
+		}
+		if isActive.Valid {
+			c.IsActive = isActive.Bool
+		}
+		if metadata.Valid {
+			_ = json.Unmarshal([]byte(metadata.String), &c.Metadata) // best-effort
+		}
+		c.CreatedAt = &createdAt
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(out))
+	for _, c := range out {
+		ids = append(ids, c.ID)
+	}
+	windows, err := m.listMenuWindows("category_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		for _, w := range windows {
+			if *w.CategoryID == out[i].ID {
+				out[i].Windows = append(out[i].Windows, w)
+			}
+		}
+	}
+	return out, nil
+}
+
+/* ---------- Menu Items ---------- */
+
+// GetMenuItemsByIDs loads items regardless of restaurant or availability; callers validate both. Deleted items are left out.
+func (m *menuRepo) GetMenuItemsByIDs(ids []int64) ([]models.MenuItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Query(`
+		SELECT `+menuItemColumns+`
+		FROM menu_items
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.MenuItem
+	for rows.Next() {
+		itm, err := scanMenuItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, itm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := m.attachModifierGroups(out); err != nil {
+		return nil, err
+	}
+	return out, m.attachMenuWindows(out)
+}
+
+const menuItemColumns = `id, restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, metadata, image_url, created_at, updated_at, restock_at, external_sku`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMenuItem(sc rowScanner) (models.MenuItem, error) {
+	var itm models.MenuItem
+	var categoryID sql.NullInt64
+	var description sql.NullString
+	var currency sql.NullString
+	var availability sql.NullString
+	var isVeg sql.NullBool
+	var spiceLevel sql.NullInt64
+	var prep sql.NullInt64
+	var tags pq.StringArray
+	var metadata sql.NullString
+	var imageURL sql.NullString
+	var createdAt, updatedAt time.Time
+	var restockAt sql.NullTime
+	var sku sql.NullString
+
+	if err := sc.Scan(
+		&itm.ID, &itm.RestaurantID, &categoryID, &itm.Name, &description, &itm.Price, &currency, &availability, &isVeg, &spiceLevel, &prep, &tags, &metadata, &imageURL, &createdAt, &updatedAt, &restockAt, &sku,
+	); err != nil {
+		return itm, err
+	}
+	itm.ExternalSKU = sku.String
+	if categoryID.Valid {
+		v := categoryID.Int64
+		itm.CategoryID = &v
+	}
+	if description.Valid {
+		itm.Description = description.String
+	}
+	if currency.Valid {
+		itm.Currency = currency.String
+	}
+	if availability.Valid {
+		itm.Availability = availability.String
+	}
+	if isVeg.Valid {
+		itm.IsVeg = isVeg.Bool
+	}
+	if spiceLevel.Valid {
+		itm.SpiceLevel = int(spiceLevel.Int64)
+	}
+	if prep.Valid {
+		itm.PrepTimeMinutes = int(prep.Int64)
+	}
+	if len(tags) > 0 {
+		itm.Tags = tags
+	}
+	if metadata.Valid {
+		_ = json.Unmarshal([]byte(metadata.String), &itm.Metadata)
+	}
+	if imageURL.Valid {
+		itm.ImageURL = imageURL.String
+	}
+	if restockAt.Valid {
+		t := restockAt.Time
+		itm.RestockAt = &t
+	}
+	itm.CreatedAt = &createdAt
+	itm.UpdatedAt = &updatedAt
+	return itm, nil
+}
+
+/* ---------- Modifier groups ---------- */
+
+func (m *menuRepo) listModifierGroups(menuItemIDs []int64) ([]models.ModifierGroup, error) {
+	if len(menuItemIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Query(`
+		SELECT id, menu_item_id, name, min_select, max_select, sort_order, created_at, updated_at
+		FROM modifier_groups
+		WHERE menu_item_id = ANY($1)
+		ORDER BY menu_item_id, sort_order, id
+	`, pq.Array(menuItemIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.ModifierGroup
+	for rows.Next() {
+		g, err := scanModifierGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, m.attachModifierOptions(out)
+}
+
+func scanModifierGroup(sc rowScanner) (*models.ModifierGroup, error) {
+	var g models.ModifierGroup
+	var createdAt, updatedAt time.Time
+	if err := sc.Scan(&g.ID, &g.MenuItemID, &g.Name, &g.MinSelect, &g.MaxSelect, &g.SortOrder, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	g.CreatedAt = &createdAt
+	g.UpdatedAt = &updatedAt
+	g.Options = []models.ModifierOption{}
+	return &g, nil
+}
+
+// attachModifierOptions loads the options of groups in one query
+func (m *menuRepo) attachModifierOptions(groups []models.ModifierGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(groups))
+	at := make(map[int64]int, len(groups))
+	for i, g := range groups {
+		ids = append(ids, g.ID)
+		at[g.ID] = i
+	}
+	rows, err := m.db.Query(`
+		SELECT id, group_id, name, price_delta, is_available, sort_order
+		FROM modifier_options
+		WHERE group_id = ANY($1)
+		ORDER BY group_id, sort_order, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o models.ModifierOption
+		if err := rows.Scan(&o.ID, &o.GroupID, &o.Name, &o.PriceDelta, &o.IsAvailable, &o.SortOrder); err != nil {
+			return err
+		}
+		g := &groups[at[o.GroupID]]
+		g.Options = append(g.Options, o)
+	}
+	return rows.Err()
+}
+
+// attachModifierGroups fills in ModifierGroups of menu items, so pricing sees them wherever items are loaded
+func (m *menuRepo) attachModifierGroups(items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(items))
+	at := make(map[int64]int, len(items))
+	for i, itm := range items {
+		ids = append(ids, itm.ID)
+		at[itm.ID] = i
+	}
+	groups, err := m.listModifierGroups(ids)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		itm := &items[at[g.MenuItemID]]
+		itm.ModifierGroups = append(itm.ModifierGroups, g)
+	}
+	return nil
+}
+
+/* ---------- Serving windows ---------- */
+
+func (m *menuRepo) listMenuWindows(column string, ids []int64) ([]models.MenuWindow, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// column is "category_id" or "menu_item_id", never input
+	rows, err := m.db.Query(`
+		SELECT id, category_id, menu_item_id, weekday, start_time, end_time
+		FROM menu_windows WHERE `+column+` = ANY($1)
+		ORDER BY weekday, start_time, id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.MenuWindow
+	for rows.Next() {
+		var w models.MenuWindow
+		var categoryID, menuItemID sql.NullInt64
+		if err := rows.Scan(&w.ID, &categoryID, &menuItemID, &w.Weekday, &w.StartTime, &w.EndTime); err != nil {
+			return nil, err
+		}
+		if categoryID.Valid {
+			v := categoryID.Int64
+			w.CategoryID = &v
+		}
+		if menuItemID.Valid {
+			v := menuItemID.Int64
+			w.MenuItemID = &v
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// attachMenuWindows fills in the items' own windows and those of their categories
+func (m *menuRepo) attachMenuWindows(items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	itemIDs := make([]int64, 0, len(items))
+	var categoryIDs []int64
+	for _, itm := range items {
+		itemIDs = append(itemIDs, itm.ID)
+		if itm.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *itm.CategoryID)
+		}
+	}
+	own, err := m.listMenuWindows("menu_item_id", itemIDs)
+	if err != nil {
+		return err
+	}
+	byCategory, err := m.listMenuWindows("category_id", categoryIDs)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		itm := &items[i]
+		for _, w := range own {
+			if *w.MenuItemID == itm.ID {
+				itm.Windows = append(itm.Windows, w)
+			}
+		}
+		if itm.CategoryID == nil {
+			continue
+		}
+		for _, w := range byCategory {
+			if *w.CategoryID == *itm.CategoryID {
+				itm.CategoryWindows = append(itm.CategoryWindows, w)
+			}
+		}
+	}
+	return nil
+}
+
+func nullableInt64(p *int64) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
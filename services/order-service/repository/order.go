@@ -0,0 +1,790 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/lib/pq"
+)
+
+type OrderRepo interface {
+	CreateOrderWithItems(tx *sql.Tx, order *models.Order, items []models.OrderItem) (int64, error)
+	NextOrderSequence(tx *sql.Tx, restaurantID int64, businessDate time.Time) (int, error)
+	GetOrderStatus(orderID int64) (string, error)
+	UpdateOrderStatus(tx *sql.Tx, orderID int64, fromStatus, toStatus string) error
+	GetOrderByID(orderID int64) (*models.Order, error)
+	// LockOrder reads the order FOR UPDATE, so changes that start from its amounts run one at a time; nil when missing
+	LockOrder(tx *sql.Tx, orderID int64) (*models.Order, error)
+	// AssignRider with claim set only takes an order nobody rides yet, else ErrRiderTaken
+	AssignRider(tx *sql.Tx, orderID, riderID int64, fromStatuses []string, claim bool) error
+	UpdateRiderLocation(tx *sql.Tx, orderID, riderID int64, lat, lon float64, minInterval time.Duration) (bool, error)
+	SetCancellation(tx *sql.Tx, orderID int64, reasonCode string, note *string, actor string, paymentStatus string) error
+	ListPendingRefunds(limit int) ([]models.Order, error)
+	MarkRefundRequested(orderID int64) error
+	ListDueScheduledOrders(now time.Time, limit int) ([]models.Order, error)
+	FindOrdersByNumber(number string) ([]models.Order, error)
+	ListOrders(params ListOrdersParams) ([]models.Order, error)
+	GetItemsForOrders(orderIDs []int64) (map[int64][]models.OrderItem, error)
+	// GetOrderItems reads an order's lines inside tx, e.g. after LockOrder
+	GetOrderItems(tx *sql.Tx, orderID int64) ([]models.OrderItem, error)
+
+	// delivery ETA
+	// CountKitchenQueue is how many of the restaurant's orders, other than excludeOrderID, the kitchen still has to cook
+	CountKitchenQueue(restaurantID, excludeOrderID int64) (int, error)
+	SetEstimatedDelivery(tx *sql.Tx, orderID int64, at time.Time) error
+	SetDelivered(tx *sql.Tx, orderID int64, at time.Time) error
+	InsertETAPrediction(tx *sql.Tx, p *models.OrderETA) error
+
+	// invoices
+	NextInvoiceSequence(tx *sql.Tx, restaurantID int64, financialYear string) (int, error)
+	InsertInvoice(tx *sql.Tx, inv *models.Invoice) error
+	// HasInvoice sees invoices committed by others; lock the order first to keep it that way
+	HasInvoice(tx *sql.Tx, orderID int64) (bool, error)
+	GetInvoiceByOrder(orderID int64) (*models.Invoice, error)
+
+	// amendments
+	// UpdateAmounts rewrites the order's amounts while it is still in fromStatus; sql.ErrNoRows otherwise
+	UpdateAmounts(tx *sql.Tx, order *models.Order, fromStatus string) error
+	// ReplaceItems applies an amended basket: updates kept lines, deletes removed ones, inserts new ones
+	ReplaceItems(tx *sql.Tx, orderID int64, kept []models.OrderItem, removed []int64, added []models.OrderItem) error
+	InsertAmendment(tx *sql.Tx, a *models.OrderAmendment) error
+	ListAmendments(orderID int64) ([]models.OrderAmendment, error)
+	// ListPendingAmendmentPayments returns charges and refunds payment-service has not accepted yet, oldest first
+	ListPendingAmendmentPayments(limit int) ([]models.OrderAmendment, error)
+	MarkAmendmentPaymentRequested(amendmentID int64) error
+	// SetChargeOutcome records, once, whether a cancelled order's amendment charge was captured or voided
+	SetChargeOutcome(amendmentID int64, outcome string) error
+
+	// status history
+	InsertStatusHistory(tx *sql.Tx, h *models.OrderStatusHistory) error
+	GetStatusHistory(orderID int64) ([]models.OrderStatusHistory, error)
+}
+
+type orderRepo struct {
+	db *sql.DB
+}
+
+func NewOrderRepo(db *sql.DB) OrderRepo { return &orderRepo{db: db} }
+
+/*
+Note: CreateOrderWithItems MUST use the provided tx (transaction) for atomic writes.
+If tx == nil we will return an error — service must supply a tx.
+*/
+func (r *orderRepo) CreateOrderWithItems(tx *sql.Tx, order *models.Order, items []models.OrderItem) (int64, error) {
+	if tx == nil {
+		return 0, errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+
+	// Insert order and return id
+	var orderID int64
+	query := `
+		INSERT INTO orders (
+			order_number, user_id, restaurant_id, dining_session_id, order_type,
+			order_status, payment_status,
+			subtotal_amount, tax_amount, delivery_fee, tip_amount, discount_amount, total_amount,
+			delivery_address_id, delivery_address, delivery_latitude, delivery_longitude, delivery_state, restaurant_gstin,
+			special_instructions, scheduled_for, release_at, estimated_delivery_time, metadata, created_at, updated_at,
+			promotion_id, promo_code, delivery_fee_quote_id
+		) VALUES (
+			$1,$2,$3,$4,$5,
+			$6,$7,
+			$8,$9,$10,$11,$12,$13,
+			$14,$15,$16,$17,$18,$19,
+			$20,$21,$22,$23,$24,$25,$26,
+			$27,$28,$29
+		) RETURNING id
+	`
+	var diningSessionID interface{}
+	if order.DiningSessionID != nil {
+		diningSessionID = *order.DiningSessionID
+	}
+	var deliveryAddressID interface{}
+	if order.DeliveryAddressID != nil {
+		deliveryAddressID = *order.DeliveryAddressID
+	}
+
+	err := tx.QueryRow(query,
+		nullString(order.OrderNumber), order.UserID, order.RestaurantID, diningSessionID, nullString(order.OrderType),
+		nullString(order.OrderStatus), nullString(order.PaymentStatus),
+		order.SubtotalAmount, order.TaxAmount, order.DeliveryFee, order.TipAmount, order.DiscountAmount, order.TotalAmount,
+		deliveryAddressID, nullString(order.DeliveryAddress), order.DeliveryLatitude, order.DeliveryLongitude,
+		nullString(order.DeliveryState), nullStringPtr(order.RestaurantGSTIN),
+		nullStringPtr(order.SpecialInstructions), order.ScheduledFor, order.ReleaseAt, order.EstimatedDeliveryAt, rawMessageOrNil(order.Metadata), now, now,
+		nullableInt64(order.PromotionID), nullStringPtr(order.PromoCode), nullableInt64(order.DeliveryFeeQuoteID),
+	).Scan(&orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertOrderItems(tx, orderID, items, now); err != nil {
+		return 0, err
+	}
+	return orderID, nil
+}
+
+// insertOrderItems writes lines of an order and fills in their ids
+func insertOrderItems(tx *sql.Tx, orderID int64, items []models.OrderItem, now time.Time) error {
+	itemInsert := `
+		INSERT INTO order_items (
+			order_id, menu_item_id, name, quantity, unit_price, total_price, options, special_instructions,
+			participant_id, participant_name, created_at,
+			tax_label, tax_rate, taxable_amount, cgst_amount, sgst_amount, igst_amount, tax_amount
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
+		RETURNING id
+	`
+	for i := range items {
+		it := &items[i]
+		var menuItemID interface{}
+		if it.MenuItemID != nil {
+			menuItemID = *it.MenuItemID
+		}
+		var special interface{}
+		if it.SpecialInstructions != nil {
+			special = *it.SpecialInstructions
+		}
+		var options interface{}
+		if len(it.Options) > 0 {
+			options = it.Options
+		}
+		args := []interface{}{
+			orderID, menuItemID, it.Name, it.Quantity, it.UnitPrice, it.TotalPrice, options, special,
+			nullableInt64(it.ParticipantID), nullStringPtr(it.ParticipantName), now,
+		}
+		var insertedID int64
+		if err := tx.QueryRow(itemInsert, append(args, lineTaxArgs(it.Tax)...)...).Scan(&insertedID); err != nil {
+			return err
+		}
+		it.ID = insertedID
+		it.OrderID = orderID
+		it.CreatedAt = &now
+	}
+	return nil
+}
+
+// lineTaxArgs are the tax_label .. tax_amount column values of a line, all NULL without a breakdown
+func lineTaxArgs(t *models.LineTax) []interface{} {
+	if t == nil {
+		return []interface{}{nil, nil, nil, nil, nil, nil, nil}
+	}
+	return []interface{}{t.Label, t.RatePercent, t.TaxableAmount, t.CGSTAmount, t.SGSTAmount, t.IGSTAmount, t.TotalAmount}
+}
+
+/*
+NextOrderSequence hands out the next order sequence for a restaurant's business day.
+The upsert takes a row lock on (restaurant, day), so concurrent transactions queue up
+and never see the same value; the lock is released when tx commits or rolls back.
+*/
+func (r *orderRepo) NextOrderSequence(tx *sql.Tx, restaurantID int64, businessDate time.Time) (int, error) {
+	if tx == nil {
+		return 0, errors.New("transaction required")
+	}
+	var seq int
+	err := tx.QueryRow(`
+		INSERT INTO order_number_sequences (restaurant_id, business_date, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (restaurant_id, business_date) DO UPDATE
+			SET last_value = order_number_sequences.last_value + 1
+		RETURNING last_value
+	`, restaurantID, businessDate.Format("2006-01-02")).Scan(&seq)
+	return seq, err
+}
+
+func (r *orderRepo) GetOrderStatus(orderID int64) (string, error) {
+	var status sql.NullString
+	err := r.db.QueryRow(`SELECT order_status FROM orders WHERE id=$1`, orderID).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	if status.Valid {
+		return status.String, nil
+	}
+	return "", nil
+}
+
+/*
+UpdateOrderStatus moves the order from fromStatus to toStatus inside tx.
+The fromStatus guard makes concurrent transitions safe: if someone else changed
+the status first no row matches and sql.ErrNoRows is returned.
+*/
+func (r *orderRepo) UpdateOrderStatus(tx *sql.Tx, orderID int64, fromStatus, toStatus string) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	res, err := tx.Exec(`UPDATE orders SET order_status=$1, updated_at=$2 WHERE id=$3 AND order_status=$4`,
+		toStatus, time.Now().UTC(), orderID, fromStatus)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const orderColumns = `id, order_number, user_id, restaurant_id, dining_session_id, order_type,
+	       order_status, payment_status, subtotal_amount, tax_amount, delivery_fee, tip_amount, discount_amount, total_amount,
+	       delivery_address_id, delivery_address, delivery_latitude, delivery_longitude, delivery_state, restaurant_gstin, special_instructions,
+	       scheduled_for, release_at, estimated_delivery_time, actual_delivery_time, rider_id, rider_latitude, rider_longitude, rider_location_at,
+	       cancel_reason_code, cancel_note, cancelled_by, cancelled_at, refund_requested_at, metadata, created_at, updated_at,
+	       promotion_id, promo_code, delivery_fee_quote_id`
+
+func scanOrder(sc rowScanner) (*models.Order, error) {
+	var o models.Order
+	var dining sql.NullInt64
+	var deliveryAddrID sql.NullInt64
+	var deliveryAddr sql.NullString
+	var deliveryLat, deliveryLon sql.NullFloat64
+	var deliveryState, gstin sql.NullString
+	var special sql.NullString
+	var scheduledFor, releaseAt sql.NullTime
+	var estimatedAt, deliveredAt sql.NullTime
+	var riderID sql.NullInt64
+	var riderLat, riderLon sql.NullFloat64
+	var riderLocAt sql.NullTime
+	var cancelCode, cancelNote, cancelledBy sql.NullString
+	var cancelledAt, refundRequestedAt sql.NullTime
+	var metadata sql.NullString
+	var createdAt, updatedAt time.Time
+	var orderNumber sql.NullString
+	var promotionID sql.NullInt64
+	var promoCode sql.NullString
+	var feeQuoteID sql.NullInt64
+
+	err := sc.Scan(
+		&o.ID, &orderNumber, &o.UserID, &o.RestaurantID, &dining, &o.OrderType,
+		&o.OrderStatus, &o.PaymentStatus, &o.SubtotalAmount, &o.TaxAmount, &o.DeliveryFee, &o.TipAmount, &o.DiscountAmount, &o.TotalAmount,
+		&deliveryAddrID, &deliveryAddr, &deliveryLat, &deliveryLon, &deliveryState, &gstin, &special,
+		&scheduledFor, &releaseAt, &estimatedAt, &deliveredAt, &riderID, &riderLat, &riderLon, &riderLocAt,
+		&cancelCode, &cancelNote, &cancelledBy, &cancelledAt, &refundRequestedAt, &metadata, &createdAt, &updatedAt,
+		&promotionID, &promoCode, &feeQuoteID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if orderNumber.Valid {
+		o.OrderNumber = orderNumber.String
+	}
+	if dining.Valid {
+		v := dining.Int64
+		o.DiningSessionID = &v
+	}
+	if deliveryAddrID.Valid {
+		v := deliveryAddrID.Int64
+		o.DeliveryAddressID = &v
+	}
+	if deliveryAddr.Valid {
+		o.DeliveryAddress = deliveryAddr.String
+	}
+	if deliveryLat.Valid {
+		v := deliveryLat.Float64
+		o.DeliveryLatitude = &v
+	}
+	if deliveryLon.Valid {
+		v := deliveryLon.Float64
+		o.DeliveryLongitude = &v
+	}
+	if deliveryState.Valid {
+		o.DeliveryState = deliveryState.String
+	}
+	if gstin.Valid {
+		v := gstin.String
+		o.RestaurantGSTIN = &v
+	}
+	if promotionID.Valid {
+		v := promotionID.Int64
+		o.PromotionID = &v
+	}
+	if promoCode.Valid {
+		v := promoCode.String
+		o.PromoCode = &v
+	}
+	if feeQuoteID.Valid {
+		v := feeQuoteID.Int64
+		o.DeliveryFeeQuoteID = &v
+	}
+	if special.Valid {
+		str := special.String
+		o.SpecialInstructions = &str
+	}
+	if scheduledFor.Valid {
+		v := scheduledFor.Time
+		o.ScheduledFor = &v
+	}
+	if releaseAt.Valid {
+		v := releaseAt.Time
+		o.ReleaseAt = &v
+	}
+	if estimatedAt.Valid {
+		v := estimatedAt.Time
+		o.EstimatedDeliveryAt = &v
+	}
+	if deliveredAt.Valid {
+		v := deliveredAt.Time
+		o.DeliveredAt = &v
+	}
+	if riderID.Valid {
+		v := riderID.Int64
+		o.RiderID = &v
+	}
+	if riderLat.Valid {
+		v := riderLat.Float64
+		o.RiderLatitude = &v
+	}
+	if riderLon.Valid {
+		v := riderLon.Float64
+		o.RiderLongitude = &v
+	}
+	if riderLocAt.Valid {
+		v := riderLocAt.Time
+		o.RiderLocationAt = &v
+	}
+	if cancelCode.Valid {
+		v := cancelCode.String
+		o.CancelReasonCode = &v
+	}
+	if cancelNote.Valid {
+		v := cancelNote.String
+		o.CancelNote = &v
+	}
+	if cancelledBy.Valid {
+		v := cancelledBy.String
+		o.CancelledBy = &v
+	}
+	if cancelledAt.Valid {
+		v := cancelledAt.Time
+		o.CancelledAt = &v
+	}
+	if refundRequestedAt.Valid {
+		v := refundRequestedAt.Time
+		o.RefundRequestedAt = &v
+	}
+	if metadata.Valid {
+		o.Metadata = []byte(metadata.String)
+	}
+	o.CreatedAt = &createdAt
+	o.UpdatedAt = &updatedAt
+	return &o, nil
+}
+
+func (r *orderRepo) GetOrderByID(orderID int64) (*models.Order, error) {
+	o, err := scanOrder(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id=$1`, orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+func (r *orderRepo) LockOrder(tx *sql.Tx, orderID int64) (*models.Order, error) {
+	if tx == nil {
+		return nil, errors.New("transaction required")
+	}
+	o, err := scanOrder(tx.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id=$1 FOR UPDATE`, orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// FindOrdersByNumber matches order numbers case-insensitively; numbers from before the year was added may match several rows
+func (r *orderRepo) FindOrdersByNumber(number string) ([]models.Order, error) {
+	rows, err := r.db.Query(`SELECT `+orderColumns+` FROM orders WHERE UPPER(order_number) = UPPER($1) ORDER BY created_at DESC`, number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+// ListOrdersParams filters an order listing; nil / empty fields are ignored
+type ListOrdersParams struct {
+	UserID        *int64
+	RestaurantID  *int64
+	Number        string
+	Status        string
+	OrderType     string
+	PaymentStatus string
+	From          *time.Time // created_at >= From
+	To            *time.Time // created_at < To
+	// keyset cursor: rows strictly after (AfterCreatedAt, AfterID) in created_at DESC, id DESC order
+	AfterCreatedAt *time.Time
+	AfterID        int64
+	Limit          int
+}
+
+/*
+ListOrders returns one page, newest first. Paging is keyset based on (created_at, id)
+so pages stay stable while new orders arrive and deep pages cost the same as the first.
+*/
+func (r *orderRepo) ListOrders(params ListOrdersParams) ([]models.Order, error) {
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+
+	where := []string{"1=1"}
+	args := []interface{}{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if params.UserID != nil {
+		add("user_id = $%d", *params.UserID)
+	}
+	if params.RestaurantID != nil {
+		add("restaurant_id = $%d", *params.RestaurantID)
+	}
+	if params.Number != "" {
+		add("UPPER(order_number) = UPPER($%d)", params.Number)
+	}
+	if params.Status != "" {
+		add("order_status = $%d", params.Status)
+	}
+	if params.OrderType != "" {
+		add("order_type = $%d", params.OrderType)
+	}
+	if params.PaymentStatus != "" {
+		add("payment_status = $%d", params.PaymentStatus)
+	}
+	if params.From != nil {
+		add("created_at >= $%d", *params.From)
+	}
+	if params.To != nil {
+		add("created_at < $%d", *params.To)
+	}
+	if params.AfterCreatedAt != nil {
+		args = append(args, *params.AfterCreatedAt, params.AfterID)
+		where = append(where, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, params.Limit)
+
+	query := fmt.Sprintf(`SELECT %s FROM orders WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		orderColumns, strings.Join(where, " AND "), len(args))
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+// GetItemsForOrders loads the lines of several orders in one query, keyed by order id
+func (r *orderRepo) GetItemsForOrders(orderIDs []int64) (map[int64][]models.OrderItem, error) {
+	out := make(map[int64][]models.OrderItem, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(`SELECT `+orderItemColumns+` FROM order_items WHERE order_id = ANY($1) ORDER BY order_id, id`, pq.Array(orderIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return out, scanOrderItems(rows, out)
+}
+
+func (r *orderRepo) GetOrderItems(tx *sql.Tx, orderID int64) ([]models.OrderItem, error) {
+	if tx == nil {
+		return nil, errors.New("transaction required")
+	}
+	rows, err := tx.Query(`SELECT `+orderItemColumns+` FROM order_items WHERE order_id = $1 ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int64][]models.OrderItem{}
+	if err := scanOrderItems(rows, out); err != nil {
+		return nil, err
+	}
+	return out[orderID], nil
+}
+
+const orderItemColumns = `id, order_id, menu_item_id, name, quantity, unit_price, total_price, options, special_instructions,
+	participant_id, participant_name, created_at,
+	tax_label, tax_rate, taxable_amount, cgst_amount, sgst_amount, igst_amount, tax_amount`
+
+// scanOrderItems appends every row to its order's lines in out
+func scanOrderItems(rows *sql.Rows, out map[int64][]models.OrderItem) error {
+	for rows.Next() {
+		var it models.OrderItem
+		var menuItemID sql.NullInt64
+		var options, special sql.NullString
+		var participantID sql.NullInt64
+		var participantName sql.NullString
+		var createdAt time.Time
+		var taxLabel sql.NullString
+		var taxRate, taxable, cgst, sgst, igst, taxTotal sql.NullFloat64
+		if err := rows.Scan(&it.ID, &it.OrderID, &menuItemID, &it.Name, &it.Quantity, &it.UnitPrice, &it.TotalPrice, &options, &special,
+			&participantID, &participantName, &createdAt,
+			&taxLabel, &taxRate, &taxable, &cgst, &sgst, &igst, &taxTotal); err != nil {
+			return err
+		}
+		if taxRate.Valid {
+			it.Tax = &models.LineTax{
+				Label:         taxLabel.String,
+				RatePercent:   taxRate.Float64,
+				TaxableAmount: taxable.Float64,
+				CGSTAmount:    cgst.Float64,
+				SGSTAmount:    sgst.Float64,
+				IGSTAmount:    igst.Float64,
+				TotalAmount:   taxTotal.Float64,
+			}
+		}
+		if participantID.Valid {
+			v := participantID.Int64
+			it.ParticipantID = &v
+		}
+		if participantName.Valid {
+			v := participantName.String
+			it.ParticipantName = &v
+		}
+		if menuItemID.Valid {
+			v := menuItemID.Int64
+			it.MenuItemID = &v
+		}
+		if options.Valid {
+			it.Options = []byte(options.String)
+		}
+		if special.Valid {
+			str := special.String
+			it.SpecialInstructions = &str
+		}
+		it.CreatedAt = &createdAt
+		out[it.OrderID] = append(out[it.OrderID], it)
+	}
+	return rows.Err()
+}
+
+/* ---------- rider ---------- */
+
+// ErrRiderTaken is returned when a rider claims an order another rider got first
+var ErrRiderTaken = errors.New("order already has a rider")
+
+/*
+AssignRider sets the rider while the order is still in one of fromStatuses; sql.ErrNoRows
+otherwise. A claim (a rider taking the order themselves) also needs the order to have no
+rider yet, so of two riders claiming at once only one wins.
+*/
+func (r *orderRepo) AssignRider(tx *sql.Tx, orderID, riderID int64, fromStatuses []string, claim bool) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	query := `
+		UPDATE orders SET rider_id=$1, rider_latitude=NULL, rider_longitude=NULL, rider_location_at=NULL, updated_at=$2
+		WHERE id=$3 AND order_status = ANY($4)`
+	if claim {
+		query += ` AND rider_id IS NULL`
+	}
+	res, err := tx.Exec(query, riderID, time.Now().UTC(), orderID, pq.Array(fromStatuses))
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		return nil
+	}
+	if claim {
+		var taken bool
+		err := tx.QueryRow(`SELECT rider_id IS NOT NULL FROM orders WHERE id=$1 AND order_status = ANY($2)`, orderID, pq.Array(fromStatuses)).Scan(&taken)
+		if err == nil && taken {
+			return ErrRiderTaken
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+	return sql.ErrNoRows
+}
+
+/*
+UpdateRiderLocation stores the assigned rider's latest position. Updates closer together
+than minInterval are ignored (false, nil) so chatty devices do not flood the event log.
+*/
+func (r *orderRepo) UpdateRiderLocation(tx *sql.Tx, orderID, riderID int64, lat, lon float64, minInterval time.Duration) (bool, error) {
+	if tx == nil {
+		return false, errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	res, err := tx.Exec(`
+		UPDATE orders SET rider_latitude=$1, rider_longitude=$2, rider_location_at=$3
+		WHERE id=$4 AND rider_id=$5 AND (rider_location_at IS NULL OR rider_location_at <= $6)
+	`, lat, lon, now, orderID, riderID, now.Add(-minInterval))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+/* ---------- scheduling ---------- */
+
+// ListDueScheduledOrders returns SCHEDULED orders whose release time has come, earliest first
+func (r *orderRepo) ListDueScheduledOrders(now time.Time, limit int) ([]models.Order, error) {
+	rows, err := r.db.Query(`
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE order_status = 'SCHEDULED' AND release_at <= $1
+		ORDER BY release_at ASC
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+/* ---------- cancellation ---------- */
+
+// SetCancellation records why and by whom an order was cancelled; paymentStatus "" leaves it unchanged
+func (r *orderRepo) SetCancellation(tx *sql.Tx, orderID int64, reasonCode string, note *string, actor string, paymentStatus string) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	_, err := tx.Exec(`
+		UPDATE orders SET cancel_reason_code=$1, cancel_note=$2, cancelled_by=$3, cancelled_at=$4,
+		       payment_status=COALESCE(NULLIF($5,''), payment_status), updated_at=$4
+		WHERE id=$6
+	`, reasonCode, note, actor, now, paymentStatus, orderID)
+	return err
+}
+
+// ListPendingRefunds returns cancelled orders whose refund payment-service has not yet accepted, oldest first
+func (r *orderRepo) ListPendingRefunds(limit int) ([]models.Order, error) {
+	rows, err := r.db.Query(`
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE payment_status = 'REFUND_PENDING' AND refund_requested_at IS NULL
+		ORDER BY cancelled_at ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+func (r *orderRepo) MarkRefundRequested(orderID int64) error {
+	_, err := r.db.Exec(`UPDATE orders SET refund_requested_at=$1 WHERE id=$2 AND refund_requested_at IS NULL`, time.Now().UTC(), orderID)
+	return err
+}
+
+/* ---------- status history ---------- */
+
+func (r *orderRepo) InsertStatusHistory(tx *sql.Tx, h *models.OrderStatusHistory) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	err := tx.QueryRow(`
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_user_id, actor_role, reason, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING id
+	`, h.OrderID, nullString(h.FromStatus), h.ToStatus, nullableInt64(h.ActorUserID), nullString(h.ActorRole), nullStringPtr(h.Reason), now).Scan(&h.ID)
+	if err != nil {
+		return err
+	}
+	h.CreatedAt = &now
+	return nil
+}
+
+func (r *orderRepo) GetStatusHistory(orderID int64) ([]models.OrderStatusHistory, error) {
+	rows, err := r.db.Query(`
+		SELECT id, order_id, from_status, to_status, actor_user_id, actor_role, reason, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.OrderStatusHistory
+	for rows.Next() {
+		var h models.OrderStatusHistory
+		var fromStatus, actorRole, reason sql.NullString
+		var actorUserID sql.NullInt64
+		var createdAt time.Time
+		if err := rows.Scan(&h.ID, &h.OrderID, &fromStatus, &h.ToStatus, &actorUserID, &actorRole, &reason, &createdAt); err != nil {
+			return nil, err
+		}
+		if fromStatus.Valid {
+			h.FromStatus = fromStatus.String
+		}
+		if actorUserID.Valid {
+			v := actorUserID.Int64
+			h.ActorUserID = &v
+		}
+		if actorRole.Valid {
+			h.ActorRole = actorRole.String
+		}
+		if reason.Valid {
+			str := reason.String
+			h.Reason = &str
+		}
+		h.CreatedAt = &createdAt
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+/* helpers to convert nil/empty values to SQL-friendly values */
+func nullStringPtr(p *string) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+func rawMessageOrNil(m json.RawMessage) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
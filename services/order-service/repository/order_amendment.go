@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/lib/pq"
+)
+
+func (r *orderRepo) UpdateAmounts(tx *sql.Tx, order *models.Order, fromStatus string) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	res, err := tx.Exec(`
+		UPDATE orders SET subtotal_amount=$1, tax_amount=$2, delivery_fee=$3, tip_amount=$4, discount_amount=$5,
+		       total_amount=$6, updated_at=$7
+		WHERE id=$8 AND order_status=$9
+	`, order.SubtotalAmount, order.TaxAmount, order.DeliveryFee, order.TipAmount, order.DiscountAmount,
+		order.TotalAmount, time.Now().UTC(), order.ID, fromStatus)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *orderRepo) ReplaceItems(tx *sql.Tx, orderID int64, kept []models.OrderItem, removed []int64, added []models.OrderItem) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if len(removed) > 0 {
+		if _, err := tx.Exec(`DELETE FROM order_items WHERE order_id=$1 AND id = ANY($2)`, orderID, pq.Array(removed)); err != nil {
+			return err
+		}
+	}
+	for _, it := range kept {
+		var options interface{}
+		if len(it.Options) > 0 {
+			options = it.Options
+		}
+		args := append([]interface{}{
+			it.Name, it.Quantity, it.UnitPrice, it.TotalPrice, options, nullStringPtr(it.SpecialInstructions), it.ID, orderID,
+		}, lineTaxArgs(it.Tax)...)
+		_, err := tx.Exec(`
+			UPDATE order_items SET name=$1, quantity=$2, unit_price=$3, total_price=$4, options=$5, special_instructions=$6,
+			       tax_label=$9, tax_rate=$10, taxable_amount=$11, cgst_amount=$12, sgst_amount=$13, igst_amount=$14, tax_amount=$15
+			WHERE id=$7 AND order_id=$8
+		`, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return insertOrderItems(tx, orderID, added, time.Now().UTC())
+}
+
+func (r *orderRepo) InsertAmendment(tx *sql.Tx, a *models.OrderAmendment) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	before, err := json.Marshal(a.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(a.After)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	err = tx.QueryRow(`
+		INSERT INTO order_amendments (order_id, actor_user_id, actor_role, before_snapshot, after_snapshot,
+			price_difference, payment_action, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id
+	`, a.OrderID, nullableInt64(a.ActorUserID), a.ActorRole, string(before), string(after),
+		a.PriceDifference, a.PaymentAction, now).Scan(&a.ID)
+	if err != nil {
+		return err
+	}
+	a.CreatedAt = &now
+	return nil
+}
+
+const amendmentColumns = `id, order_id, actor_user_id, actor_role, before_snapshot, after_snapshot,
+	price_difference, payment_action, payment_requested_at, charge_outcome, created_at`
+
+func (r *orderRepo) ListAmendments(orderID int64) ([]models.OrderAmendment, error) {
+	return r.queryAmendments(`SELECT `+amendmentColumns+` FROM order_amendments WHERE order_id=$1 ORDER BY id`, orderID)
+}
+
+func (r *orderRepo) ListPendingAmendmentPayments(limit int) ([]models.OrderAmendment, error) {
+	return r.queryAmendments(`
+		SELECT `+amendmentColumns+` FROM order_amendments
+		WHERE payment_action <> $1 AND payment_requested_at IS NULL AND charge_outcome IS NULL
+		ORDER BY created_at
+		LIMIT $2
+	`, models.AmendPaymentNone, limit)
+}
+
+func (r *orderRepo) MarkAmendmentPaymentRequested(amendmentID int64) error {
+	_, err := r.db.Exec(`
+		UPDATE order_amendments SET payment_requested_at=$1 WHERE id=$2 AND payment_requested_at IS NULL
+	`, time.Now().UTC(), amendmentID)
+	return err
+}
+
+func (r *orderRepo) SetChargeOutcome(amendmentID int64, outcome string) error {
+	_, err := r.db.Exec(`
+		UPDATE order_amendments SET charge_outcome=$1 WHERE id=$2 AND charge_outcome IS NULL
+	`, outcome, amendmentID)
+	return err
+}
+
+func (r *orderRepo) queryAmendments(query string, args ...interface{}) ([]models.OrderAmendment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.OrderAmendment{}
+	for rows.Next() {
+		var a models.OrderAmendment
+		var actorUserID sql.NullInt64
+		var before, after []byte
+		var requestedAt sql.NullTime
+		var chargeOutcome sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&a.ID, &a.OrderID, &actorUserID, &a.ActorRole, &before, &after,
+			&a.PriceDifference, &a.PaymentAction, &requestedAt, &chargeOutcome, &createdAt); err != nil {
+			return nil, err
+		}
+		if actorUserID.Valid {
+			v := actorUserID.Int64
+			a.ActorUserID = &v
+		}
+		if err := json.Unmarshal(before, &a.Before); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(after, &a.After); err != nil {
+			return nil, err
+		}
+		if requestedAt.Valid {
+			v := requestedAt.Time
+			a.PaymentRequestedAt = &v
+		}
+		a.ChargeOutcome = chargeOutcome.String
+		a.CreatedAt = &createdAt
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
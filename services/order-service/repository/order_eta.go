@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+func (r *orderRepo) CountKitchenQueue(restaurantID, excludeOrderID int64) (int, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM orders
+		WHERE restaurant_id=$1 AND id <> $2 AND order_status IN ($3, $4, $5)
+	`, restaurantID, excludeOrderID, models.OrderStatusPlaced, models.OrderStatusConfirmed, models.OrderStatusPreparing).Scan(&n)
+	return n, err
+}
+
+func (r *orderRepo) SetEstimatedDelivery(tx *sql.Tx, orderID int64, at time.Time) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	_, err := tx.Exec(`UPDATE orders SET estimated_delivery_time=$1 WHERE id=$2`, at, orderID)
+	return err
+}
+
+func (r *orderRepo) SetDelivered(tx *sql.Tx, orderID int64, at time.Time) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	_, err := tx.Exec(`UPDATE orders SET actual_delivery_time=$1 WHERE id=$2`, at, orderID)
+	return err
+}
+
+func (r *orderRepo) InsertETAPrediction(tx *sql.Tx, p *models.OrderETA) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	var distance interface{}
+	if p.DistanceKm != nil {
+		distance = *p.DistanceKm
+	}
+	return tx.QueryRow(`
+		INSERT INTO order_eta_predictions (
+			order_id, order_status, estimated_delivery_time, prep_minutes, queue_depth, queue_minutes,
+			travel_minutes, distance_km, created_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		RETURNING id
+	`, p.OrderID, p.OrderStatus, p.EstimatedDeliveryTime, p.PrepMinutes, p.QueueDepth, p.QueueMinutes,
+		p.TravelMinutes, distance, p.CreatedAt).Scan(&p.ID)
+}
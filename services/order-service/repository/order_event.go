@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// OrderEventsChannel is the Postgres NOTIFY channel carrying new event ids
+const OrderEventsChannel = "order_events"
+
+type OrderEventRepo interface {
+	// Insert must run inside the tx that changes the order so the event exists iff the change commits
+	Insert(tx *sql.Tx, e *models.OrderEvent) error
+	GetByID(id int64) (*models.OrderEvent, error)
+	// LatestSeq and the List*Since methods go by commit order (OrderEvent.Seq), not by id
+	LatestSeq() (int64, error)
+	ListSince(afterSeq int64, limit int) ([]models.OrderEvent, error)
+	ListForRestaurantSince(restaurantID, afterSeq int64, limit int) ([]models.OrderEvent, error)
+	ListForOrderSince(orderID, afterSeq int64, limit int) ([]models.OrderEvent, error)
+}
+
+type orderEventRepo struct {
+	db *sql.DB
+}
+
+func NewOrderEventRepo(db *sql.DB) OrderEventRepo {
+	return &orderEventRepo{db: db}
+}
+
+func (r *orderEventRepo) Insert(tx *sql.Tx, e *models.OrderEvent) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	err := tx.QueryRow(`
+		INSERT INTO order_events (restaurant_id, order_id, user_id, event_type, payload, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id
+	`, e.RestaurantID, e.OrderID, e.UserID, e.Type, rawMessageOrNil(e.Payload), now).Scan(&e.ID)
+	if err != nil {
+		return err
+	}
+	e.CreatedAt = &now
+	// delivered to listeners only when tx commits, which is also when Seq is drawn (025_order_event_commit_order.sql)
+	_, err = tx.Exec(`SELECT pg_notify($1, $2)`, OrderEventsChannel, strconv.FormatInt(e.ID, 10))
+	return err
+}
+
+// commit_seq is only NULL inside the inserting transaction
+const orderEventColumns = `id, COALESCE(commit_seq, 0), restaurant_id, order_id, user_id, event_type, payload, created_at`
+
+func scanOrderEvent(sc rowScanner) (*models.OrderEvent, error) {
+	var e models.OrderEvent
+	var payload sql.NullString
+	var createdAt time.Time
+	if err := sc.Scan(&e.ID, &e.Seq, &e.RestaurantID, &e.OrderID, &e.UserID, &e.Type, &payload, &createdAt); err != nil {
+		return nil, err
+	}
+	if payload.Valid {
+		e.Payload = json.RawMessage(payload.String)
+	}
+	e.CreatedAt = &createdAt
+	return &e, nil
+}
+
+func (r *orderEventRepo) GetByID(id int64) (*models.OrderEvent, error) {
+	e, err := scanOrderEvent(r.db.QueryRow(`SELECT `+orderEventColumns+` FROM order_events WHERE id=$1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *orderEventRepo) LatestSeq() (int64, error) {
+	var seq int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(commit_seq), 0) FROM order_events`).Scan(&seq)
+	return seq, err
+}
+
+func (r *orderEventRepo) ListSince(afterSeq int64, limit int) ([]models.OrderEvent, error) {
+	return r.list(`SELECT `+orderEventColumns+` FROM order_events WHERE commit_seq > $1 ORDER BY commit_seq LIMIT $2`, afterSeq, limit)
+}
+
+func (r *orderEventRepo) ListForRestaurantSince(restaurantID, afterSeq int64, limit int) ([]models.OrderEvent, error) {
+	return r.list(`SELECT `+orderEventColumns+` FROM order_events WHERE restaurant_id = $1 AND commit_seq > $2 ORDER BY commit_seq LIMIT $3`, restaurantID, afterSeq, limit)
+}
+
+func (r *orderEventRepo) ListForOrderSince(orderID, afterSeq int64, limit int) ([]models.OrderEvent, error) {
+	return r.list(`SELECT `+orderEventColumns+` FROM order_events WHERE order_id = $1 AND commit_seq > $2 ORDER BY commit_seq LIMIT $3`, orderID, afterSeq, limit)
+}
+
+func (r *orderEventRepo) list(query string, args ...interface{}) ([]models.OrderEvent, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.OrderEvent
+	for rows.Next() {
+		e, err := scanOrderEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
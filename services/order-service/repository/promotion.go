@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/lib/pq"
+)
+
+type PromotionRepo interface {
+	Create(p *models.Promotion) error
+	// GetByID returns nil when there is no such promotion
+	GetByID(id int64) (*models.Promotion, error)
+	// GetActiveByCode finds the live promotion with that code, ignoring case; nil when there is none
+	GetActiveByCode(code string) (*models.Promotion, error)
+	// ListAutomatic returns the live offers without a code that may apply at the restaurant
+	ListAutomatic(restaurantID int64) ([]models.Promotion, error)
+	// List returns the restaurant's promotions, or the platform's when restaurantID is nil, newest first
+	List(restaurantID *int64) ([]models.Promotion, error)
+	// Deactivate ends a promotion; sql.ErrNoRows when restaurantID (nil: platform) has no such live promotion
+	Deactivate(restaurantID *int64, id int64) error
+
+	// usage; redemptions of cancelled orders do not count
+	// LockPromotion serialises redemptions of one promotion until tx ends and reports whether it is still live
+	LockPromotion(tx *sql.Tx, id int64) (bool, error)
+	CountRedemptions(tx *sql.Tx, promotionID, userID int64) (total int, byUser int, err error)
+	// LockCustomer serialises first-order checks of one customer until tx ends
+	LockCustomer(tx *sql.Tx, userID int64) error
+	// CountCustomerOrders is how many orders the customer has placed, cancelled ones and excludeOrderID aside
+	CountCustomerOrders(tx *sql.Tx, userID, excludeOrderID int64) (int, error)
+	InsertRedemption(tx *sql.Tx, r *models.PromotionRedemption) error
+	// UpdateRedemption rewrites the discount of an amended order; 0 removes the redemption
+	UpdateRedemption(tx *sql.Tx, orderID int64, amount float64) error
+}
+
+type promotionRepo struct {
+	db *sql.DB
+}
+
+func NewPromotionRepo(db *sql.DB) PromotionRepo {
+	return &promotionRepo{db: db}
+}
+
+const promotionColumns = `id, restaurant_id, code, name, description, discount_type, discount_value, max_discount,
+	       min_subtotal, first_order_only, restaurant_ids, tags, starts_at, ends_at, daily_start, daily_end,
+	       usage_limit, per_user_limit, is_active, created_at, updated_at,
+	       (SELECT COUNT(*) FROM promotion_redemptions pr JOIN orders o ON o.id = pr.order_id
+	        WHERE pr.promotion_id = promotions.id AND o.order_status <> 'CANCELLED')`
+
+func scanPromotion(sc rowScanner) (*models.Promotion, error) {
+	var p models.Promotion
+	var restID sql.NullInt64
+	var code, description, dailyStart, dailyEnd sql.NullString
+	var maxDiscount sql.NullFloat64
+	var restaurantIDs pq.Int64Array
+	var tags pq.StringArray
+	var startsAt, endsAt sql.NullTime
+	var usageLimit, perUserLimit sql.NullInt64
+	var createdAt, updatedAt time.Time
+	err := sc.Scan(&p.ID, &restID, &code, &p.Name, &description, &p.DiscountType, &p.DiscountValue, &maxDiscount,
+		&p.MinSubtotal, &p.FirstOrderOnly, &restaurantIDs, &tags, &startsAt, &endsAt, &dailyStart, &dailyEnd,
+		&usageLimit, &perUserLimit, &p.IsActive, &createdAt, &updatedAt, &p.Redemptions)
+	if err != nil {
+		return nil, err
+	}
+	if restID.Valid {
+		v := restID.Int64
+		p.RestaurantID = &v
+	}
+	if code.Valid {
+		v := code.String
+		p.Code = &v
+	}
+	p.Description = description.String
+	if maxDiscount.Valid {
+		v := maxDiscount.Float64
+		p.MaxDiscount = &v
+	}
+	p.RestaurantIDs = restaurantIDs
+	p.Tags = tags
+	if startsAt.Valid {
+		v := startsAt.Time
+		p.StartsAt = &v
+	}
+	if endsAt.Valid {
+		v := endsAt.Time
+		p.EndsAt = &v
+	}
+	p.DailyStart = dailyStart.String
+	p.DailyEnd = dailyEnd.String
+	if usageLimit.Valid {
+		v := int(usageLimit.Int64)
+		p.UsageLimit = &v
+	}
+	if perUserLimit.Valid {
+		v := int(perUserLimit.Int64)
+		p.PerUserLimit = &v
+	}
+	p.CreatedAt = &createdAt
+	p.UpdatedAt = &updatedAt
+	return &p, nil
+}
+
+func (r *promotionRepo) Create(p *models.Promotion) error {
+	now := time.Now().UTC()
+	p.CreatedAt = &now
+	p.UpdatedAt = &now
+	var restaurantIDs, tags interface{}
+	if len(p.RestaurantIDs) > 0 {
+		restaurantIDs = pq.Array(p.RestaurantIDs)
+	}
+	if len(p.Tags) > 0 {
+		tags = pq.Array(p.Tags)
+	}
+	var usageLimit, perUserLimit interface{}
+	if p.UsageLimit != nil {
+		usageLimit = *p.UsageLimit
+	}
+	if p.PerUserLimit != nil {
+		perUserLimit = *p.PerUserLimit
+	}
+	return r.db.QueryRow(`
+		INSERT INTO promotions (
+			restaurant_id, code, name, description, discount_type, discount_value, max_discount,
+			min_subtotal, first_order_only, restaurant_ids, tags, starts_at, ends_at, daily_start, daily_end,
+			usage_limit, per_user_limit, is_active, created_at, updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$19)
+		RETURNING id
+	`, nullableInt64(p.RestaurantID), nullStringPtr(p.Code), p.Name, nullString(p.Description), p.DiscountType, p.DiscountValue, p.MaxDiscount,
+		p.MinSubtotal, p.FirstOrderOnly, restaurantIDs, tags, p.StartsAt, p.EndsAt, nullString(p.DailyStart), nullString(p.DailyEnd),
+		usageLimit, perUserLimit, p.IsActive, now).Scan(&p.ID)
+}
+
+func (r *promotionRepo) GetByID(id int64) (*models.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow(`SELECT `+promotionColumns+` FROM promotions WHERE id=$1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *promotionRepo) GetActiveByCode(code string) (*models.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow(`
+		SELECT `+promotionColumns+` FROM promotions
+		WHERE code IS NOT NULL AND UPPER(code) = UPPER($1) AND is_active
+	`, code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *promotionRepo) ListAutomatic(restaurantID int64) ([]models.Promotion, error) {
+	return r.query(`
+		SELECT `+promotionColumns+` FROM promotions
+		WHERE code IS NULL AND is_active
+		  AND (restaurant_id = $1 OR (restaurant_id IS NULL AND (restaurant_ids IS NULL OR $1 = ANY(restaurant_ids))))
+		ORDER BY id
+	`, restaurantID)
+}
+
+func (r *promotionRepo) List(restaurantID *int64) ([]models.Promotion, error) {
+	if restaurantID == nil {
+		return r.query(`SELECT ` + promotionColumns + ` FROM promotions WHERE restaurant_id IS NULL ORDER BY id DESC`)
+	}
+	return r.query(`SELECT `+promotionColumns+` FROM promotions WHERE restaurant_id=$1 ORDER BY id DESC`, *restaurantID)
+}
+
+func (r *promotionRepo) query(q string, args ...interface{}) ([]models.Promotion, error) {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func (r *promotionRepo) Deactivate(restaurantID *int64, id int64) error {
+	res, err := r.db.Exec(`
+		UPDATE promotions SET is_active=false, updated_at=$1
+		WHERE id=$2 AND restaurant_id IS NOT DISTINCT FROM $3::BIGINT AND is_active
+	`, time.Now().UTC(), id, nullableInt64(restaurantID))
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *promotionRepo) LockPromotion(tx *sql.Tx, id int64) (bool, error) {
+	if tx == nil {
+		return false, errors.New("transaction required")
+	}
+	var active bool
+	err := tx.QueryRow(`SELECT is_active FROM promotions WHERE id=$1 FOR UPDATE`, id).Scan(&active)
+	return active, err
+}
+
+func (r *promotionRepo) CountRedemptions(tx *sql.Tx, promotionID, userID int64) (int, int, error) {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	var total, byUser int
+	err := ex.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE pr.user_id = $2)
+		FROM promotion_redemptions pr JOIN orders o ON o.id = pr.order_id
+		WHERE pr.promotion_id = $1 AND o.order_status <> 'CANCELLED'
+	`, promotionID, userID).Scan(&total, &byUser)
+	return total, byUser, err
+}
+
+func (r *promotionRepo) LockCustomer(tx *sql.Tx, userID int64) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	// there is no customer row here; a hash collision only makes two customers wait for each other
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('first_order_promotion:' || $1::text))`, userID)
+	return err
+}
+
+func (r *promotionRepo) CountCustomerOrders(tx *sql.Tx, userID, excludeOrderID int64) (int, error) {
+	var ex dbtx = r.db
+	if tx != nil {
+		ex = tx
+	}
+	var n int
+	err := ex.QueryRow(`
+		SELECT COUNT(*) FROM orders WHERE user_id=$1 AND id <> $2 AND order_status <> 'CANCELLED'
+	`, userID, excludeOrderID).Scan(&n)
+	return n, err
+}
+
+func (r *promotionRepo) InsertRedemption(tx *sql.Tx, red *models.PromotionRedemption) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	red.CreatedAt = &now
+	return tx.QueryRow(`
+		INSERT INTO promotion_redemptions (promotion_id, order_id, user_id, code, discount_amount, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6) RETURNING id
+	`, red.PromotionID, red.OrderID, red.UserID, nullStringPtr(red.Code), red.DiscountAmount, now).Scan(&red.ID)
+}
+
+func (r *promotionRepo) UpdateRedemption(tx *sql.Tx, orderID int64, amount float64) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if amount <= 0 {
+		if _, err := tx.Exec(`DELETE FROM promotion_redemptions WHERE order_id=$1`, orderID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE orders SET promotion_id=NULL, promo_code=NULL WHERE id=$1`, orderID)
+		return err
+	}
+	_, err := tx.Exec(`UPDATE promotion_redemptions SET discount_amount=$1 WHERE order_id=$2`, amount, orderID)
+	return err
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/lib/pq"
+)
+
+// RestaurantRepo reads the restaurant rows restaurant-service writes; orders never change them
+type RestaurantRepo interface {
+	GetByID(id int64) (*models.Restaurant, error)
+	GetHoursByRestaurant(restaurantID int64) ([]models.RestaurantHour, error)
+	GetTableByQRToken(token string) (*models.RestaurantTable, error)
+}
+
+type restaurantRepo struct {
+	db *sql.DB
+}
+
+func NewRestaurantRepo(db *sql.DB) RestaurantRepo {
+	return &restaurantRepo{db: db}
+}
+
+func (r *restaurantRepo) GetByID(id int64) (*models.Restaurant, error) {
+	query := `
+	SELECT id, owner_auth_user_id, name, slug, description, status,
+		   address_line1, address_line2, city, state, pincode,
+		   latitude, longitude, avg_rating, rating_count, tags, metadata, gstin, created_at, updated_at
+	FROM restaurants WHERE id=$1
+	`
+	var rest models.Restaurant
+	var owner sql.NullInt64
+	var lat, lon sql.NullFloat64
+	var avgRating sql.NullFloat64
+	var ratingCount sql.NullInt64
+	var tags pq.StringArray
+	var metadata sql.NullString
+	var gstin sql.NullString
+	var createdAt, updatedAt time.Time
+
+	err := r.db.QueryRow(query, id).Scan(
+		&rest.ID, &owner, &rest.Name, &rest.Slug, &rest.Description, &rest.Status,
+		&rest.AddressLine1, &rest.AddressLine2, &rest.City, &rest.State, &rest.Pincode,
+		&lat, &lon, &avgRating, &ratingCount, &tags, &metadata, &gstin, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if owner.Valid {
+		v := owner.Int64
+		rest.OwnerAuthUserID = &v
+	}
+	if lat.Valid {
+		v := lat.Float64
+		rest.Latitude = &v
+	}
+	if lon.Valid {
+		v := lon.Float64
+		rest.Longitude = &v
+	}
+	if avgRating.Valid {
+		v := avgRating.Float64
+		rest.AvgRating = &v
+	}
+	if ratingCount.Valid {
+		v := ratingCount.Int64
+		rest.RatingCount = &v
+	}
+	if len(tags) > 0 {
+		rest.Tags = tags
+	}
+	if metadata.Valid {
+		_ = json.Unmarshal([]byte(metadata.String), &rest.Metadata)
+	}
+	if gstin.Valid {
+		rest.GSTIN = gstin.String
+	}
+	rest.CreatedAt = &createdAt
+	rest.UpdatedAt = &updatedAt
+	return &rest, nil
+}
+
+/* ---------- hours ---------- */
+
+func (r *restaurantRepo) GetHoursByRestaurant(restaurantID int64) ([]models.RestaurantHour, error) {
+	rows, err := r.db.Query(`
+	SELECT id, restaurant_id, weekday, open_time, close_time, is_closed, created_at
+	FROM restaurant_hours WHERE restaurant_id=$1 ORDER BY weekday
+	`, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.RestaurantHour
+	for rows.Next() {
+		var h models.RestaurantHour
+		var open, close sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&h.ID, &h.RestaurantID, &h.Weekday, &open, &close, &h.IsClosed, &createdAt); err != nil {
+			return nil, err
+		}
+		if open.Valid {
+			h.OpenTime = open.String
+		}
+		if close.Valid {
+			h.CloseTime = close.String
+		}
+		h.CreatedAt = createdAt.Format(time.RFC3339)
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+/* ---------- tables (QR) ---------- */
+
+func (r *restaurantRepo) GetTableByQRToken(token string) (*models.RestaurantTable, error) {
+	return r.getTable(`qr_token=$1`, token)
+}
+
+func (r *restaurantRepo) getTable(where string, arg interface{}) (*models.RestaurantTable, error) {
+	var t models.RestaurantTable
+	var qrToken, qrUrl sql.NullString
+	var createdAt time.Time
+	err := r.db.QueryRow(`
+	SELECT id, restaurant_id, table_identifier, seats, qr_token, qr_url, is_active, created_at FROM restaurant_tables WHERE `+where,
+		arg).Scan(&t.ID, &t.RestaurantID, &t.TableIdentifier, &t.Seats, &qrToken, &qrUrl, &t.IsActive, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if qrToken.Valid {
+		t.QRToken = qrToken.String
+	}
+	if qrUrl.Valid {
+		t.QRUrl = qrUrl.String
+	}
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+	return &t, nil
+}
+
+/* ---------- helpers ---------- */
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+type TaxRepo interface {
+	// ListRules returns the restaurant's own rules and the platform defaults
+	ListRules(restaurantID int64) ([]models.TaxRule, error)
+	CreateRule(rule *models.TaxRule) error
+	// DeleteRule removes one of the restaurant's rules; sql.ErrNoRows when it has no such rule
+	DeleteRule(restaurantID, ruleID int64) error
+}
+
+type taxRepo struct {
+	db *sql.DB
+}
+
+func NewTaxRepo(db *sql.DB) TaxRepo {
+	return &taxRepo{db: db}
+}
+
+func (r *taxRepo) ListRules(restaurantID int64) ([]models.TaxRule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, restaurant_id, category_id, tag, label, rate_percent, created_at
+		FROM tax_rules WHERE restaurant_id = $1 OR restaurant_id IS NULL
+		ORDER BY restaurant_id NULLS LAST, id
+	`, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.TaxRule{}
+	for rows.Next() {
+		var rule models.TaxRule
+		var restID, categoryID sql.NullInt64
+		var tag sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&rule.ID, &restID, &categoryID, &tag, &rule.Label, &rule.RatePercent, &createdAt); err != nil {
+			return nil, err
+		}
+		if restID.Valid {
+			v := restID.Int64
+			rule.RestaurantID = &v
+		}
+		if categoryID.Valid {
+			v := categoryID.Int64
+			rule.CategoryID = &v
+		}
+		if tag.Valid {
+			v := tag.String
+			rule.Tag = &v
+		}
+		rule.CreatedAt = &createdAt
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+func (r *taxRepo) CreateRule(rule *models.TaxRule) error {
+	now := time.Now().UTC()
+	rule.CreatedAt = &now
+	return r.db.QueryRow(`
+		INSERT INTO tax_rules (restaurant_id, category_id, tag, label, rate_percent, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6) RETURNING id
+	`, nullableInt64(rule.RestaurantID), nullableInt64(rule.CategoryID), nullStringPtr(rule.Tag), rule.Label, rule.RatePercent, now).Scan(&rule.ID)
+}
+
+func (r *taxRepo) DeleteRule(restaurantID, ruleID int64) error {
+	res, err := r.db.Exec(`DELETE FROM tax_rules WHERE id=$1 AND restaurant_id=$2`, ruleID, restaurantID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package routes
+
+import (
+	"database/sql"
+	"os"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/controller"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/realtime"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+func Setup(r *gin.Engine, db *sql.DB) {
+	// repos; restaurants and menus are restaurant-service's, read here only
+	restRepo := repository.NewRestaurantRepo(db)
+	menuRepo := repository.NewMenuRepo(db)
+	orderRepo := repository.NewOrderRepo(db)
+	idemStore := idempotency.NewStore(db)
+	eventRepo := repository.NewOrderEventRepo(db)
+	cartRepo := repository.NewCartRepo(db)
+	groupCartRepo := repository.NewGroupCartRepo(db)
+	diningRepo := repository.NewDiningSessionRepo(db)
+	taxRepo := repository.NewTaxRepo(db)
+	promoRepo := repository.NewPromotionRepo(db)
+	feeRepo := repository.NewDeliveryFeeRepo(db)
+
+	// live order events: every replica listens on Postgres so streams see changes made anywhere
+	hub := realtime.NewHub()
+	go realtime.Listen(os.Getenv("DATABASE_URL"), eventRepo, hub)
+
+	// services
+	payments := clients.NewPaymentClient()
+	users := clients.NewUserClient()
+	orderSvc := services.NewOrderService(orderRepo, restRepo, menuRepo, eventRepo, diningRepo, taxRepo, promoRepo, feeRepo, payments, users, db)
+
+	cartSvc := services.NewCartService(cartRepo, menuRepo, orderSvc)
+	groupCartSvc := services.NewGroupCartService(groupCartRepo, restRepo, menuRepo, orderSvc, db)
+	diningSvc := services.NewDiningService(diningRepo, restRepo, orderRepo, payments, db)
+	taxSvc := services.NewTaxService(taxRepo, restRepo, menuRepo)
+	promoSvc := services.NewPromotionService(promoRepo, restRepo, orderSvc)
+	feeSvc := services.NewDeliveryFeeService(feeRepo, restRepo)
+
+	// refunds and amendment charges payment-service could not take at the time
+	go services.RunRefundRetries(orderSvc, time.Minute)
+	// SCHEDULED orders go to the kitchen when their lead time starts
+	go services.RunScheduledReleases(orderSvc, 30*time.Second)
+	// split dine-in bills settle once payment-service reports every share paid
+	go services.RunSettlementSync(diningSvc, 30*time.Second)
+
+	// controllers
+	orderC := controller.NewOrderController(orderSvc, hub)
+	cartC := controller.NewCartController(cartSvc)
+	groupC := controller.NewGroupCartController(groupCartSvc)
+	diningC := controller.NewDiningController(diningSvc)
+	taxC := controller.NewTaxController(taxSvc)
+	promoC := controller.NewPromotionController(promoSvc)
+	feeC := controller.NewDeliveryFeeController(feeSvc)
+
+	// a restaurant's orders, dine-in tabs, GST rates and offers
+	rest := r.Group("/restaurants/:id")
+	rest.GET("/orders/stream", middleware.StreamAuth(), orderC.StreamForRestaurant)
+	auth := rest.Group("")
+	auth.Use(middleware.AuthRequired())
+	{
+		auth.GET("/orders", orderC.ListForRestaurant)
+
+		// dine-in
+		auth.GET("/dining-sessions", diningC.ListForRestaurant)
+
+		// GST rates
+		auth.GET("/tax-rules", taxC.List)
+		auth.POST("/tax-rules", taxC.Create)
+		auth.DELETE("/tax-rules/:rule_id", taxC.Delete)
+
+		// coupons and automatic offers
+		auth.GET("/promotions", promoC.List)
+		auth.POST("/promotions", promoC.Create)
+		auth.DELETE("/promotions/:promotion_id", promoC.Deactivate)
+	}
+
+	r.POST("/orders", middleware.AuthRequired(), idempotency.Middleware(idemStore), orderC.PlaceOrder)
+	r.GET("/orders/:id/status", orderC.GetStatus)
+	r.GET("/orders/:id/track", middleware.StreamAuth(), orderC.TrackOrder)
+	r.POST("/stream-tickets", middleware.AuthRequired(), orderC.IssueStreamTicket)
+
+	orders := r.Group("/orders")
+	orders.Use(middleware.AuthRequired())
+	{
+		orders.GET("", orderC.List)
+		orders.GET("/:id", orderC.Get)
+		orders.POST("/:id/cancel", idempotency.Middleware(idemStore), orderC.Cancel)
+		orders.POST("/:id/amend", idempotency.Middleware(idemStore), orderC.Amend)
+		orders.GET("/:id/amendments", orderC.ListAmendments)
+		orders.POST("/:id/reorder", idempotency.Middleware(idemStore), cartC.Reorder)
+		orders.PUT("/:id/status", orderC.UpdateStatus)
+		orders.GET("/:id/history", orderC.GetHistory)
+		orders.GET("/:id/invoice", orderC.Invoice)
+		orders.PUT("/:id/rider", orderC.AssignRider)
+		orders.POST("/:id/rider/location", orderC.UpdateRiderLocation)
+	}
+
+	// platform promotions (admins) and the basket preview (customers)
+	promos := r.Group("/promotions")
+	promos.Use(middleware.AuthRequired())
+	{
+		promos.GET("", promoC.List)
+		promos.POST("", promoC.Create)
+		promos.DELETE("/:promotion_id", promoC.Deactivate)
+		promos.POST("/validate", promoC.Validate)
+	}
+
+	// delivery fee quotes, their per-city configs (admins) and the rider availability behind surge
+	fees := r.Group("/delivery-fee")
+	fees.Use(middleware.AuthRequired())
+	{
+		fees.POST("/quote", feeC.Quote)
+		fees.GET("/configs", feeC.ListConfigs)
+		fees.PUT("/configs", feeC.PutConfig)
+	}
+	r.PUT("/riders/availability", middleware.AuthRequired(), feeC.UpdateAvailability)
+
+	// server-side cart of the logged in user
+	cart := r.Group("/cart")
+	cart.Use(middleware.AuthRequired())
+	{
+		cart.GET("", cartC.Get)
+		cart.DELETE("", cartC.Clear)
+		cart.POST("/items", cartC.AddItem)
+		cart.PUT("/items/:item_id", cartC.UpdateItem)
+		cart.DELETE("/items/:item_id", cartC.RemoveItem)
+		cart.POST("/checkout", idempotency.Middleware(idemStore), cartC.Checkout)
+	}
+
+	// group orders: the join token in the path is the shared secret; guests add X-Participant-Token
+	r.POST("/group-carts", middleware.AuthRequired(), groupC.Create)
+	group := r.Group("/group-carts/:token")
+	group.Use(middleware.AuthOptional())
+	{
+		group.GET("", groupC.Get)
+		group.POST("/join", groupC.Join)
+		group.POST("/items", groupC.AddItem)
+		group.PUT("/items/:item_id", groupC.UpdateItem)
+		group.DELETE("/items/:item_id", groupC.RemoveItem)
+	}
+	host := r.Group("/group-carts/:token")
+	host.Use(middleware.AuthRequired())
+	{
+		host.POST("/lock", groupC.Lock)
+		host.POST("/unlock", groupC.Unlock)
+		host.DELETE("", groupC.Cancel)
+		host.POST("/checkout", idempotency.Middleware(idemStore), groupC.Checkout)
+	}
+
+	// dine-in: the table's QR token is the secret, staff run the tab and close it
+	r.POST("/dine-in/:qr_token/session", middleware.AuthOptional(), diningC.Scan)
+	dining := r.Group("/dining-sessions")
+	dining.Use(middleware.AuthRequired())
+	{
+		dining.GET("/:id", diningC.Get)
+		dining.POST("/:id/close", diningC.Close)
+		dining.POST("/:id/split", idempotency.Middleware(idemStore), diningC.Split)
+	}
+}
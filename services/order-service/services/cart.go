@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+// a line above this is almost certainly a typo in the app
+const maxCartLineQuantity = 50
+
+// CartService keeps a per-user cart and turns it into an order
+type CartService interface {
+	GetCart(userID int64) (*models.Cart, error)
+	AddItem(userID int64, item *models.CartItem, replaceCart bool) (*models.Cart, error)
+	UpdateItem(userID int64, item *models.CartItem) (*models.Cart, error)
+	RemoveItem(userID, itemID int64) (*models.Cart, error)
+	Clear(userID int64) error
+	Checkout(userID int64, req CartCheckout) (*models.Order, error)
+	// Reorder copies a past order's lines into the cart or straight into a new order
+	Reorder(userID, orderID int64, req ReorderRequest) (*ReorderResult, error)
+}
+
+// CartCheckout carries what the cart does not know yet: how and where the order goes
+type CartCheckout struct {
+	OrderType           string
+	DiningSessionID     *int64
+	TableToken          string // the table's QR token, required with DiningSessionID
+	DeliveryAddress     string
+	DeliveryLatitude    *float64
+	DeliveryLongitude   *float64
+	DeliveryAddressID   *int64 // a saved address of the user; required for delivery
+	TipAmount           float64
+	TotalAmount         float64 // optional: the total the user saw, checked like in POST /orders
+	PromoCode           string  // coupon; without one the best automatic offer applies
+	DeliveryFeeQuoteID  *int64  // from POST /delivery-fee/quote; without one the fee is quoted at checkout
+	SpecialInstructions *string
+	ScheduledFor        *time.Time
+}
+
+type cartService struct {
+	repo     repository.CartRepo
+	menuRepo repository.MenuRepo
+	orders   OrderService
+}
+
+func NewCartService(r repository.CartRepo, menuRepo repository.MenuRepo, orders OrderService) CartService {
+	return &cartService{repo: r, menuRepo: menuRepo, orders: orders}
+}
+
+func (s *cartService) GetCart(userID int64) (*models.Cart, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.load(cart)
+}
+
+/*
+AddItem puts a menu item in the cart. A cart holds one restaurant: adding from another
+one is "restaurant_mismatch" unless replaceCart empties it first. The same item with the
+same options is merged into the existing line.
+*/
+func (s *cartService) AddItem(userID int64, item *models.CartItem, replaceCart bool) (*models.Cart, error) {
+	if item.Quantity <= 0 || item.Quantity > maxCartLineQuantity {
+		return nil, errors.New("invalid_quantity")
+	}
+	menu, err := s.menuRepo.GetMenuItemsByIDs([]int64{item.MenuItemID})
+	if err != nil {
+		return nil, err
+	}
+	if len(menu) == 0 {
+		return nil, errors.New("not_found")
+	}
+	if menu[0].Availability != models.AvailabilityInStock {
+		return nil, errors.New("item_unavailable")
+	}
+	// picks are checked against the item's modifier groups and stored resolved, so equal picks merge
+	opts, _, reason := resolveOptions(menu[0], item.Options)
+	if reason != "" {
+		return nil, errors.New("invalid_options")
+	}
+	item.Options = opts
+
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.GetItems(cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 && cart.RestaurantID != nil && *cart.RestaurantID != menu[0].RestaurantID {
+		if !replaceCart {
+			return nil, errors.New("restaurant_mismatch")
+		}
+		if err := s.repo.Clear(nil, cart.ID); err != nil {
+			return nil, err
+		}
+		lines = nil
+	}
+	if len(lines) == 0 || cart.RestaurantID == nil || *cart.RestaurantID != menu[0].RestaurantID {
+		rid := menu[0].RestaurantID
+		if err := s.repo.SetRestaurant(cart.ID, &rid); err != nil {
+			return nil, err
+		}
+		cart.RestaurantID = &rid
+	}
+
+	for i := range lines {
+		l := &lines[i]
+		if l.MenuItemID == item.MenuItemID && bytes.Equal(l.Options, item.Options) && sameInstructions(l.SpecialInstructions, item.SpecialInstructions) {
+			l.Quantity += item.Quantity
+			if l.Quantity > maxCartLineQuantity {
+				return nil, errors.New("invalid_quantity")
+			}
+			if err := s.repo.UpdateItem(l); err != nil {
+				return nil, err
+			}
+			return s.load(cart)
+		}
+	}
+
+	item.CartID = cart.ID
+	if _, err := s.repo.AddItem(item); err != nil {
+		return nil, err
+	}
+	return s.load(cart)
+}
+
+// UpdateItem changes quantity, options or instructions of a line; quantity 0 removes it
+func (s *cartService) UpdateItem(userID int64, item *models.CartItem) (*models.Cart, error) {
+	if item.Quantity < 0 || item.Quantity > maxCartLineQuantity {
+		return nil, errors.New("invalid_quantity")
+	}
+	if item.Quantity == 0 {
+		return s.RemoveItem(userID, item.ID)
+	}
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetItem(cart.ID, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, errors.New("not_found")
+	}
+	existing.Quantity = item.Quantity
+	if item.Options != nil {
+		menu, err := s.menuRepo.GetMenuItemsByIDs([]int64{existing.MenuItemID})
+		if err != nil {
+			return nil, err
+		}
+		if len(menu) == 0 {
+			return nil, errors.New("item_unavailable")
+		}
+		opts, _, reason := resolveOptions(menu[0], item.Options)
+		if reason != "" {
+			return nil, errors.New("invalid_options")
+		}
+		existing.Options = opts
+	}
+	if item.SpecialInstructions != nil {
+		existing.SpecialInstructions = item.SpecialInstructions
+		if strings.TrimSpace(*item.SpecialInstructions) == "" {
+			existing.SpecialInstructions = nil
+		}
+	}
+	if err := s.repo.UpdateItem(existing); err != nil {
+		return nil, err
+	}
+	return s.load(cart)
+}
+
+func (s *cartService) RemoveItem(userID, itemID int64) (*models.Cart, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteItem(cart.ID, itemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("not_found")
+		}
+		return nil, err
+	}
+	return s.load(cart)
+}
+
+func (s *cartService) Clear(userID int64) error {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Clear(nil, cart.ID)
+}
+
+/*
+Checkout places the cart as an order through the normal PlaceOrder path, so pricing,
+scheduling and the order number work exactly as for POST /orders. The cart is emptied
+in the same transaction. Lines that can no longer be ordered fail the whole checkout
+with the usual OrderValidationError.
+*/
+func (s *cartService) Checkout(userID int64, req CartCheckout) (*models.Order, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	cart, err = s.load(cart)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 || cart.RestaurantID == nil {
+		return nil, errors.New("cart_empty")
+	}
+
+	now := time.Now().UTC()
+	order := newCheckoutOrder(userID, *cart.RestaurantID, req, now)
+	items := make([]models.OrderItem, 0, len(cart.Items))
+	for _, l := range cart.Items {
+		menuItemID := l.MenuItemID
+		items = append(items, models.OrderItem{
+			MenuItemID:          &menuItemID,
+			Name:                l.Name,
+			Quantity:            l.Quantity,
+			Options:             l.Options,
+			SpecialInstructions: l.SpecialInstructions,
+			CreatedAt:           &now,
+		})
+	}
+
+	orderID, err := s.orders.PlaceOrderWith(order, items, func(tx *sql.Tx, _ int64) error {
+		return s.repo.Clear(tx, cart.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.ID = orderID
+	order.Items = items
+	return order, nil
+}
+
+// newCheckoutOrder is the PLACED order a checkout submits, before pricing
+func newCheckoutOrder(userID, restaurantID int64, req CartCheckout, now time.Time) *models.Order {
+	var promoCode *string
+	if req.PromoCode != "" {
+		promoCode = &req.PromoCode
+	}
+	return &models.Order{
+		UserID:              userID,
+		RestaurantID:        restaurantID,
+		DiningSessionID:     req.DiningSessionID,
+		TableToken:          req.TableToken,
+		OrderType:           req.OrderType,
+		OrderStatus:         models.OrderStatusPlaced,
+		PaymentStatus:       models.PaymentStatusPending,
+		TipAmount:           req.TipAmount,
+		TotalAmount:         req.TotalAmount,
+		PromoCode:           promoCode,
+		DeliveryFeeQuoteID:  req.DeliveryFeeQuoteID,
+		DeliveryAddress:     req.DeliveryAddress,
+		DeliveryLatitude:    req.DeliveryLatitude,
+		DeliveryLongitude:   req.DeliveryLongitude,
+		DeliveryAddressID:   req.DeliveryAddressID,
+		SpecialInstructions: req.SpecialInstructions,
+		ScheduledFor:        req.ScheduledFor,
+		CreatedAt:           &now,
+		UpdatedAt:           &now,
+	}
+}
+
+// load fills the cart's lines with current menu names, prices and availability
+func (s *cartService) load(cart *models.Cart) (*models.Cart, error) {
+	lines, err := s.repo.GetItems(cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.CartItem, len(lines))
+	for i := range lines {
+		refs[i] = &lines[i]
+	}
+	subtotal, ok, err := priceCartLines(s.menuRepo, cart.RestaurantID, refs)
+	if err != nil {
+		return nil, err
+	}
+	cart.Items = lines
+	cart.Subtotal = subtotal
+	cart.Checkout = ok && len(lines) > 0
+	return cart, nil
+}
+
+/*
+priceCartLines re-validates cart lines against the menu on every read: names and prices
+come from the menu, lines that cannot be ordered get a Problem. It returns the subtotal of
+the orderable lines and whether all of them are orderable.
+*/
+func priceCartLines(menuRepo repository.MenuRepo, restaurantID *int64, lines []*models.CartItem) (float64, bool, error) {
+	ids := make([]int64, 0, len(lines))
+	for _, l := range lines {
+		ids = append(ids, l.MenuItemID)
+	}
+	menu, err := menuRepo.GetMenuItemsByIDs(ids)
+	if err != nil {
+		return 0, false, err
+	}
+	byID := make(map[int64]models.MenuItem, len(menu))
+	for _, m := range menu {
+		byID[m.ID] = m
+	}
+
+	subtotal := 0.0
+	allOK := true
+	for _, l := range lines {
+		m, ok := byID[l.MenuItemID]
+		switch {
+		case !ok:
+			l.Problem = "menu item no longer exists"
+		case restaurantID == nil || m.RestaurantID != *restaurantID:
+			l.Problem = "item belongs to another restaurant"
+		case m.Availability != models.AvailabilityInStock:
+			l.Name = m.Name
+			l.Problem = "item is not available"
+		default:
+			opts, delta, reason := resolveOptions(m, l.Options)
+			l.Name = m.Name
+			if reason != "" {
+				l.Problem = reason
+				break
+			}
+			l.Options = opts
+			l.UnitPrice = roundMoney(m.Price + delta)
+			l.TotalPrice = roundMoney(l.UnitPrice * float64(l.Quantity))
+			l.Available = true
+			subtotal += l.TotalPrice
+		}
+		if !l.Available {
+			allOK = false
+		}
+	}
+	return roundMoney(subtotal), allOK, nil
+}
+
+// normalizeOptions re-encodes options so equal selections compare byte for byte
+func normalizeOptions(raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	// encoding/json writes map keys sorted
+	return json.Marshal(v)
+}
+
+func sameInstructions(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return strings.TrimSpace(*a) == strings.TrimSpace(*b)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+/*
+applyDeliveryAddress fills a new delivery order from the customer's saved address in
+user-service. The GST place of supply is always the address's state, never something the
+client says; the drop point and the address text come from it when the client sent none.
+Orders already placed keep what was stored with them.
+*/
+func (s *orderService) applyDeliveryAddress(order *models.Order, verr *OrderValidationError) error {
+	if order.ID != 0 {
+		return nil
+	}
+	order.DeliveryState = ""
+	if !isDeliveryOrder(order) || order.DiningSessionID != nil {
+		return nil
+	}
+	if order.DeliveryAddressID == nil {
+		verr.add(-1, nil, "deliveryAddressId", "required for delivery")
+		return nil
+	}
+	addr, err := s.users.GetAddress(*order.DeliveryAddressID)
+	if err != nil {
+		return err
+	}
+	if addr == nil || addr.UserID != order.UserID {
+		verr.add(-1, nil, "deliveryAddressId", "address not found")
+		return nil
+	}
+	if strings.TrimSpace(addr.State) == "" {
+		verr.add(-1, nil, "deliveryAddressId", "address has no state")
+		return nil
+	}
+	order.DeliveryState = strings.TrimSpace(addr.State)
+	if order.DeliveryLatitude == nil && order.DeliveryLongitude == nil {
+		order.DeliveryLatitude, order.DeliveryLongitude = addr.Latitude, addr.Longitude
+	}
+	if strings.TrimSpace(order.DeliveryAddress) == "" {
+		order.DeliveryAddress = formatAddress(addr.AddressLine1, addr.AddressLine2, addr.City, addr.State, addr.Pincode)
+	}
+	return nil
+}
+
+// formatAddress joins the non-empty parts of an address into one line
+func formatAddress(parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ", ")
+}
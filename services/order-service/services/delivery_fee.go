@@ -0,0 +1,349 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+const (
+	// how long a quoted distance fee and surge hold
+	deliveryQuoteTTL = 10 * time.Minute
+	// riders not heard from for this long are offline
+	riderPresenceWindow = 5 * time.Minute
+	// every order waiting per free rider beyond the first adds this much to the multiplier
+	surgeStep = 0.25
+	// a drop point may move this far from the quoted one, e.g. a corrected pin
+	quoteToleranceKm   = 0.2
+	maxSurgeMultiplier = 5.0
+)
+
+// DeliveryFeeService quotes delivery fees and manages what they are computed from
+type DeliveryFeeService interface {
+	// Quote prices delivery from the restaurant to the drop point; subtotal decides surcharge and free delivery
+	Quote(userID, restaurantID int64, lat, lon *float64, subtotal float64) (*models.DeliveryFeeQuote, *models.DeliveryFeeBreakdown, error)
+	ListConfigs(tokenUserID int64, role string) ([]models.DeliveryFeeConfig, error)
+	PutConfig(cfg *models.DeliveryFeeConfig, tokenUserID int64, role string) (*models.DeliveryFeeConfig, error)
+	// UpdateRiderPresence is a rider's availability heartbeat
+	UpdateRiderPresence(p *models.RiderPresence, tokenUserID int64, role string) error
+}
+
+type deliveryFeeService struct {
+	repo     repository.DeliveryFeeRepo
+	restRepo repository.RestaurantRepo
+}
+
+func NewDeliveryFeeService(r repository.DeliveryFeeRepo, restRepo repository.RestaurantRepo) DeliveryFeeService {
+	return &deliveryFeeService{repo: r, restRepo: restRepo}
+}
+
+func (s *deliveryFeeService) Quote(userID, restaurantID int64, lat, lon *float64, subtotal float64) (*models.DeliveryFeeQuote, *models.DeliveryFeeBreakdown, error) {
+	if (lat == nil) != (lon == nil) {
+		return nil, nil, errors.New("invalid_location")
+	}
+	if subtotal < 0 {
+		return nil, nil, errors.New("invalid_subtotal")
+	}
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rest == nil {
+		return nil, nil, errors.New("not_found")
+	}
+	q, err := quoteDeliveryFee(s.repo, rest, userID, lat, lon, time.Now().UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.InsertQuote(nil, q); err != nil {
+		return nil, nil, err
+	}
+	fee := deliveryFee(q, roundMoney(subtotal))
+	return q, &fee, nil
+}
+
+func (s *deliveryFeeService) ListConfigs(tokenUserID int64, role string) ([]models.DeliveryFeeConfig, error) {
+	if !strings.Contains(strings.ToUpper(role), "ADMIN") {
+		return nil, errors.New("forbidden")
+	}
+	return s.repo.ListConfigs()
+}
+
+// PutConfig replaces a city's config; city "" is the default
+func (s *deliveryFeeService) PutConfig(cfg *models.DeliveryFeeConfig, tokenUserID int64, role string) (*models.DeliveryFeeConfig, error) {
+	if !strings.Contains(strings.ToUpper(role), "ADMIN") {
+		return nil, errors.New("forbidden")
+	}
+	cfg.City = strings.TrimSpace(cfg.City)
+	if len(cfg.Slabs) == 0 {
+		return nil, errors.New("invalid_slabs")
+	}
+	prev := 0.0
+	for i := range cfg.Slabs {
+		sl := &cfg.Slabs[i]
+		if sl.UpToKm <= prev || sl.Fee < 0 {
+			return nil, errors.New("invalid_slabs")
+		}
+		prev = sl.UpToKm
+		sl.Fee = roundMoney(sl.Fee)
+	}
+	if cfg.SmallOrderThreshold < 0 || cfg.SmallOrderSurcharge < 0 || (cfg.FreeDeliveryThreshold != nil && *cfg.FreeDeliveryThreshold < 0) {
+		return nil, errors.New("invalid_amount")
+	}
+	if cfg.SurgeMaxMultiplier == 0 {
+		cfg.SurgeMaxMultiplier = 1
+	}
+	if cfg.SurgeMaxMultiplier < 1 || cfg.SurgeMaxMultiplier > maxSurgeMultiplier {
+		return nil, errors.New("invalid_surge")
+	}
+	if err := s.repo.UpsertConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (s *deliveryFeeService) UpdateRiderPresence(p *models.RiderPresence, tokenUserID int64, role string) error {
+	upper := strings.ToUpper(role)
+	if tokenUserID == 0 || !(strings.Contains(upper, "RIDER") || strings.Contains(upper, "DELIVERY")) {
+		return errors.New("forbidden")
+	}
+	p.City = strings.TrimSpace(p.City)
+	if p.City == "" {
+		return errors.New("city_required")
+	}
+	if (p.Latitude == nil) != (p.Longitude == nil) {
+		return errors.New("invalid_location")
+	}
+	p.RiderID = tokenUserID
+	p.LastSeenAt = time.Now().UTC()
+	return s.repo.UpsertPresence(p)
+}
+
+/*
+quoteDeliveryFee prices delivery from rest to the drop point with the config of the
+restaurant's city; the caller stores the quote. The distance is the straight line
+stretched by roadFactor, like the ETA's. Both ends need coordinates (location_required,
+restaurant_unlocated) and drop points past the last slab are refused with out_of_delivery_area.
+*/
+func quoteDeliveryFee(fees repository.DeliveryFeeRepo, rest *models.Restaurant, userID int64, lat, lon *float64, now time.Time) (*models.DeliveryFeeQuote, error) {
+	if lat == nil || lon == nil {
+		return nil, errors.New("location_required")
+	}
+	if rest.Latitude == nil || rest.Longitude == nil {
+		return nil, errors.New("restaurant_unlocated")
+	}
+	cfg, err := fees.GetConfig(rest.City)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || len(cfg.Slabs) == 0 {
+		return nil, errors.New("delivery fees are not configured")
+	}
+	q := &models.DeliveryFeeQuote{
+		UserID:                userID,
+		RestaurantID:          rest.ID,
+		City:                  cfg.City,
+		Latitude:              lat,
+		Longitude:             lon,
+		DistanceFee:           cfg.Slabs[0].Fee,
+		SmallOrderThreshold:   cfg.SmallOrderThreshold,
+		SmallOrderSurcharge:   cfg.SmallOrderSurcharge,
+		FreeDeliveryThreshold: cfg.FreeDeliveryThreshold,
+		ExpiresAt:             now.Add(deliveryQuoteTTL),
+		CreatedAt:             now,
+	}
+	km := math.Round(distanceKm(*rest.Latitude, *rest.Longitude, *lat, *lon)*roadFactor*1000) / 1000
+	q.DistanceKm = &km
+	found := false
+	for _, sl := range cfg.Slabs {
+		if km <= sl.UpToKm {
+			q.DistanceFee = sl.Fee
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("out_of_delivery_area")
+	}
+
+	q.SurgeMultiplier = 1
+	if cfg.SurgeMaxMultiplier > 1 {
+		// surge follows the restaurant's city even when it is priced with the default config
+		open, err := fees.CountAwaitingRider(rest.City)
+		if err != nil {
+			return nil, err
+		}
+		online, available, err := fees.RiderSupply(rest.City, now.Add(-riderPresenceWindow))
+		if err != nil {
+			return nil, err
+		}
+		q.OpenOrders, q.AvailableRiders = open, available
+		q.SurgeMultiplier = surgeMultiplier(open, online, available, cfg.SurgeMaxMultiplier)
+	}
+	return q, nil
+}
+
+/*
+surgeMultiplier turns demand into a multiplier: at most one order waiting per free rider
+is normal, every order per rider beyond that adds surgeStep, up to max. Cities where no
+rider reports availability get no surge, there is nothing to compare with.
+*/
+func surgeMultiplier(open, online, available int, max float64) float64 {
+	if online == 0 || open == 0 {
+		return 1
+	}
+	riders := available
+	if riders < 1 {
+		riders = 1
+	}
+	ratio := float64(open) / float64(riders)
+	if ratio <= 1 {
+		return 1
+	}
+	m := 1 + (ratio-1)*surgeStep
+	if m > max {
+		m = max
+	}
+	// steps of 0.05 so the multiplier does not flicker with every order
+	return math.Round(m*20) / 20
+}
+
+// deliveryFee applies the basket-dependent rules of a quote to a subtotal
+func deliveryFee(q *models.DeliveryFeeQuote, subtotal float64) models.DeliveryFeeBreakdown {
+	b := models.DeliveryFeeBreakdown{
+		DistanceFee:     q.DistanceFee,
+		SurgeMultiplier: q.SurgeMultiplier,
+	}
+	surged := roundMoney(q.DistanceFee * q.SurgeMultiplier)
+	b.SurgeAmount = roundMoney(surged - q.DistanceFee)
+	if q.FreeDeliveryThreshold != nil && subtotal >= *q.FreeDeliveryThreshold {
+		b.FreeDelivery = true
+		surged = 0
+	}
+	if subtotal < q.SmallOrderThreshold {
+		b.SmallOrderSurcharge = q.SmallOrderSurcharge
+	}
+	b.Fee = roundMoney(surged + b.SmallOrderSurcharge)
+	return b
+}
+
+/*
+applyDeliveryFee sets the order's delivery fee during pricing. Delivery orders are
+charged from the quote in DeliveryFeeQuoteID, which must be the customer's own, for the
+same restaurant and drop point, and not expired; without one a quote is made on the spot.
+Amended orders keep the quote they were placed with. Pickup and dine-in orders pay no
+delivery fee. A client deliveryFee is only an assertion.
+*/
+func (s *orderService) applyDeliveryFee(order *models.Order, subtotal float64, now time.Time, preview bool, verr *OrderValidationError) error {
+	claimed := order.DeliveryFee
+	if !isDeliveryOrder(order) || order.DiningSessionID != nil {
+		order.DeliveryFee = 0
+		order.DeliveryFeeQuoteID = nil
+		if claimed != 0 && order.ID == 0 {
+			verr.mismatch(-1, nil, "deliveryFee", 0, claimed)
+		}
+		return nil
+	}
+	if order.ID != 0 && order.DeliveryFeeQuoteID == nil {
+		// placed before delivery fees were computed here
+		return nil
+	}
+
+	var q *models.DeliveryFeeQuote
+	if order.DeliveryFeeQuoteID != nil {
+		var err error
+		if q, err = s.fees.GetQuote(*order.DeliveryFeeQuoteID); err != nil {
+			return err
+		}
+		if order.ID == 0 {
+			reason := ""
+			switch {
+			case q == nil || q.UserID != order.UserID || q.RestaurantID != order.RestaurantID:
+				reason = "quote not found"
+			case !now.Before(q.ExpiresAt):
+				reason = "quote expired, request a new one"
+			case q.Latitude != nil && order.DeliveryLatitude != nil && order.DeliveryLongitude != nil &&
+				distanceKm(*q.Latitude, *q.Longitude, *order.DeliveryLatitude, *order.DeliveryLongitude) > quoteToleranceKm:
+				reason = "delivery address changed since the quote"
+			}
+			if reason != "" {
+				verr.add(-1, nil, "deliveryFeeQuoteId", reason)
+				return nil
+			}
+		}
+	}
+	if q == nil {
+		rest, err := s.restRepo.GetByID(order.RestaurantID)
+		if err != nil {
+			return err
+		}
+		if rest == nil {
+			verr.add(-1, nil, "restaurantId", "restaurant not found")
+			return nil
+		}
+		q, err = quoteDeliveryFee(s.fees, rest, order.UserID, order.DeliveryLatitude, order.DeliveryLongitude, now)
+		if err != nil {
+			switch err.Error() {
+			case "out_of_delivery_area":
+				verr.add(-1, nil, "deliveryLatitude", "address is outside the delivery area")
+				return nil
+			case "location_required":
+				verr.add(-1, nil, "deliveryLatitude", "deliveryLatitude and deliveryLongitude are required for delivery")
+				return nil
+			case "restaurant_unlocated":
+				verr.add(-1, nil, "restaurantId", "restaurant has no location to deliver from")
+				return nil
+			}
+			return err
+		}
+	}
+
+	fee := deliveryFee(q, subtotal)
+	if claimed != 0 && order.ID == 0 && !moneyEqual(claimed, fee.Fee) {
+		verr.mismatch(-1, nil, "deliveryFee", fee.Fee, claimed)
+	}
+	order.DeliveryFee = fee.Fee
+	order.DeliveryFeeQuoteID = nil
+	order.PendingFeeQuote = nil
+	if q.ID != 0 {
+		order.DeliveryFeeQuoteID = &q.ID
+	} else if !preview {
+		// a preview only shows the fee; placing stores the quote with the order, in its transaction
+		order.PendingFeeQuote = q
+	}
+	return nil
+}
+
+/*
+lockDeliveryFee stores the quote pricing made for the order, or makes sure the quote the
+client brought has not priced another order. It runs in the order transaction, so a quote
+never outlives a failed placement.
+*/
+func (s *orderService) lockDeliveryFee(tx *sql.Tx, order *models.Order) error {
+	if order.PendingFeeQuote != nil {
+		if err := s.fees.InsertQuote(tx, order.PendingFeeQuote); err != nil {
+			return err
+		}
+		order.DeliveryFeeQuoteID = &order.PendingFeeQuote.ID
+		order.PendingFeeQuote = nil
+		return nil
+	}
+	if order.DeliveryFeeQuoteID == nil {
+		return nil
+	}
+	used, err := s.fees.LockQuote(tx, *order.DeliveryFeeQuoteID)
+	if err != nil {
+		return err
+	}
+	if used {
+		verr := &OrderValidationError{}
+		verr.add(-1, nil, "deliveryFeeQuoteId", "quote was already used for another order")
+		return verr
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+// DiningService runs dine-in sessions: a table's QR code opens one, guests order rounds into it, staff close it
+type DiningService interface {
+	// Scan opens the table's session or joins the one already open
+	Scan(qrToken string, tokenUserID int64) (*models.DiningSession, error)
+	GetSession(sessionID int64, tokenUserID int64, role string) (*models.DiningSession, error)
+	ListSessions(restaurantID int64, status string, tokenUserID int64, role string) ([]models.DiningSession, error)
+	CloseSession(sessionID int64, tokenUserID int64, role string) (*models.DiningSession, error)
+
+	// settlement
+	SplitBill(sessionID int64, req SplitRequest, tokenUserID int64, role string) (*models.DiningSession, error)
+	SyncSettlements() error
+}
+
+type diningService struct {
+	repo      repository.DiningSessionRepo
+	restRepo  repository.RestaurantRepo
+	orderRepo repository.OrderRepo
+	payments  clients.PaymentClient
+	db        *sql.DB
+}
+
+func NewDiningService(r repository.DiningSessionRepo, restRepo repository.RestaurantRepo, orderRepo repository.OrderRepo, payments clients.PaymentClient, db *sql.DB) DiningService {
+	return &diningService{repo: r, restRepo: restRepo, orderRepo: orderRepo, payments: payments, db: db}
+}
+
+/*
+Scan is what the table's QR code leads to. Whoever holds the code is at the table, so the
+code alone is enough to open or join the session and see the running tab; signed-in
+guests are remembered as the opener. Inactive tables cannot be used.
+*/
+func (s *diningService) Scan(qrToken string, tokenUserID int64) (*models.DiningSession, error) {
+	qrToken = strings.TrimSpace(qrToken)
+	if qrToken == "" {
+		return nil, errors.New("not_found")
+	}
+	table, err := s.restRepo.GetTableByQRToken(qrToken)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, errors.New("not_found")
+	}
+	if !table.IsActive {
+		return nil, errors.New("table_inactive")
+	}
+	var openedBy *int64
+	if tokenUserID != 0 {
+		openedBy = &tokenUserID
+	}
+	session, _, err := s.repo.Open(table, openedBy)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadTab(nil, session)
+}
+
+// GetSession is the running tab for staff
+func (s *diningService) GetSession(sessionID int64, tokenUserID int64, role string) (*models.DiningSession, error) {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.New("not_found")
+	}
+	if err := s.authorizeStaff(session.RestaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	if session.Status == models.DiningSessionClosed {
+		// pick up payments made since the last sync
+		if err := s.syncSettlement(session.ID); err != nil {
+			log.Printf("dining session %d: settlement sync: %v", session.ID, err)
+		}
+		if session, err = s.repo.GetByID(sessionID); err != nil {
+			return nil, err
+		}
+	}
+	return s.loadTab(nil, session)
+}
+
+func (s *diningService) ListSessions(restaurantID int64, status string, tokenUserID int64, role string) ([]models.DiningSession, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status != "" && status != models.DiningSessionOpen && status != models.DiningSessionClosed && status != models.DiningSessionSettled {
+		return nil, errors.New("invalid_status")
+	}
+	if err := s.authorizeStaff(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByRestaurant(restaurantID, status)
+}
+
+/*
+CloseSession ends the sitting and produces its consolidated bill. Every round has to be
+served (DELIVERED) or cancelled first so nothing is billed that did not reach the table.
+The session row is locked before the orders are read: a round being placed right now
+either lands before the close or is rejected because the session is closed.
+*/
+func (s *diningService) CloseSession(sessionID int64, tokenUserID int64, role string) (*models.DiningSession, error) {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.New("not_found")
+	}
+	if err := s.authorizeStaff(session.RestaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	locked, err := s.repo.LockForClose(tx, sessionID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if locked == nil {
+		_ = tx.Rollback()
+		return nil, errors.New("not_found")
+	}
+	if locked.Status != models.DiningSessionOpen {
+		_ = tx.Rollback()
+		return nil, errors.New("session_closed")
+	}
+	session, err = s.loadTab(tx, session)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	for _, o := range session.Orders {
+		if o.OrderStatus != models.OrderStatusDelivered && o.OrderStatus != models.OrderStatusCancelled {
+			_ = tx.Rollback()
+			return nil, errors.New("orders_in_progress")
+		}
+	}
+	if err := s.repo.Close(tx, sessionID, tokenUserID, session.Bill); err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("session_closed")
+		}
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	closed, err := s.repo.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	closed.Orders = session.Orders
+	closed.Bill = session.Bill
+	return closed, nil
+}
+
+// loadTab attaches the session's orders with their lines and the bill so far
+func (s *diningService) loadTab(tx *sql.Tx, session *models.DiningSession) (*models.DiningSession, error) {
+	orders, err := s.repo.ListOrders(tx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	items, err := s.orderRepo.GetItemsForOrders(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].Items = items[orders[i].ID]
+	}
+	session.Orders = orders
+	session.Bill = buildDiningBill(orders)
+	if session.Status != models.DiningSessionOpen {
+		shares, err := s.repo.ListShares(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		session.Shares = shares
+	}
+	return session, nil
+}
+
+func (s *diningService) authorizeStaff(restaurantID int64, tokenUserID int64, role string) error {
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	upper := strings.ToUpper(role)
+	if rest.OwnerAuthUserID == nil || (*rest.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+/*
+buildDiningBill merges all rounds into one bill. The same dish at the same price is one
+line however many rounds it was ordered in; amounts are the sum of the orders' own
+server-side amounts, so the bill always matches what each round was priced at.
+*/
+func buildDiningBill(orders []models.Order) *models.DiningBill {
+	type lineKey struct {
+		menuItemID int64
+		name       string
+		unitPrice  float64
+	}
+	bill := &models.DiningBill{Lines: []models.DiningBillLine{}}
+	index := map[lineKey]int{}
+	for _, o := range orders {
+		if o.OrderStatus == models.OrderStatusCancelled {
+			continue
+		}
+		bill.OrderCount++
+		bill.SubtotalAmount += o.SubtotalAmount
+		bill.TaxAmount += o.TaxAmount
+		bill.DiscountAmount += o.DiscountAmount
+		bill.TipAmount += o.TipAmount
+		bill.TotalAmount += o.TotalAmount
+		for _, it := range o.Items {
+			k := lineKey{name: it.Name, unitPrice: it.UnitPrice}
+			if it.MenuItemID != nil {
+				k.menuItemID = *it.MenuItemID
+			}
+			if i, ok := index[k]; ok {
+				bill.Lines[i].Quantity += it.Quantity
+				bill.Lines[i].TotalPrice = roundMoney(bill.Lines[i].TotalPrice + it.TotalPrice)
+				continue
+			}
+			index[k] = len(bill.Lines)
+			bill.Lines = append(bill.Lines, models.DiningBillLine{
+				MenuItemID: it.MenuItemID,
+				Name:       it.Name,
+				UnitPrice:  it.UnitPrice,
+				Quantity:   it.Quantity,
+				TotalPrice: roundMoney(it.TotalPrice),
+			})
+		}
+	}
+	bill.SubtotalAmount = roundMoney(bill.SubtotalAmount)
+	bill.TaxAmount = roundMoney(bill.TaxAmount)
+	bill.DiscountAmount = roundMoney(bill.DiscountAmount)
+	bill.TipAmount = roundMoney(bill.TipAmount)
+	bill.TotalAmount = roundMoney(bill.TotalAmount)
+	return bill
+}
+
+/*
+checkDiningSession rejects orders into a session that is closed, unknown, elsewhere or at an
+inactive table. The order must quote the table's QR token: knowing a session id is not
+proof of sitting at the table.
+*/
+func (s *orderService) checkDiningSession(tx *sql.Tx, order *models.Order) error {
+	session, tableActive, err := s.dining.LockForOrder(tx, *order.DiningSessionID)
+	if err != nil {
+		return err
+	}
+	verr := &OrderValidationError{}
+	switch {
+	case session == nil:
+		verr.add(-1, nil, "diningSessionId", "dining session not found")
+	case session.RestaurantID != order.RestaurantID:
+		verr.add(-1, nil, "diningSessionId", "dining session belongs to another restaurant")
+	case session.Status != models.DiningSessionOpen:
+		verr.add(-1, nil, "diningSessionId", "dining session is closed")
+	case !tableActive:
+		verr.add(-1, nil, "diningSessionId", "table is not active")
+	case session.TableQRToken == "" || subtle.ConstantTimeCompare([]byte(order.TableToken), []byte(session.TableQRToken)) != 1:
+		verr.add(-1, nil, "tableToken", "must be the QR token of the session's table")
+	}
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	return nil
+}
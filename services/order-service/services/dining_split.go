@@ -0,0 +1,403 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+const (
+	// more shares than this is not a table any more
+	maxBillShares = 20
+	// sessions checked against payment-service per sync pass
+	settlementSyncBatch = 50
+)
+
+// SplitRequest says how to split a closed session's bill
+type SplitRequest struct {
+	Mode   string       // EVEN, ITEM or CUSTOM
+	Parts  int          // EVEN: number of equal shares
+	Shares []SplitShare // ITEM and CUSTOM: one entry per payer
+}
+
+// SplitShare is one payer in an ITEM or CUSTOM split
+type SplitShare struct {
+	Label       string
+	PayerUserID *int64
+	Lines       []models.ShareLine // ITEM: bill lines and quantities this payer had
+	Amount      float64            // CUSTOM: what this payer pays, tax and tip included
+}
+
+/*
+SplitBill splits a CLOSED session's bill into shares and opens one payment per share in
+payment-service. The session becomes SETTLED once every share is paid. A bill can be split
+again until the first share is paid; the previous shares' payments are withdrawn first.
+*/
+func (s *diningService) SplitBill(sessionID int64, req SplitRequest, tokenUserID int64, role string) (*models.DiningSession, error) {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.New("not_found")
+	}
+	if err := s.authorizeStaff(session.RestaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	switch session.Status {
+	case models.DiningSessionOpen:
+		return nil, errors.New("session_open")
+	case models.DiningSessionSettled:
+		return nil, errors.New("session_settled")
+	}
+	session, err = s.loadTab(nil, session)
+	if err != nil {
+		return nil, err
+	}
+	mode := strings.ToUpper(strings.TrimSpace(req.Mode))
+	shares, err := splitBill(session.Bill, mode, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withdrawShares(session.Shares); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	locked, err := s.repo.LockForClose(tx, sessionID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if locked == nil || locked.Status != models.DiningSessionClosed {
+		_ = tx.Rollback()
+		return nil, errors.New("session_settled")
+	}
+	if err := s.repo.ReplaceShares(tx, sessionID, mode, shares); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for i := range shares {
+		// payment-service being down is not fatal: the settlement sync opens what is missing
+		if err := s.openSharePayment(session, &shares[i]); err != nil {
+			log.Printf("dining session %d: payment for share %d: %v", sessionID, shares[i].ID, err)
+		}
+	}
+	if session, err = s.repo.GetByID(sessionID); err != nil {
+		return nil, err
+	}
+	return s.loadTab(nil, session)
+}
+
+// withdrawShares cancels the pending payments of a split that is being replaced
+func (s *diningService) withdrawShares(shares []models.BillShare) error {
+	for _, sh := range shares {
+		if sh.PaymentStatus == models.SharePaymentPaid {
+			return errors.New("split_locked")
+		}
+		if sh.PaymentID == nil {
+			continue
+		}
+		p, err := s.payments.CancelPayment(*sh.PaymentID)
+		if err != nil {
+			return err
+		}
+		if p.Status == models.SharePaymentPaid {
+			// paid while we were looking
+			if err := s.repo.MarkSharePaid(sh.ID); err != nil {
+				return err
+			}
+			return errors.New("split_locked")
+		}
+	}
+	return nil
+}
+
+// openSharePayment opens the payment for the share's current attempt
+func (s *diningService) openSharePayment(session *models.DiningSession, sh *models.BillShare) error {
+	payer := int64(0)
+	if sh.PayerUserID != nil {
+		payer = *sh.PayerUserID
+	}
+	p, err := s.payments.CreatePayment(clients.PaymentRequest{
+		Reference:   fmt.Sprintf("dining-%d-share-%d-%d", session.ID, sh.ID, sh.PaymentAttempt),
+		UserID:      payer,
+		Amount:      sh.Amount,
+		Description: fmt.Sprintf("Table %s, %s", session.TableIdentifier, sh.Label),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetSharePayment(sh.ID, p.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the split was replaced meanwhile; nobody will ask for this payment
+			_, _ = s.payments.CancelPayment(p.ID)
+			return nil
+		}
+		return err
+	}
+	sh.PaymentID = &p.ID
+	sh.PaymentStatus = models.SharePaymentPending
+	return nil
+}
+
+/*
+syncSettlement brings a split session's shares in line with payment-service: paid shares
+are recorded, failed or cancelled payments are replaced by a new attempt, missing ones are
+opened. When every share is paid the session is SETTLED.
+*/
+func (s *diningService) syncSettlement(sessionID int64) error {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.Status != models.DiningSessionClosed {
+		return nil
+	}
+	shares, err := s.repo.ListShares(sessionID)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		return nil
+	}
+	for i := range shares {
+		sh := &shares[i]
+		if sh.PaymentStatus == models.SharePaymentPaid {
+			continue
+		}
+		if sh.PaymentID != nil {
+			p, err := s.payments.GetPayment(*sh.PaymentID)
+			if err != nil {
+				return err
+			}
+			switch p.Status {
+			case models.SharePaymentPaid:
+				if err := s.repo.MarkSharePaid(sh.ID); err != nil {
+					return err
+				}
+				sh.PaymentStatus = models.SharePaymentPaid
+				continue
+			case models.SharePaymentPending:
+				continue
+			default:
+				// FAILED or CANCELLED: the guest needs a fresh payment
+				if err := s.repo.MarkShareFailed(sh.ID); err != nil {
+					return err
+				}
+				sh.PaymentAttempt++
+			}
+		}
+		if err := s.openSharePayment(session, sh); err != nil {
+			return err
+		}
+	}
+	for _, sh := range shares {
+		if sh.PaymentStatus != models.SharePaymentPaid {
+			return nil
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Settle(tx, sessionID); err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			// re-split or settled elsewhere
+			return nil
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// SyncSettlements runs syncSettlement over the sessions waiting for payments
+func (s *diningService) SyncSettlements() error {
+	ids, err := s.repo.ListSettling(settlementSyncBatch)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		// one unreachable payment should not hold up the other tables
+		if err := s.syncSettlement(id); err != nil {
+			log.Printf("dining session %d: settlement sync: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// RunSettlementSync calls SyncSettlements every interval, forever
+func RunSettlementSync(svc DiningService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := svc.SyncSettlements(); err != nil {
+			log.Printf("settlement sync: %v", err)
+		}
+	}
+}
+
+/*
+splitBill turns the bill into shares. Every mode first decides what each share pays in
+total; tax, tip and discount are then divided in proportion to those amounts, and the
+share's subtotal is what remains. Amounts are allocated in paise so the shares always add
+up to the bill exactly.
+*/
+func splitBill(bill *models.DiningBill, mode string, req SplitRequest) ([]models.BillShare, error) {
+	if bill == nil || bill.TotalAmount <= 0 {
+		return nil, errors.New("nothing_to_pay")
+	}
+	var shares []models.BillShare
+	var amounts []float64
+
+	switch mode {
+	case models.SplitEven:
+		if req.Parts < 1 || req.Parts > maxBillShares {
+			return nil, errors.New("invalid_parts")
+		}
+		weights := make([]float64, req.Parts)
+		for i := range weights {
+			weights[i] = 1
+			shares = append(shares, models.BillShare{Label: fmt.Sprintf("Share %d", i+1)})
+		}
+		amounts = allocateCents(bill.TotalAmount, weights)
+
+	case models.SplitByItem:
+		if len(req.Shares) < 1 || len(req.Shares) > maxBillShares {
+			return nil, errors.New("invalid_shares")
+		}
+		assigned := make([]int, len(bill.Lines))
+		weights := make([]float64, len(req.Shares))
+		for i, rs := range req.Shares {
+			sh := models.BillShare{Label: shareLabel(rs.Label, i), PayerUserID: rs.PayerUserID}
+			for _, l := range rs.Lines {
+				if l.Line < 0 || l.Line >= len(bill.Lines) || l.Quantity <= 0 {
+					return nil, errors.New("invalid_share_line")
+				}
+				bl := bill.Lines[l.Line]
+				assigned[l.Line] += l.Quantity
+				weights[i] += bl.TotalPrice * float64(l.Quantity) / float64(bl.Quantity)
+				sh.Lines = append(sh.Lines, models.ShareLine{Line: l.Line, Name: bl.Name, Quantity: l.Quantity})
+			}
+			shares = append(shares, sh)
+		}
+		for i, bl := range bill.Lines {
+			if assigned[i] != bl.Quantity {
+				return nil, errors.New("items_unassigned")
+			}
+		}
+		sum := 0.0
+		for _, w := range weights {
+			sum += w
+		}
+		if sum <= 0 {
+			return nil, errors.New("nothing_to_pay")
+		}
+		amounts = allocateCents(bill.TotalAmount, weights)
+
+	case models.SplitCustom:
+		if len(req.Shares) < 1 || len(req.Shares) > maxBillShares {
+			return nil, errors.New("invalid_shares")
+		}
+		cents := int64(0)
+		for i, rs := range req.Shares {
+			if rs.Amount <= 0 {
+				return nil, errors.New("invalid_amount")
+			}
+			amounts = append(amounts, roundMoney(rs.Amount))
+			cents += toCents(rs.Amount)
+			shares = append(shares, models.BillShare{Label: shareLabel(rs.Label, i), PayerUserID: rs.PayerUserID})
+		}
+		if cents != toCents(bill.TotalAmount) {
+			return nil, errors.New("amounts_mismatch")
+		}
+
+	default:
+		return nil, errors.New("invalid_split_mode")
+	}
+
+	taxes := allocateCents(bill.TaxAmount, amounts)
+	tips := allocateCents(bill.TipAmount, amounts)
+	discounts := allocateCents(bill.DiscountAmount, amounts)
+	for i := range shares {
+		if amounts[i] <= 0 {
+			return nil, errors.New("invalid_amount")
+		}
+		shares[i].Amount = amounts[i]
+		shares[i].TaxAmount = taxes[i]
+		shares[i].TipAmount = tips[i]
+		shares[i].DiscountAmount = discounts[i]
+		shares[i].SubtotalAmount = roundMoney(amounts[i] - taxes[i] - tips[i] + discounts[i])
+	}
+	return shares, nil
+}
+
+func shareLabel(label string, i int) string {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return fmt.Sprintf("Share %d", i+1)
+	}
+	if len(label) > 64 {
+		label = label[:64]
+	}
+	return label
+}
+
+func toCents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+/*
+allocateCents divides total in proportion to weights, in whole paise, handing the paise
+lost to rounding down to the largest remainders (earlier shares win ties), so the parts
+always sum to total.
+*/
+func allocateCents(total float64, weights []float64) []float64 {
+	out := make([]float64, len(weights))
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	totalCents := toCents(total)
+	if sum <= 0 || totalCents == 0 {
+		return out
+	}
+	type rem struct {
+		i    int
+		frac float64
+	}
+	parts := make([]int64, len(weights))
+	rems := make([]rem, len(weights))
+	given := int64(0)
+	for i, w := range weights {
+		exact := float64(totalCents) * w / sum
+		parts[i] = int64(math.Floor(exact))
+		rems[i] = rem{i: i, frac: exact - float64(parts[i])}
+		given += parts[i]
+	}
+	sort.SliceStable(rems, func(a, b int) bool { return rems[a].frac > rems[b].frac })
+	for k := int64(0); k < totalCents-given; k++ {
+		parts[rems[k%int64(len(rems))].i]++
+	}
+	for i, p := range parts {
+		out[i] = float64(p) / 100
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAllocateCents(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		weights []float64
+		want    []float64
+	}{
+		{"even three ways", 100, []float64{1, 1, 1}, []float64{33.34, 33.33, 33.33}},
+		{"in proportion", 10, []float64{3, 1}, []float64{7.5, 2.5}},
+		{"paisa to the largest remainder", 1, []float64{2, 1}, []float64{0.67, 0.33}},
+		{"tie goes to the earlier share", 0.05, []float64{1, 1}, []float64{0.03, 0.02}},
+		{"zero weight gets nothing", 50, []float64{498, 0, 135}, []float64{39.34, 0, 10.66}},
+		{"no weights", 10, []float64{0, 0}, []float64{0, 0}},
+		{"nothing to share", 0, []float64{1, 2}, []float64{0, 0}},
+		{"sub-paisa total", 0.004, []float64{1}, []float64{0}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := allocateCents(tc.total, tc.weights)
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("allocateCents(%v, %v) = %v, want %v", tc.total, tc.weights, got, tc.want)
+			}
+			var sum int64
+			weight := 0.0
+			for i, v := range got {
+				sum += toCents(v)
+				weight += tc.weights[i]
+			}
+			if weight > 0 && sum != toCents(tc.total) {
+				t.Errorf("shares sum to %d paise, want %d", sum, toCents(tc.total))
+			}
+		})
+	}
+}
@@ -0,0 +1,496 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	maxGroupParticipants = 25
+	maxNicknameLength    = 40
+)
+
+// GroupCaller is whoever acts on a group cart: a signed-in user, or a guest with the token they got on join
+type GroupCaller struct {
+	UserID int64
+	Token  string
+}
+
+// GroupCartService runs shared carts: a host opens one for a restaurant, others join by link
+type GroupCartService interface {
+	Create(hostUserID, restaurantID int64, nickname string) (*models.GroupCart, error)
+	Get(joinToken string) (*models.GroupCart, error)
+	// Join returns the cart and the caller's participant; guests keep participant.Token for later calls
+	Join(joinToken string, caller GroupCaller, nickname string) (*models.GroupCart, *models.GroupCartParticipant, error)
+	AddItem(joinToken string, caller GroupCaller, item *models.GroupCartItem) (*models.GroupCart, error)
+	UpdateItem(joinToken string, caller GroupCaller, item *models.GroupCartItem) (*models.GroupCart, error)
+	RemoveItem(joinToken string, caller GroupCaller, itemID int64) (*models.GroupCart, error)
+	Lock(joinToken string, hostUserID int64) (*models.GroupCart, error)
+	Unlock(joinToken string, hostUserID int64) (*models.GroupCart, error)
+	Cancel(joinToken string, hostUserID int64) error
+	Checkout(joinToken string, hostUserID int64, req CartCheckout) (*models.Order, error)
+}
+
+type groupCartService struct {
+	repo     repository.GroupCartRepo
+	restRepo repository.RestaurantRepo
+	menuRepo repository.MenuRepo
+	orders   OrderService
+	db       *sql.DB
+}
+
+func NewGroupCartService(r repository.GroupCartRepo, restRepo repository.RestaurantRepo, menuRepo repository.MenuRepo, orders OrderService, db *sql.DB) GroupCartService {
+	return &groupCartService{repo: r, restRepo: restRepo, menuRepo: menuRepo, orders: orders, db: db}
+}
+
+func (s *groupCartService) Create(hostUserID, restaurantID int64, nickname string) (*models.GroupCart, error) {
+	if hostUserID == 0 {
+		return nil, errors.New("forbidden")
+	}
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if rest == nil {
+		return nil, errors.New("restaurant_not_found")
+	}
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" {
+		nickname = "Host"
+	}
+	if utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return nil, errors.New("invalid_nickname")
+	}
+
+	gc := &models.GroupCart{
+		RestaurantID: restaurantID,
+		HostUserID:   hostUserID,
+		JoinToken:    newSecretToken(),
+		Status:       models.GroupCartOpen,
+	}
+	host := &models.GroupCartParticipant{
+		UserID:   &hostUserID,
+		Nickname: nickname,
+		IsHost:   true,
+		Token:    newSecretToken(),
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(tx, gc, host); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.load(gc)
+}
+
+func (s *groupCartService) Get(joinToken string) (*models.GroupCart, error) {
+	gc, err := s.byToken(joinToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.load(gc)
+}
+
+/*
+Join adds the caller to an OPEN group cart. Signed-in users join once and are recognised by
+their user id afterwards; guests need a nickname and get a participant token back.
+*/
+func (s *groupCartService) Join(joinToken string, caller GroupCaller, nickname string) (*models.GroupCart, *models.GroupCartParticipant, error) {
+	gc, err := s.byToken(joinToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing, err := s.participant(gc, caller); err != nil {
+		return nil, nil, err
+	} else if existing != nil {
+		gc, err = s.load(gc)
+		return gc, existing, err
+	}
+	if gc.Status != models.GroupCartOpen {
+		return nil, nil, errors.New("not_open")
+	}
+
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" || utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return nil, nil, errors.New("invalid_nickname")
+	}
+	participants, err := s.repo.ListParticipants(gc.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(participants) >= maxGroupParticipants {
+		return nil, nil, errors.New("group_full")
+	}
+
+	p := &models.GroupCartParticipant{
+		GroupCartID: gc.ID,
+		Nickname:    nickname,
+		Token:       newSecretToken(),
+	}
+	if caller.UserID != 0 {
+		uid := caller.UserID
+		p.UserID = &uid
+	}
+	if err := s.repo.AddParticipant(p); err != nil {
+		return nil, nil, err
+	}
+	gc, err = s.load(gc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gc, p, nil
+}
+
+// AddItem puts a line in the caller's part of the cart; the same item and options are merged
+func (s *groupCartService) AddItem(joinToken string, caller GroupCaller, item *models.GroupCartItem) (*models.GroupCart, error) {
+	if item.Quantity <= 0 || item.Quantity > maxCartLineQuantity {
+		return nil, errors.New("invalid_quantity")
+	}
+	gc, p, err := s.participantFor(joinToken, caller)
+	if err != nil {
+		return nil, err
+	}
+	menu, err := s.menuRepo.GetMenuItemsByIDs([]int64{item.MenuItemID})
+	if err != nil {
+		return nil, err
+	}
+	if len(menu) == 0 || menu[0].RestaurantID != gc.RestaurantID {
+		return nil, errors.New("not_found")
+	}
+	if menu[0].Availability != models.AvailabilityInStock {
+		return nil, errors.New("item_unavailable")
+	}
+	// picks are checked against the item's modifier groups and stored resolved, so equal picks merge
+	opts, _, reason := resolveOptions(menu[0], item.Options)
+	if reason != "" {
+		return nil, errors.New("invalid_options")
+	}
+	item.Options = opts
+
+	lines, err := s.repo.ListItems(gc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		l := &lines[i]
+		if l.ParticipantID == p.ID && l.MenuItemID == item.MenuItemID && bytes.Equal(l.Options, item.Options) && sameInstructions(l.SpecialInstructions, item.SpecialInstructions) {
+			l.Quantity += item.Quantity
+			if l.Quantity > maxCartLineQuantity {
+				return nil, errors.New("invalid_quantity")
+			}
+			if err := s.repo.UpdateItem(l); err != nil {
+				return nil, mapGroupWriteErr(err)
+			}
+			return s.load(gc)
+		}
+	}
+
+	item.CartID = gc.ID
+	item.ParticipantID = p.ID
+	if err := s.repo.AddItem(item); err != nil {
+		return nil, mapGroupWriteErr(err)
+	}
+	return s.load(gc)
+}
+
+// UpdateItem edits a line; participants edit their own lines, the host any line. Quantity 0 removes it.
+func (s *groupCartService) UpdateItem(joinToken string, caller GroupCaller, item *models.GroupCartItem) (*models.GroupCart, error) {
+	if item.Quantity < 0 || item.Quantity > maxCartLineQuantity {
+		return nil, errors.New("invalid_quantity")
+	}
+	if item.Quantity == 0 {
+		return s.RemoveItem(joinToken, caller, item.ID)
+	}
+	gc, existing, err := s.ownedItem(joinToken, caller, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	existing.Quantity = item.Quantity
+	if item.Options != nil {
+		menu, err := s.menuRepo.GetMenuItemsByIDs([]int64{existing.MenuItemID})
+		if err != nil {
+			return nil, err
+		}
+		if len(menu) == 0 {
+			return nil, errors.New("item_unavailable")
+		}
+		opts, _, reason := resolveOptions(menu[0], item.Options)
+		if reason != "" {
+			return nil, errors.New("invalid_options")
+		}
+		existing.Options = opts
+	}
+	if item.SpecialInstructions != nil {
+		existing.SpecialInstructions = item.SpecialInstructions
+		if strings.TrimSpace(*item.SpecialInstructions) == "" {
+			existing.SpecialInstructions = nil
+		}
+	}
+	if err := s.repo.UpdateItem(existing); err != nil {
+		return nil, mapGroupWriteErr(err)
+	}
+	return s.load(gc)
+}
+
+func (s *groupCartService) RemoveItem(joinToken string, caller GroupCaller, itemID int64) (*models.GroupCart, error) {
+	gc, _, err := s.ownedItem(joinToken, caller, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteItem(gc.ID, itemID); err != nil {
+		return nil, mapGroupWriteErr(err)
+	}
+	return s.load(gc)
+}
+
+// Lock stops changes so the host can review and check out
+func (s *groupCartService) Lock(joinToken string, hostUserID int64) (*models.GroupCart, error) {
+	return s.hostTransition(joinToken, hostUserID, models.GroupCartOpen, models.GroupCartLocked)
+}
+
+// Unlock reopens a locked cart for changes
+func (s *groupCartService) Unlock(joinToken string, hostUserID int64) (*models.GroupCart, error) {
+	return s.hostTransition(joinToken, hostUserID, models.GroupCartLocked, models.GroupCartOpen)
+}
+
+func (s *groupCartService) Cancel(joinToken string, hostUserID int64) error {
+	gc, err := s.hostCart(joinToken, hostUserID)
+	if err != nil {
+		return err
+	}
+	if gc.Status != models.GroupCartOpen && gc.Status != models.GroupCartLocked {
+		return errors.New("invalid_state")
+	}
+	if err := s.repo.SetStatus(nil, gc.ID, gc.Status, models.GroupCartCancelled, nil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("conflict")
+		}
+		return err
+	}
+	return nil
+}
+
+/*
+Checkout turns a LOCKED group cart into one order placed by the host through PlaceOrder.
+Every order line keeps the participant who added it so the bill can be split later.
+The cart is marked CHECKED_OUT in the order transaction.
+*/
+func (s *groupCartService) Checkout(joinToken string, hostUserID int64, req CartCheckout) (*models.Order, error) {
+	gc, err := s.hostCart(joinToken, hostUserID)
+	if err != nil {
+		return nil, err
+	}
+	if gc.Status != models.GroupCartLocked {
+		return nil, errors.New("not_locked")
+	}
+	gc, err = s.load(gc)
+	if err != nil {
+		return nil, err
+	}
+	if len(gc.Items) == 0 {
+		return nil, errors.New("cart_empty")
+	}
+
+	now := time.Now().UTC()
+	order := newCheckoutOrder(gc.HostUserID, gc.RestaurantID, req, now)
+	items := make([]models.OrderItem, 0, len(gc.Items))
+	for _, l := range gc.Items {
+		menuItemID := l.MenuItemID
+		participantID := l.ParticipantID
+		participantName := l.ParticipantName
+		items = append(items, models.OrderItem{
+			MenuItemID:          &menuItemID,
+			Name:                l.Name,
+			Quantity:            l.Quantity,
+			Options:             l.Options,
+			SpecialInstructions: l.SpecialInstructions,
+			ParticipantID:       &participantID,
+			ParticipantName:     &participantName,
+			CreatedAt:           &now,
+		})
+	}
+
+	orderID, err := s.orders.PlaceOrderWith(order, items, func(tx *sql.Tx, orderID int64) error {
+		err := s.repo.SetStatus(tx, gc.ID, models.GroupCartLocked, models.GroupCartCheckedOut, &orderID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// unlocked or checked out by a parallel request
+			return errors.New("conflict")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.ID = orderID
+	order.Items = items
+	return order, nil
+}
+
+/* helpers */
+
+func (s *groupCartService) byToken(joinToken string) (*models.GroupCart, error) {
+	gc, err := s.repo.GetByToken(strings.TrimSpace(joinToken))
+	if err != nil {
+		return nil, err
+	}
+	if gc == nil {
+		return nil, errors.New("not_found")
+	}
+	return gc, nil
+}
+
+// participant finds the caller in the cart, or nil when they have not joined
+func (s *groupCartService) participant(gc *models.GroupCart, caller GroupCaller) (*models.GroupCartParticipant, error) {
+	if caller.UserID != 0 {
+		p, err := s.repo.GetParticipantByUser(gc.ID, caller.UserID)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	if caller.Token != "" {
+		return s.repo.GetParticipantByToken(gc.ID, caller.Token)
+	}
+	return nil, nil
+}
+
+// participantFor loads an OPEN cart and the calling participant
+func (s *groupCartService) participantFor(joinToken string, caller GroupCaller) (*models.GroupCart, *models.GroupCartParticipant, error) {
+	gc, err := s.byToken(joinToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := s.participant(gc, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, nil, errors.New("forbidden")
+	}
+	if gc.Status != models.GroupCartOpen {
+		return nil, nil, errors.New("not_open")
+	}
+	return gc, p, nil
+}
+
+// ownedItem loads a line the caller may change: their own, or any line for the host
+func (s *groupCartService) ownedItem(joinToken string, caller GroupCaller, itemID int64) (*models.GroupCart, *models.GroupCartItem, error) {
+	gc, p, err := s.participantFor(joinToken, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+	item, err := s.repo.GetItem(gc.ID, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, errors.New("item_not_found")
+	}
+	if item.ParticipantID != p.ID && !p.IsHost {
+		return nil, nil, errors.New("forbidden")
+	}
+	return gc, item, nil
+}
+
+func (s *groupCartService) hostCart(joinToken string, hostUserID int64) (*models.GroupCart, error) {
+	gc, err := s.byToken(joinToken)
+	if err != nil {
+		return nil, err
+	}
+	if hostUserID == 0 || gc.HostUserID != hostUserID {
+		return nil, errors.New("forbidden")
+	}
+	return gc, nil
+}
+
+func (s *groupCartService) hostTransition(joinToken string, hostUserID int64, from, to string) (*models.GroupCart, error) {
+	gc, err := s.hostCart(joinToken, hostUserID)
+	if err != nil {
+		return nil, err
+	}
+	if gc.Status != from {
+		return nil, errors.New("invalid_state")
+	}
+	if err := s.repo.SetStatus(nil, gc.ID, from, to, nil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("conflict")
+		}
+		return nil, err
+	}
+	gc.Status = to
+	return s.load(gc)
+}
+
+// load fills participants and re-priced items, with a subtotal per participant
+func (s *groupCartService) load(gc *models.GroupCart) (*models.GroupCart, error) {
+	participants, err := s.repo.ListParticipants(gc.ID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.ListItems(gc.ID)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.CartItem, len(lines))
+	for i := range lines {
+		refs[i] = &lines[i].CartItem
+	}
+	rid := gc.RestaurantID
+	subtotal, ok, err := priceCartLines(s.menuRepo, &rid, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	perParticipant := make(map[int64]float64, len(participants))
+	for _, l := range lines {
+		if l.Available {
+			perParticipant[l.ParticipantID] += l.TotalPrice
+		}
+	}
+	for i := range participants {
+		participants[i].Subtotal = roundMoney(perParticipant[participants[i].ID])
+	}
+
+	gc.Participants = participants
+	gc.Items = lines
+	gc.Subtotal = subtotal
+	gc.Checkout = ok && len(lines) > 0 && gc.Status == models.GroupCartLocked
+	gc.JoinURL = groupJoinURL(gc.JoinToken)
+	return gc, nil
+}
+
+func mapGroupWriteErr(err error) error {
+	if errors.Is(err, repository.ErrGroupCartClosed) {
+		// locked by the host meanwhile (or the line is gone)
+		return errors.New("not_open")
+	}
+	return err
+}
+
+// groupJoinURL is the link the host shares, served by the web app
+func groupJoinURL(joinToken string) string {
+	base := os.Getenv("APP_BASE_URL")
+	if base == "" {
+		base = "https://yourapp.example.com"
+	}
+	return fmt.Sprintf("%s/group/%s", strings.TrimRight(base, "/"), joinToken)
+}
+
+// newSecretToken is an unguessable token for links and guest sessions
+func newSecretToken() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
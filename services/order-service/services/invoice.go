@@ -0,0 +1,193 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+/*
+GetInvoice returns the invoice of a delivered order to its customer, restaurant or
+support. Orders delivered before invoicing existed get theirs issued on first request.
+*/
+func (s *orderService) GetInvoice(orderID int64, tokenUserID int64, role string) (*models.Invoice, error) {
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	switch actor {
+	case ActorCustomer, ActorRestaurant, ActorAdmin:
+	default:
+		return nil, errors.New("forbidden")
+	}
+	if order.OrderStatus != models.OrderStatusDelivered {
+		return nil, errors.New("not_delivered")
+	}
+	inv, err := s.repo.GetInvoiceByOrder(order.ID)
+	if err != nil || inv != nil {
+		return inv, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := time.Now().UTC()
+	if order.DeliveredAt != nil {
+		issuedAt = *order.DeliveredAt
+	}
+	// a concurrent request may have issued it meanwhile; then this takes no number
+	if err := s.issueInvoice(tx, order, issuedAt); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInvoiceByOrder(order.ID)
+}
+
+/*
+issueInvoice numbers and stores the invoice inside tx, and does nothing when the order
+already has one. The order row is locked before looking, so the number is only taken
+when the insert will go through and invoice numbers stay gapless.
+*/
+func (s *orderService) issueInvoice(tx *sql.Tx, order *models.Order, issuedAt time.Time) error {
+	locked, err := s.repo.LockOrder(tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if locked == nil {
+		return errors.New("not_found")
+	}
+	issued, err := s.repo.HasInvoice(tx, order.ID)
+	if err != nil || issued {
+		return err
+	}
+	items := order.Items
+	if items == nil {
+		loaded, err := s.repo.GetItemsForOrders([]int64{order.ID})
+		if err != nil {
+			return err
+		}
+		items = loaded[order.ID]
+	}
+	rest, err := s.restRepo.GetByID(order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	inv := buildInvoice(order, items, rest, issuedAt)
+	seq, err := s.repo.NextInvoiceSequence(tx, order.RestaurantID, inv.FinancialYear)
+	if err != nil {
+		return err
+	}
+	inv.InvoiceNumber = formatInvoiceNumber(order.RestaurantID, inv.FinancialYear, seq)
+	return s.repo.InsertInvoice(tx, inv)
+}
+
+/*
+buildInvoice lays out what the invoice shows. Lines carry the GST breakdown they were
+priced with; lines priced before the tax engine show no tax. The order discount is
+shown per line as the difference between amount and taxable value.
+*/
+func buildInvoice(order *models.Order, items []models.OrderItem, rest *models.Restaurant, issuedAt time.Time) *models.Invoice {
+	inv := &models.Invoice{
+		OrderID:       order.ID,
+		RestaurantID:  order.RestaurantID,
+		FinancialYear: financialYear(issuedAt),
+		Kind:          models.InvoiceKindReceipt,
+		IssuedAt:      issuedAt,
+		OrderNumber:   order.OrderNumber,
+		OrderType:     order.OrderType,
+		OrderedAt:     order.CreatedAt,
+		Supplier: models.InvoiceParty{
+			Name:         rest.Name,
+			AddressLine1: rest.AddressLine1,
+			AddressLine2: rest.AddressLine2,
+			City:         rest.City,
+			State:        rest.State,
+			Pincode:      rest.Pincode,
+		},
+		Customer: models.InvoiceParty{
+			UserID:       &order.UserID,
+			AddressLine1: order.DeliveryAddress,
+			State:        order.DeliveryState,
+		},
+		Lines: make([]models.InvoiceLine, 0, len(items)),
+	}
+	// the registration in force when the order was placed
+	if order.RestaurantGSTIN != nil {
+		inv.Supplier.GSTIN = *order.RestaurantGSTIN
+	}
+	if inv.Supplier.GSTIN != "" {
+		inv.Kind = models.InvoiceKindTax
+	}
+	inv.PlaceOfSupply = strings.TrimSpace(order.DeliveryState)
+	if inv.PlaceOfSupply == "" || !isDeliveryOrder(order) {
+		inv.PlaceOfSupply = rest.State
+	}
+
+	t := &inv.Totals
+	for _, it := range items {
+		line := models.InvoiceLine{
+			Name:          it.Name,
+			Quantity:      it.Quantity,
+			UnitPrice:     it.UnitPrice,
+			Amount:        it.TotalPrice,
+			TaxableAmount: it.TotalPrice,
+		}
+		if it.Tax != nil {
+			line.TaxableAmount = it.Tax.TaxableAmount
+			line.TaxLabel = it.Tax.Label
+			line.RatePercent = it.Tax.RatePercent
+			line.CGSTAmount = it.Tax.CGSTAmount
+			line.SGSTAmount = it.Tax.SGSTAmount
+			line.IGSTAmount = it.Tax.IGSTAmount
+			if line.IGSTAmount > 0 {
+				inv.InterState = true
+			}
+		}
+		line.Discount = roundMoney(line.Amount - line.TaxableAmount)
+		inv.Lines = append(inv.Lines, line)
+
+		t.Subtotal += line.Amount
+		t.TaxableAmount += line.TaxableAmount
+		t.CGSTAmount += line.CGSTAmount
+		t.SGSTAmount += line.SGSTAmount
+		t.IGSTAmount += line.IGSTAmount
+	}
+	t.Subtotal = roundMoney(t.Subtotal)
+	t.Discount = roundMoney(order.DiscountAmount)
+	t.TaxableAmount = roundMoney(t.TaxableAmount)
+	t.CGSTAmount = roundMoney(t.CGSTAmount)
+	t.SGSTAmount = roundMoney(t.SGSTAmount)
+	t.IGSTAmount = roundMoney(t.IGSTAmount)
+	t.TaxAmount = roundMoney(order.TaxAmount)
+	t.DeliveryFee = roundMoney(order.DeliveryFee)
+	t.Tip = roundMoney(order.TipAmount)
+	t.Total = roundMoney(order.TotalAmount)
+	return inv
+}
+
+// financialYear is the Indian financial year (April to March) of t, e.g. "2526"
+func financialYear(t time.Time) string {
+	local := t.In(businesstime.Location)
+	start := local.Year()
+	if local.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%02d%02d", start%100, (start+1)%100)
+}
+
+// formatInvoiceNumber renders I42-2526-00031; it stays within GST's 16 characters up to restaurant 9999
+func formatInvoiceNumber(restaurantID int64, financialYear string, seq int) string {
+	return fmt.Sprintf("I%d-%s-%05d", restaurantID, financialYear, seq)
+}
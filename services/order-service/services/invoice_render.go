@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/utils"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+var invoiceHTML = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money":   func(v float64) string { return fmt.Sprintf("₹%.2f", v) },
+	"rate":    formatRate,
+	"date":    invoiceDate,
+	"title":   invoiceTitle,
+	"address": invoiceAddress,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{title .}} {{.InvoiceNumber}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; color: #222; margin: 32px; }
+h1 { font-size: 20px; margin: 0 0 16px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 6px 8px; text-align: left; vertical-align: top; }
+.lines th { border-bottom: 1px solid #444; }
+.lines td { border-bottom: 1px solid #ddd; }
+.num { text-align: right; white-space: nowrap; }
+.parties td { width: 50%; padding: 0 0 16px; }
+.totals { width: 320px; margin-left: auto; margin-top: 16px; }
+.totals .grand td { border-top: 1px solid #444; font-weight: bold; }
+.muted { color: #666; }
+</style>
+</head>
+<body>
+<h1>{{title .}}</h1>
+<table class="parties">
+<tr>
+<td>
+<strong>{{.Supplier.Name}}</strong><br>
+{{range address .Supplier}}{{.}}<br>{{end}}
+{{if .Supplier.GSTIN}}GSTIN: {{.Supplier.GSTIN}}<br>{{end}}
+</td>
+<td>
+Invoice no: <strong>{{.InvoiceNumber}}</strong><br>
+Date: {{date .IssuedAt}}<br>
+Order: {{.OrderNumber}}{{if .OrderType}} ({{.OrderType}}){{end}}<br>
+{{if .PlaceOfSupply}}Place of supply: {{.PlaceOfSupply}}<br>{{end}}
+</td>
+</tr>
+{{if address .Customer}}
+<tr>
+<td colspan="2">
+<span class="muted">Billed to</span><br>
+{{range address .Customer}}{{.}}<br>{{end}}
+</td>
+</tr>
+{{end}}
+</table>
+<table class="lines">
+<tr>
+<th>Item</th><th class="num">Qty</th><th class="num">Rate</th><th class="num">Amount</th><th class="num">Discount</th><th class="num">Taxable</th><th class="num">GST</th>
+{{if .InterState}}<th class="num">IGST</th>{{else}}<th class="num">CGST</th><th class="num">SGST</th>{{end}}
+</tr>
+{{range .Lines}}
+<tr>
+<td>{{.Name}}{{if .TaxLabel}}<br><span class="muted">{{.TaxLabel}}</span>{{end}}</td>
+<td class="num">{{.Quantity}}</td>
+<td class="num">{{money .UnitPrice}}</td>
+<td class="num">{{money .Amount}}</td>
+<td class="num">{{money .Discount}}</td>
+<td class="num">{{money .TaxableAmount}}</td>
+<td class="num">{{rate .RatePercent}}</td>
+{{if $.InterState}}<td class="num">{{money .IGSTAmount}}</td>{{else}}<td class="num">{{money .CGSTAmount}}</td><td class="num">{{money .SGSTAmount}}</td>{{end}}
+</tr>
+{{end}}
+</table>
+<table class="totals">
+{{with .Totals}}
+<tr><td>Subtotal</td><td class="num">{{money .Subtotal}}</td></tr>
+{{if .Discount}}<tr><td>Discount</td><td class="num">-{{money .Discount}}</td></tr>{{end}}
+<tr><td>Taxable value</td><td class="num">{{money .TaxableAmount}}</td></tr>
+{{if $.InterState}}<tr><td>IGST</td><td class="num">{{money .IGSTAmount}}</td></tr>{{else}}<tr><td>CGST</td><td class="num">{{money .CGSTAmount}}</td></tr>
+<tr><td>SGST</td><td class="num">{{money .SGSTAmount}}</td></tr>{{end}}
+{{if .DeliveryFee}}<tr><td>Delivery fee</td><td class="num">{{money .DeliveryFee}}</td></tr>{{end}}
+{{if .Tip}}<tr><td>Tip</td><td class="num">{{money .Tip}}</td></tr>{{end}}
+<tr class="grand"><td>Total</td><td class="num">{{money .Total}}</td></tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// RenderInvoiceHTML renders the invoice as a standalone printable page
+func RenderInvoiceHTML(inv *models.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceHTML.Execute(&buf, inv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+RenderInvoicePDF lays out the same invoice as an A4 PDF. The built-in PDF fonts have no
+rupee sign, so amounts are printed as "Rs.". Long item names are cut to their column and
+lines that do not fit continue on a new page.
+*/
+func RenderInvoicePDF(inv *models.Invoice) []byte {
+	const (
+		margin = 40.0
+		body   = 8.5
+		row    = 14.0
+	)
+	right := utils.PDFPageWidth - margin
+	money := func(v float64) string { return fmt.Sprintf("Rs. %.2f", v) }
+	doc := utils.NewPDF()
+	y := utils.PDFPageHeight - margin - 16
+
+	doc.Text(margin, y, 16, true, invoiceTitle(inv))
+	y -= 28
+	top := y
+	doc.Text(margin, y, 11, true, inv.Supplier.Name)
+	y -= row
+	for _, l := range invoiceAddress(inv.Supplier) {
+		doc.Text(margin, y, body, false, l)
+		y -= row
+	}
+	if inv.Supplier.GSTIN != "" {
+		doc.Text(margin, y, body, false, "GSTIN: "+inv.Supplier.GSTIN)
+		y -= row
+	}
+	meta := []string{
+		"Invoice no: " + inv.InvoiceNumber,
+		"Date: " + invoiceDate(inv.IssuedAt),
+		"Order: " + inv.OrderNumber,
+	}
+	if inv.PlaceOfSupply != "" {
+		meta = append(meta, "Place of supply: "+inv.PlaceOfSupply)
+	}
+	my := top
+	for _, m := range meta {
+		doc.TextRight(right, my, body, false, m)
+		my -= row
+	}
+	if my < y {
+		y = my
+	}
+	if customer := invoiceAddress(inv.Customer); len(customer) > 0 {
+		y -= 6
+		doc.Text(margin, y, body, true, "Billed to")
+		y -= row
+		for _, l := range customer {
+			doc.Text(margin, y, body, false, l)
+			y -= row
+		}
+	}
+	y -= 10
+
+	// columns: the name on the left, numbers right-aligned at each edge
+	headers := []string{"Qty", "Rate", "Amount", "Discount", "Taxable", "GST", "IGST"}
+	edges := []float64{260, 315, 370, 425, 480, 505, right}
+	if !inv.InterState {
+		headers = []string{"Qty", "Rate", "Amount", "Discount", "Taxable", "GST", "CGST", "SGST"}
+		edges = []float64{200, 255, 310, 365, 420, 450, 503, right}
+	}
+	nameWidth := edges[0] - 25 - margin
+	header := func() {
+		doc.Text(margin, y, body, true, "Item")
+		for i, h := range headers {
+			doc.TextRight(edges[i], y, body, true, h)
+		}
+		y -= 5
+		doc.Line(margin, y, right, y, 0.8)
+		y -= row
+	}
+	header()
+	for _, l := range inv.Lines {
+		if y < margin+row {
+			doc.AddPage()
+			y = utils.PDFPageHeight - margin
+			header()
+		}
+		cells := []string{
+			fmt.Sprintf("%d", l.Quantity), money(l.UnitPrice), money(l.Amount), money(l.Discount),
+			money(l.TaxableAmount), formatRate(l.RatePercent),
+		}
+		if inv.InterState {
+			cells = append(cells, money(l.IGSTAmount))
+		} else {
+			cells = append(cells, money(l.CGSTAmount), money(l.SGSTAmount))
+		}
+		doc.Text(margin, y, body, false, utils.PDFClip(l.Name, nameWidth, body, false))
+		for i, v := range cells {
+			doc.TextRight(edges[i], y, body, false, v)
+		}
+		y -= row
+	}
+	doc.Line(margin, y+row-4, right, y+row-4, 0.4)
+
+	t := inv.Totals
+	totals := [][2]string{{"Subtotal", money(t.Subtotal)}}
+	if t.Discount > 0 {
+		totals = append(totals, [2]string{"Discount", "-" + money(t.Discount)})
+	}
+	totals = append(totals, [2]string{"Taxable value", money(t.TaxableAmount)})
+	if inv.InterState {
+		totals = append(totals, [2]string{"IGST", money(t.IGSTAmount)})
+	} else {
+		totals = append(totals, [2]string{"CGST", money(t.CGSTAmount)}, [2]string{"SGST", money(t.SGSTAmount)})
+	}
+	if t.DeliveryFee > 0 {
+		totals = append(totals, [2]string{"Delivery fee", money(t.DeliveryFee)})
+	}
+	if t.Tip > 0 {
+		totals = append(totals, [2]string{"Tip", money(t.Tip)})
+	}
+	if y < margin+row*float64(len(totals)+2) {
+		doc.AddPage()
+		y = utils.PDFPageHeight - margin
+	}
+	y -= 6
+	labelX := right - 180
+	for _, r := range totals {
+		doc.Text(labelX, y, body, false, r[0])
+		doc.TextRight(right, y, body, false, r[1])
+		y -= row
+	}
+	doc.Line(labelX, y+row-4, right, y+row-4, 0.8)
+	doc.Text(labelX, y-2, 10, true, "Total")
+	doc.TextRight(right, y-2, 10, true, money(t.Total))
+	return doc.Bytes()
+}
+
+func invoiceTitle(inv *models.Invoice) string {
+	if inv.Kind == models.InvoiceKindTax {
+		return "Tax Invoice"
+	}
+	return "Receipt"
+}
+
+func invoiceDate(t time.Time) string {
+	return t.In(businesstime.Location).Format("02 Jan 2006")
+}
+
+// invoiceAddress is the party's postal address as printed lines, blanks left out
+func invoiceAddress(p models.InvoiceParty) []string {
+	var lines []string
+	for _, l := range []string{p.AddressLine1, p.AddressLine2} {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	var last []string
+	for _, l := range []string{p.City, p.State, p.Pincode} {
+		if l = strings.TrimSpace(l); l != "" {
+			last = append(last, l)
+		}
+	}
+	if len(last) > 0 {
+		lines = append(lines, strings.Join(last, ", "))
+	}
+	return lines
+}
+
+// formatRate prints a GST rate without trailing zeros: 5%, 2.5%
+func formatRate(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".") + "%"
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+/*
+resolveOptions checks a line's modifier selections against the menu item and returns
+them in the form orders store, with names and prices filled in and groups and options in
+menu order, plus what they add to the unit price. A non-empty reason says what is wrong
+with the selection. Groups left out count as nothing picked, so required groups must be
+there; items without modifier groups take no options.
+*/
+func resolveOptions(m models.MenuItem, raw json.RawMessage) (json.RawMessage, float64, string) {
+	var picked []models.ModifierSelection
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) && !bytes.Equal(trimmed, []byte("{}")) {
+		if err := json.Unmarshal(trimmed, &picked); err != nil {
+			return nil, 0, "options must be a list of {group_id, option_ids}"
+		}
+	}
+	if len(picked) > 0 && len(m.ModifierGroups) == 0 {
+		return nil, 0, "item has no options"
+	}
+
+	groupAt := make(map[int64]int, len(m.ModifierGroups))
+	for i, g := range m.ModifierGroups {
+		groupAt[g.ID] = i
+	}
+	chosen := make([][]int, len(m.ModifierGroups)) // option indexes per group
+	seen := make([]bool, len(m.ModifierGroups))
+	for _, sel := range picked {
+		gi, ok := groupAt[sel.GroupID]
+		if !ok {
+			return nil, 0, fmt.Sprintf("unknown option group %d", sel.GroupID)
+		}
+		g := m.ModifierGroups[gi]
+		if seen[gi] {
+			return nil, 0, fmt.Sprintf("%q is listed twice", g.Name)
+		}
+		seen[gi] = true
+		for _, id := range sel.OptionIDs {
+			oi := -1
+			for j, o := range g.Options {
+				if o.ID == id {
+					oi = j
+					break
+				}
+			}
+			if oi < 0 {
+				return nil, 0, fmt.Sprintf("option %d is not part of %q", id, g.Name)
+			}
+			if !g.Options[oi].IsAvailable {
+				return nil, 0, fmt.Sprintf("%q is not available", g.Options[oi].Name)
+			}
+			for _, prev := range chosen[gi] {
+				if prev == oi {
+					return nil, 0, fmt.Sprintf("%q is picked twice", g.Options[oi].Name)
+				}
+			}
+			chosen[gi] = append(chosen[gi], oi)
+		}
+	}
+
+	var out []models.ModifierSelection
+	delta := 0.0
+	for gi, g := range m.ModifierGroups {
+		n := len(chosen[gi])
+		if n < g.MinSelect {
+			return nil, 0, fmt.Sprintf("%q needs at least %d choice(s)", g.Name, g.MinSelect)
+		}
+		if n > g.MaxSelect {
+			return nil, 0, fmt.Sprintf("%q allows at most %d choice(s)", g.Name, g.MaxSelect)
+		}
+		if n == 0 {
+			continue
+		}
+		sort.Ints(chosen[gi])
+		sel := models.ModifierSelection{GroupID: g.ID, Group: g.Name}
+		for _, oi := range chosen[gi] {
+			o := g.Options[oi]
+			sel.OptionIDs = append(sel.OptionIDs, o.ID)
+			sel.Options = append(sel.Options, models.SelectedModifier{ID: o.ID, Name: o.Name, PriceDelta: o.PriceDelta})
+			delta += o.PriceDelta
+		}
+		out = append(out, sel)
+	}
+	if len(out) == 0 {
+		return nil, 0, ""
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, 0, err.Error()
+	}
+	return b, roundMoney(delta), ""
+}
+
+/*
+servedAt reports whether a menu item is served at t: inside one of its own windows and
+one of its category's. Either list being empty means no limit from that side.
+*/
+func servedAt(m models.MenuItem, t time.Time) bool {
+	return inMenuWindows(m.Windows, t) && inMenuWindows(m.CategoryWindows, t)
+}
+
+func inMenuWindows(windows []models.MenuWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if businesstime.InWeeklyWindow(w.Weekday, w.StartTime, w.EndTime, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+type OrderService interface {
+	PlaceOrder(order *models.Order, items []models.OrderItem) (int64, error)
+	// PlaceOrderWith is PlaceOrder running inTx inside the order transaction before commit
+	PlaceOrderWith(order *models.Order, items []models.OrderItem, inTx func(tx *sql.Tx, orderID int64) error) (int64, error)
+	GetOrderStatus(orderID int64) (string, error)
+	UpdateOrderStatus(orderID int64, status, reason string, tokenUserID int64, role string) error
+	// GetOrder is the order with its items for anyone involved in it
+	GetOrder(orderID int64, tokenUserID int64, role string) (*models.Order, error)
+	GetStatusHistory(orderID int64, tokenUserID int64, role string) ([]models.OrderStatusHistory, error)
+	FindOrdersByNumber(number string, tokenUserID int64, role string) ([]models.Order, error)
+	ListCustomerOrders(params repository.ListOrdersParams, cursor string, tokenUserID int64) ([]models.Order, string, error)
+	ListRestaurantOrders(restaurantID int64, params repository.ListOrdersParams, cursor string, tokenUserID int64, role string) ([]models.Order, string, error)
+
+	// live streams
+	AuthorizeRestaurantStaff(restaurantID int64, tokenUserID int64, role string) error
+	RestaurantEventsSince(restaurantID, afterSeq int64) ([]models.OrderEvent, error)
+
+	// delivery tracking
+	AssignRider(orderID int64, riderID *int64, tokenUserID int64, role string) (*models.Order, error)
+	UpdateRiderLocation(orderID int64, lat, lon float64, tokenUserID int64, role string) error
+	AuthorizeOrderTracking(orderID int64, tokenUserID int64) (*models.Order, error)
+	OrderEventsSince(orderID, afterSeq int64) ([]models.OrderEvent, error)
+
+	// cancellation
+	CancelOrder(orderID int64, reasonCode, note string, tokenUserID int64, role string) (*models.Order, error)
+	RetryPendingRefunds() error
+
+	// scheduled orders
+	ReleaseScheduledOrders() error
+
+	// amendments before the restaurant confirms
+	AmendOrder(orderID int64, lines []OrderAmendLine, tokenUserID int64, role string) (*models.Order, *models.OrderAmendment, error)
+	ListAmendments(orderID int64, tokenUserID int64, role string) ([]models.OrderAmendment, error)
+
+	// ReorderSource is a past order with its lines, for the customer who placed it
+	ReorderSource(orderID int64, tokenUserID int64) (*models.Order, error)
+
+	// GetInvoice is the invoice of a delivered order
+	GetInvoice(orderID int64, tokenUserID int64, role string) (*models.Invoice, error)
+
+	// PreviewOrder prices an order like PlaceOrder without placing it
+	PreviewOrder(order *models.Order, items []models.OrderItem) error
+}
+
+type orderService struct {
+	repo     repository.OrderRepo
+	restRepo repository.RestaurantRepo
+	menuRepo repository.MenuRepo
+	events   repository.OrderEventRepo
+	dining   repository.DiningSessionRepo
+	taxes    repository.TaxRepo
+	promos   repository.PromotionRepo
+	fees     repository.DeliveryFeeRepo
+	payments clients.PaymentClient
+	users    clients.UserClient
+	db       *sql.DB
+}
+
+func NewOrderService(r repository.OrderRepo, restRepo repository.RestaurantRepo, menuRepo repository.MenuRepo, events repository.OrderEventRepo, dining repository.DiningSessionRepo, taxes repository.TaxRepo, promos repository.PromotionRepo, fees repository.DeliveryFeeRepo, payments clients.PaymentClient, users clients.UserClient, db *sql.DB) OrderService {
+	return &orderService{repo: r, restRepo: restRepo, menuRepo: menuRepo, events: events, dining: dining, taxes: taxes, promos: promos, fees: fees, payments: payments, users: users, db: db}
+}
+
+func (s *orderService) PlaceOrder(order *models.Order, items []models.OrderItem) (int64, error) {
+	return s.PlaceOrderWith(order, items, nil)
+}
+
+func (s *orderService) PlaceOrderWith(order *models.Order, items []models.OrderItem, inTx func(tx *sql.Tx, orderID int64) error) (int64, error) {
+	if order == nil || len(items) == 0 {
+		return 0, errors.New("order and items required")
+	}
+	// never trust client prices
+	if err := s.priceOrder(order, items, false); err != nil {
+		return 0, err
+	}
+	if order.DiningSessionID != nil {
+		// a round at the table: no scheduling, always DINE_IN
+		verr := &OrderValidationError{}
+		if order.ScheduledFor != nil {
+			verr.add(-1, nil, "scheduledFor", "dine-in orders cannot be scheduled")
+		}
+		if order.OrderType != "" && !strings.EqualFold(order.OrderType, "DINE_IN") {
+			verr.add(-1, nil, "orderType", "must be DINE_IN for a dining session")
+		}
+		if len(verr.Problems) > 0 {
+			return 0, verr
+		}
+		order.OrderType = "DINE_IN"
+	}
+	if order.ScheduledFor != nil {
+		verr := &OrderValidationError{}
+		if err := s.scheduleOrder(order, items, time.Now().UTC(), verr); err != nil {
+			return 0, err
+		}
+		if len(verr.Problems) > 0 {
+			return 0, verr
+		}
+	}
+	// first ETA, stored with the order and as its first prediction
+	eta, err := s.estimateETA(order, items, order.OrderStatus, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if eta != nil {
+		order.EstimatedDeliveryAt = &eta.EstimatedDeliveryTime
+	}
+	// create tx
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// ensure rollback on panic
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if order.DiningSessionID != nil {
+		// the share lock keeps the session from being closed until this order is in
+		if err := s.checkDiningSession(tx, order); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	order.CreatedAt = timePtr(time.Now().UTC())
+	order.UpdatedAt = timePtr(time.Now().UTC())
+
+	day := businesstime.Day(time.Now())
+	seq, err := s.repo.NextOrderSequence(tx, order.RestaurantID, day)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	order.OrderNumber = formatOrderNumber(order.RestaurantID, day, seq)
+
+	// the quoted fee prices this order only
+	if err := s.lockDeliveryFee(tx, order); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	orderID, err := s.repo.CreateOrderWithItems(tx, order, items)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	// first timeline entry
+	if err := s.repo.InsertStatusHistory(tx, &models.OrderStatusHistory{
+		OrderID:     orderID,
+		ToStatus:    order.OrderStatus,
+		ActorUserID: &order.UserID,
+		ActorRole:   ActorCustomer,
+	}); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if eta != nil {
+		eta.OrderID = orderID
+		if err := s.repo.InsertETAPrediction(tx, eta); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	order.ID = orderID
+	order.Items = items
+	// the discount and its redemption commit together or not at all
+	if err := s.redeemPromotion(tx, order); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	eventType := models.OrderEventPlaced
+	if order.OrderStatus == models.OrderStatusScheduled {
+		// the kitchen gets ORDER_PLACED when the scheduler releases it
+		eventType = models.OrderEventScheduled
+	}
+	if err := s.recordEvent(tx, order, eventType, order); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if inTx != nil {
+		if err := inTx(tx, orderID); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return orderID, nil
+}
+
+func (s *orderService) PreviewOrder(order *models.Order, items []models.OrderItem) error {
+	if order == nil || len(items) == 0 {
+		return errors.New("order and items required")
+	}
+	return s.priceOrder(order, items, true)
+}
+
+func (s *orderService) GetOrderStatus(orderID int64) (string, error) {
+	return s.repo.GetOrderStatus(orderID)
+}
+
+func (s *orderService) UpdateOrderStatus(orderID int64, status, reason string, tokenUserID int64, role string) error {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status == "" {
+		return errors.New("status required")
+	}
+	if !isKnownOrderStatus(status) {
+		return errors.New("invalid_status")
+	}
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return err
+	}
+	if actor == "" {
+		return errors.New("forbidden")
+	}
+	if status == models.OrderStatusCancelled {
+		// same rules and refund handling as POST /orders/:id/cancel
+		_, err := s.cancel(order, actor, models.CancelReasonOther, reason, tokenUserID)
+		return err
+	}
+	if res := checkOrderTransition(order.OrderStatus, status, actor); res != "" {
+		return errors.New(res)
+	}
+	return s.transition(order, status, reason, actor, tokenUserID)
+}
+
+func (s *orderService) GetOrder(orderID int64, tokenUserID int64, role string) (*models.Order, error) {
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	if actor == "" {
+		return nil, errors.New("forbidden")
+	}
+	items, err := s.repo.GetItemsForOrders([]int64{orderID})
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items[orderID]
+	return order, nil
+}
+
+func (s *orderService) GetStatusHistory(orderID int64, tokenUserID int64, role string) ([]models.OrderStatusHistory, error) {
+	_, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	// riders only see what they are handed; the timeline is for customer, restaurant and support
+	switch actor {
+	case ActorCustomer, ActorRestaurant, ActorAdmin:
+	default:
+		return nil, errors.New("forbidden")
+	}
+	return s.repo.GetStatusHistory(orderID)
+}
+
+// FindOrdersByNumber returns the orders with that number which the caller is allowed to see
+func (s *orderService) FindOrdersByNumber(number string, tokenUserID int64, role string) ([]models.Order, error) {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return nil, errors.New("number required")
+	}
+	orders, err := s.repo.FindOrdersByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Order, 0, len(orders))
+	for i := range orders {
+		rest, err := s.restRepo.GetByID(orders[i].RestaurantID)
+		if err != nil {
+			return nil, err
+		}
+		switch resolveOrderActor(&orders[i], rest, tokenUserID, role) {
+		case ActorCustomer, ActorRestaurant, ActorAdmin:
+			out = append(out, orders[i])
+		}
+	}
+	return out, nil
+}
+
+/* helpers */
+
+// loadWithActor fetches the order and resolves in which capacity the caller acts on it
+func (s *orderService) loadWithActor(orderID int64, tokenUserID int64, role string) (*models.Order, string, error) {
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	if order == nil {
+		return nil, "", errors.New("not_found")
+	}
+	rest, err := s.restRepo.GetByID(order.RestaurantID)
+	if err != nil {
+		return nil, "", err
+	}
+	return order, resolveOrderActor(order, rest, tokenUserID, role), nil
+}
+
+// transition writes the new status and its history row in one transaction
+func (s *orderService) transition(order *models.Order, status, reason, actor string, tokenUserID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := s.applyTransition(tx, order, status, reason, actor, tokenUserID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyTransition is transition inside a caller-owned tx; the caller rolls back on error
+func (s *orderService) applyTransition(tx *sql.Tx, order *models.Order, status, reason, actor string, tokenUserID int64) error {
+	if err := s.repo.UpdateOrderStatus(tx, order.ID, order.OrderStatus, status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// status moved underneath us
+			return errors.New("conflict")
+		}
+		return err
+	}
+	h := &models.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: order.OrderStatus,
+		ToStatus:   status,
+		ActorRole:  actor,
+	}
+	if tokenUserID != 0 {
+		h.ActorUserID = &tokenUserID
+	}
+	if r := strings.TrimSpace(reason); r != "" {
+		h.Reason = &r
+	}
+	if err := s.repo.InsertStatusHistory(tx, h); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	eta, err := s.refreshETA(tx, order, status, now)
+	if err != nil {
+		return err
+	}
+	if status == models.OrderStatusDelivered {
+		if err := s.issueInvoice(tx, order, now); err != nil {
+			return err
+		}
+	}
+	return s.recordEvent(tx, order, models.OrderEventStatusChanged, map[string]interface{}{
+		"order_number":            order.OrderNumber,
+		"from_status":             h.FromStatus,
+		"to_status":               h.ToStatus,
+		"actor_role":              h.ActorRole,
+		"reason":                  h.Reason,
+		"estimated_delivery_time": eta,
+	})
+}
+
+// recordEvent appends to the order event log inside tx; live streams pick it up after commit
+func (s *orderService) recordEvent(tx *sql.Tx, order *models.Order, eventType string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.events.Insert(tx, &models.OrderEvent{
+		RestaurantID: order.RestaurantID,
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		Type:         eventType,
+		Payload:      raw,
+	})
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// OrderAmendLine is one change to an order: ItemID changes an existing line (Quantity 0 removes it),
+// MenuItemID adds a new one
+type OrderAmendLine struct {
+	ItemID              *int64
+	MenuItemID          *int64
+	Quantity            int
+	Options             json.RawMessage // nil keeps the line's options
+	SpecialInstructions *string         // nil keeps the line's instructions
+}
+
+/*
+AmendOrder changes the lines of an order the restaurant has not confirmed yet. The basket
+is re-priced like a new order, except that lines the amendment leaves alone keep the price
+they were ordered at, and the change is stored with before/after snapshots. The order is
+locked while this happens, so concurrent amendments and cancellations each start from
+what the one before them left. For paid orders the difference is charged or refunded
+through payment-service.
+*/
+func (s *orderService) AmendOrder(orderID int64, lines []OrderAmendLine, tokenUserID int64, role string) (*models.Order, *models.OrderAmendment, error) {
+	if len(lines) == 0 {
+		return nil, nil, errors.New("no_changes")
+	}
+	_, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actor != ActorCustomer && actor != ActorAdmin {
+		return nil, nil, errors.New("forbidden")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	amended, a, err := s.amend(tx, orderID, lines, actor, tokenUserID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	if a.PaymentAction != models.AmendPaymentNone {
+		a.PaymentReference = amendmentPaymentReference(a)
+		// best effort now; RetryPendingRefunds picks it up if payment-service is unreachable
+		if err := s.requestAmendmentPayment(amended, a); err != nil {
+			log.Printf("amendment %d of order %d: payment hand-off failed, will retry: %v", a.ID, orderID, err)
+		}
+	}
+	return amended, a, nil
+}
+
+// amend re-prices and stores the amended order inside tx, working from the locked row
+func (s *orderService) amend(tx *sql.Tx, orderID int64, lines []OrderAmendLine, actor string, tokenUserID int64) (*models.Order, *models.OrderAmendment, error) {
+	order, err := s.repo.LockOrder(tx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order == nil {
+		return nil, nil, errors.New("not_found")
+	}
+	if order.OrderStatus != models.OrderStatusPlaced {
+		return nil, nil, errors.New("amend_window_closed")
+	}
+	current, err := s.repo.GetOrderItems(tx, order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := orderSnapshot(order, current)
+
+	kept, removed, added, err := applyAmendLines(current, lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(kept)+len(added) == 0 {
+		// that is a cancellation, with its own reasons and rules
+		return nil, nil, errors.New("order_empty")
+	}
+	basket := append(kept, added...)
+	for i := range basket {
+		if !basket[i].PriceLocked {
+			basket[i].UnitPrice = 0
+		}
+		basket[i].TotalPrice = 0
+		basket[i].Tax = nil
+	}
+	amended := *order
+	amended.SubtotalAmount = 0
+	amended.TaxAmount = 0
+	amended.TotalAmount = 0
+	if err := s.priceOrder(&amended, basket, false); err != nil {
+		return nil, nil, err
+	}
+
+	a := &models.OrderAmendment{
+		OrderID:         order.ID,
+		ActorRole:       actor,
+		Before:          before,
+		PriceDifference: roundMoney(amended.TotalAmount - order.TotalAmount),
+		PaymentAction:   models.AmendPaymentNone,
+	}
+	if tokenUserID != 0 {
+		a.ActorUserID = &tokenUserID
+	}
+	if order.PaymentStatus == models.PaymentStatusPaid {
+		switch {
+		case a.PriceDifference > 0:
+			a.PaymentAction = models.AmendPaymentCharge
+		case a.PriceDifference < 0:
+			a.PaymentAction = models.AmendPaymentRefund
+		}
+	}
+
+	if err := s.repo.UpdateAmounts(tx, &amended, models.OrderStatusPlaced); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, errors.New("amend_window_closed")
+		}
+		return nil, nil, err
+	}
+	if order.PromotionID != nil {
+		if err := s.promos.UpdateRedemption(tx, order.ID, amended.DiscountAmount); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := s.repo.ReplaceItems(tx, order.ID, basket[:len(kept)], removed, basket[len(kept):]); err != nil {
+		return nil, nil, err
+	}
+	a.After = orderSnapshot(&amended, basket)
+	if err := s.repo.InsertAmendment(tx, a); err != nil {
+		return nil, nil, err
+	}
+	amended.Items = basket
+	if err := s.recordEvent(tx, &amended, models.OrderEventAmended, map[string]interface{}{
+		"order":            amended,
+		"amendment_id":     a.ID,
+		"price_difference": a.PriceDifference,
+	}); err != nil {
+		return nil, nil, err
+	}
+	return &amended, a, nil
+}
+
+// ListAmendments is the amendment log of an order for its customer, restaurant and support
+func (s *orderService) ListAmendments(orderID int64, tokenUserID int64, role string) ([]models.OrderAmendment, error) {
+	_, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	switch actor {
+	case ActorCustomer, ActorRestaurant, ActorAdmin:
+	default:
+		return nil, errors.New("forbidden")
+	}
+	list, err := s.repo.ListAmendments(orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].PaymentAction != models.AmendPaymentNone {
+			list[i].PaymentReference = amendmentPaymentReference(&list[i])
+		}
+	}
+	return list, nil
+}
+
+/*
+applyAmendLines works out which current lines stay (with their new values), go, or are new.
+Kept lines no amend line names are PriceLocked.
+*/
+func applyAmendLines(current []models.OrderItem, lines []OrderAmendLine) (kept []models.OrderItem, removed []int64, added []models.OrderItem, err error) {
+	byID := make(map[int64]int, len(current))
+	next := make([]models.OrderItem, len(current))
+	copy(next, current)
+	for i, it := range next {
+		byID[it.ID] = i
+	}
+	gone := map[int64]bool{}
+	touched := map[int64]bool{}
+
+	for _, l := range lines {
+		if l.Quantity < 0 || l.Quantity > maxCartLineQuantity {
+			return nil, nil, nil, errors.New("invalid_quantity")
+		}
+		var opts json.RawMessage
+		if l.Options != nil {
+			if opts, err = normalizeOptions(l.Options); err != nil {
+				return nil, nil, nil, errors.New("invalid_options")
+			}
+		}
+		if l.ItemID != nil {
+			i, ok := byID[*l.ItemID]
+			if !ok || gone[*l.ItemID] {
+				return nil, nil, nil, errors.New("item_not_found")
+			}
+			touched[*l.ItemID] = true
+			if l.Quantity == 0 {
+				gone[*l.ItemID] = true
+				removed = append(removed, *l.ItemID)
+				continue
+			}
+			next[i].Quantity = l.Quantity
+			if l.Options != nil {
+				next[i].Options = opts
+			}
+			if l.SpecialInstructions != nil {
+				next[i].SpecialInstructions = l.SpecialInstructions
+			}
+			continue
+		}
+		if l.MenuItemID == nil {
+			return nil, nil, nil, errors.New("menu_item_required")
+		}
+		if l.Quantity == 0 {
+			return nil, nil, nil, errors.New("invalid_quantity")
+		}
+		menuItemID := *l.MenuItemID
+		added = append(added, models.OrderItem{
+			MenuItemID:          &menuItemID,
+			Quantity:            l.Quantity,
+			Options:             opts,
+			SpecialInstructions: l.SpecialInstructions,
+		})
+	}
+	for _, it := range next {
+		if !gone[it.ID] {
+			it.PriceLocked = !touched[it.ID]
+			kept = append(kept, it)
+		}
+	}
+	return kept, removed, added, nil
+}
+
+func orderSnapshot(order *models.Order, items []models.OrderItem) *models.OrderSnapshot {
+	snap := &models.OrderSnapshot{
+		Items:          make([]models.OrderItem, len(items)),
+		SubtotalAmount: order.SubtotalAmount,
+		TaxAmount:      order.TaxAmount,
+		DeliveryFee:    order.DeliveryFee,
+		TipAmount:      order.TipAmount,
+		DiscountAmount: order.DiscountAmount,
+		TotalAmount:    order.TotalAmount,
+	}
+	copy(snap.Items, items)
+	return snap
+}
+
+func amendmentPaymentReference(a *models.OrderAmendment) string {
+	return fmt.Sprintf("order-%d-amendment-%d", a.OrderID, a.ID)
+}
+
+// amendmentCharge is the payment for an upward amendment; the same request always maps to the same payment
+func amendmentCharge(order *models.Order, a *models.OrderAmendment) clients.PaymentRequest {
+	return clients.PaymentRequest{
+		Reference:   amendmentPaymentReference(a),
+		UserID:      order.UserID,
+		Amount:      a.PriceDifference,
+		Description: fmt.Sprintf("Order %s amendment", order.OrderNumber),
+	}
+}
+
+// requestAmendmentPayment charges or refunds an amendment's price difference and remembers that payment-service took it
+func (s *orderService) requestAmendmentPayment(order *models.Order, a *models.OrderAmendment) error {
+	ref := amendmentPaymentReference(a)
+	var err error
+	switch a.PaymentAction {
+	case models.AmendPaymentCharge:
+		_, err = s.payments.CreatePayment(amendmentCharge(order, a))
+	case models.AmendPaymentRefund:
+		err = s.payments.RequestRefund(clients.RefundRequest{
+			Reference:  ref,
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			Amount:     -a.PriceDifference,
+			ReasonCode: "ORDER_AMENDED",
+		})
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.MarkAmendmentPaymentRequested(a.ID)
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/clients"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+const (
+	// after the restaurant confirms, a customer can still back out for this long from placing the order
+	customerCancelGrace = 2 * time.Minute
+	// refunds handed over per retry pass
+	refundRetryBatch = 50
+)
+
+// cancelReasons lists the reason codes each actor may give
+var cancelReasons = map[string][]string{
+	ActorCustomer: {
+		models.CancelReasonChangedMind, models.CancelReasonOrderedByMistake,
+		models.CancelReasonTakingTooLong, models.CancelReasonOther,
+	},
+	ActorRestaurant: {
+		models.CancelReasonItemUnavailable, models.CancelReasonRestaurantClosed,
+		models.CancelReasonRestaurantBusy, models.CancelReasonAddressIssue, models.CancelReasonOther,
+	},
+	ActorAdmin: {
+		models.CancelReasonChangedMind, models.CancelReasonOrderedByMistake, models.CancelReasonTakingTooLong,
+		models.CancelReasonItemUnavailable, models.CancelReasonRestaurantClosed, models.CancelReasonRestaurantBusy,
+		models.CancelReasonAddressIssue, models.CancelReasonPaymentIssue, models.CancelReasonFraudSuspected,
+		models.CancelReasonOther,
+	},
+}
+
+/*
+checkCancellation applies the per-actor cancel rules on top of the state machine.
+Customers may cancel a PLACED order, or a CONFIRMED one within customerCancelGrace of
+the kitchen getting it: placing it, or its release for scheduled orders. Restaurants and
+admins follow orderTransitions.
+*/
+func checkCancellation(order *models.Order, actor string, now time.Time) string {
+	if actor == ActorCustomer && order.OrderStatus == models.OrderStatusConfirmed {
+		sentAt := order.CreatedAt
+		if order.ReleaseAt != nil {
+			sentAt = order.ReleaseAt
+		}
+		if sentAt != nil && now.Sub(*sentAt) <= customerCancelGrace {
+			return ""
+		}
+		return "cancel_window_closed"
+	}
+	res := checkOrderTransition(order.OrderStatus, models.OrderStatusCancelled, actor)
+	if res == "forbidden" && actor == ActorCustomer {
+		// the customer is allowed in principle, just not at this stage any more
+		return "cancel_window_closed"
+	}
+	return res
+}
+
+func isCancelReasonAllowed(actor, code string) bool {
+	for _, c := range cancelReasons[actor] {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelOrder cancels with a reason code (and a note, required for OTHER); a paid order is refunded
+func (s *orderService) CancelOrder(orderID int64, reasonCode, note string, tokenUserID int64, role string) (*models.Order, error) {
+	reasonCode = strings.ToUpper(strings.TrimSpace(reasonCode))
+	note = strings.TrimSpace(note)
+	if reasonCode == "" {
+		return nil, errors.New("reason required")
+	}
+	if reasonCode == models.CancelReasonOther && note == "" {
+		return nil, errors.New("note required")
+	}
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	if actor == "" {
+		return nil, errors.New("forbidden")
+	}
+	return s.cancel(order, actor, reasonCode, note, tokenUserID)
+}
+
+// cancel moves the order to CANCELLED with its reason and, for paid orders, starts the refund
+func (s *orderService) cancel(order *models.Order, actor, reasonCode, note string, tokenUserID int64) (*models.Order, error) {
+	if _, ok := cancelReasons[actor]; !ok {
+		return nil, errors.New("forbidden")
+	}
+	if !isCancelReasonAllowed(actor, reasonCode) {
+		return nil, errors.New("invalid_reason")
+	}
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+	reason := reasonCode
+	if note != "" {
+		reason += ": " + note
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// an amendment may have changed the total since order was read; refund what the row says now
+	locked, err := s.repo.LockOrder(tx, order.ID)
+	if err != nil || locked == nil {
+		_ = tx.Rollback()
+		if err == nil {
+			err = errors.New("not_found")
+		}
+		return nil, err
+	}
+	order = locked
+	if res := checkCancellation(order, actor, time.Now().UTC()); res != "" {
+		_ = tx.Rollback()
+		return nil, errors.New(res)
+	}
+	paymentStatus := ""
+	if order.PaymentStatus == models.PaymentStatusPaid {
+		paymentStatus = models.PaymentStatusRefundPending
+	}
+	if err := s.applyTransition(tx, order, models.OrderStatusCancelled, reason, actor, tokenUserID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := s.repo.SetCancellation(tx, order.ID, reasonCode, notePtr, actor, paymentStatus); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	order.OrderStatus = models.OrderStatusCancelled
+	order.CancelReasonCode = &reasonCode
+	order.CancelNote = notePtr
+	order.CancelledBy = &actor
+	order.CancelledAt = &now
+	if paymentStatus != "" {
+		order.PaymentStatus = paymentStatus
+		// best effort now; RetryPendingRefunds picks it up if payment-service is unreachable
+		if err := s.requestRefund(order); err != nil {
+			log.Printf("refund hand-off for order %d failed, will retry: %v", order.ID, err)
+		}
+	}
+	return order, nil
+}
+
+/*
+requestRefund hands a REFUND_PENDING order to payment-service and remembers that it was
+accepted. Only what was captured goes back: amendment charges are voided first and the
+ones the customer had not paid are left out of the refund.
+*/
+func (s *orderService) requestRefund(order *models.Order) error {
+	voided, err := s.voidAmendmentCharges(order)
+	if err != nil {
+		return err
+	}
+	if amount := roundMoney(order.TotalAmount - voided); amount > 0 {
+		err := s.payments.RequestRefund(clients.RefundRequest{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			Amount:     amount,
+			ReasonCode: derefString(order.CancelReasonCode),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.repo.MarkRefundRequested(order.ID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	order.RefundRequestedAt = &now
+	return nil
+}
+
+/*
+voidAmendmentCharges withdraws the charges of a cancelled order's upward amendments and
+returns the total of those the customer had not paid. Each charge is looked up by its
+reference, so one that never reached payment-service is opened and withdrawn at once.
+*/
+func (s *orderService) voidAmendmentCharges(order *models.Order) (float64, error) {
+	amendments, err := s.repo.ListAmendments(order.ID)
+	if err != nil {
+		return 0, err
+	}
+	voided := 0.0
+	for i := range amendments {
+		a := &amendments[i]
+		if a.PaymentAction != models.AmendPaymentCharge {
+			continue
+		}
+		if a.ChargeOutcome == "" {
+			p, err := s.payments.CreatePayment(amendmentCharge(order, a))
+			if err != nil {
+				return 0, err
+			}
+			if p, err = s.payments.CancelPayment(p.ID); err != nil {
+				return 0, err
+			}
+			a.ChargeOutcome = models.AmendChargeVoided
+			if p.Status == models.PaymentStatusPaid {
+				a.ChargeOutcome = models.AmendChargeCaptured
+			}
+			if err := s.repo.SetChargeOutcome(a.ID, a.ChargeOutcome); err != nil {
+				return 0, err
+			}
+		}
+		if a.ChargeOutcome == models.AmendChargeVoided {
+			voided += a.PriceDifference
+		}
+	}
+	return voided, nil
+}
+
+// RetryPendingRefunds re-sends refunds, and amendment charges, payment-service has not accepted yet
+func (s *orderService) RetryPendingRefunds() error {
+	orders, err := s.repo.ListPendingRefunds(refundRetryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		if err := s.requestRefund(&orders[i]); err != nil {
+			log.Printf("refund retry for order %d: %v", orders[i].ID, err)
+		}
+	}
+
+	amendments, err := s.repo.ListPendingAmendmentPayments(refundRetryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range amendments {
+		order, err := s.repo.GetOrderByID(amendments[i].OrderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			continue
+		}
+		if order.OrderStatus == models.OrderStatusCancelled && amendments[i].PaymentAction == models.AmendPaymentCharge {
+			// the order's refund voids it instead
+			continue
+		}
+		if err := s.requestAmendmentPayment(order, &amendments[i]); err != nil {
+			log.Printf("amendment %d of order %d: payment retry: %v", amendments[i].ID, order.ID, err)
+		}
+	}
+	return nil
+}
+
+// RunRefundRetries calls RetryPendingRefunds every interval, forever
+func RunRefundRetries(svc OrderService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := svc.RetryPendingRefunds(); err != nil {
+			log.Printf("refund retry: %v", err)
+		}
+	}
+}
+
+func derefString(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+const (
+	// each order ahead in the kitchen delays this one by roughly this much
+	queueDelayPerOrder = 3 * time.Minute
+	// average rider speed in the city and how much longer roads are than a straight line
+	riderSpeedKmh = 20.0
+	roadFactor    = 1.3
+	// rider reaching the restaurant and collecting the food
+	pickupBuffer  = 5 * time.Minute
+	earthRadiusKm = 6371.0
+)
+
+/*
+estimateETA predicts when the order reaches the customer if it is in status now:
+  - PLACED: confirmation buffer + kitchen queue + prep + travel
+  - CONFIRMED: kitchen queue + prep + travel
+  - PREPARING: prep + travel
+  - READY: travel
+  - OUT_FOR_DELIVERY: travel from the rider's last position when known
+
+SCHEDULED orders are due at ScheduledFor. Prep is the slowest item's PrepTimeMinutes,
+the queue counts the restaurant's other orders not cooked yet, and travel is the road
+distance between restaurant and drop-off at rider speed plus pick-up. Pickup and dine-in
+orders have no travel. Returns nil for DELIVERED and CANCELLED orders.
+*/
+func (s *orderService) estimateETA(order *models.Order, items []models.OrderItem, status string, now time.Time) (*models.OrderETA, error) {
+	eta := &models.OrderETA{OrderID: order.ID, OrderStatus: status, CreatedAt: now}
+	var wait time.Duration
+
+	switch status {
+	case models.OrderStatusScheduled, models.OrderStatusPlaced, models.OrderStatusConfirmed, models.OrderStatusPreparing:
+		if items == nil && order.ID != 0 {
+			loaded, err := s.repo.GetItemsForOrders([]int64{order.ID})
+			if err != nil {
+				return nil, err
+			}
+			items = loaded[order.ID]
+		}
+		prep, err := s.prepTime(items)
+		if err != nil {
+			return nil, err
+		}
+		eta.PrepMinutes = minutes(prep)
+		wait += prep
+	case models.OrderStatusReady, models.OrderStatusOutForDelivery:
+	default:
+		return nil, nil
+	}
+
+	if status == models.OrderStatusPlaced || status == models.OrderStatusConfirmed {
+		depth, err := s.repo.CountKitchenQueue(order.RestaurantID, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		queue := time.Duration(depth) * queueDelayPerOrder
+		eta.QueueDepth = depth
+		eta.QueueMinutes = minutes(queue)
+		wait += queue
+		if status == models.OrderStatusPlaced {
+			wait += confirmBuffer
+		}
+	}
+
+	if isDeliveryOrder(order) {
+		travel, km, err := s.travelTime(order, status)
+		if err != nil {
+			return nil, err
+		}
+		eta.TravelMinutes = minutes(travel)
+		eta.DistanceKm = km
+		wait += travel
+	}
+
+	eta.EstimatedDeliveryTime = now.Add(wait)
+	if status == models.OrderStatusScheduled && order.ScheduledFor != nil {
+		// the kitchen is released early enough to make the requested time
+		eta.EstimatedDeliveryTime = order.ScheduledFor.UTC()
+	}
+	return eta, nil
+}
+
+/*
+travelTime is the rider's part of the ETA. Until the rider has the food it runs from the
+restaurant and includes the pick-up; once out for delivery it runs from the rider's last
+reported position. Without coordinates it falls back to the flat deliveryBuffer.
+*/
+func (s *orderService) travelTime(order *models.Order, status string) (time.Duration, *float64, error) {
+	if order.DeliveryLatitude == nil || order.DeliveryLongitude == nil {
+		return deliveryBuffer, nil, nil
+	}
+	var fromLat, fromLon *float64
+	pickup := pickupBuffer
+	if status == models.OrderStatusOutForDelivery && order.RiderLatitude != nil && order.RiderLongitude != nil {
+		fromLat, fromLon = order.RiderLatitude, order.RiderLongitude
+		pickup = 0
+	} else {
+		rest, err := s.restRepo.GetByID(order.RestaurantID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if rest != nil {
+			fromLat, fromLon = rest.Latitude, rest.Longitude
+		}
+		if status == models.OrderStatusOutForDelivery {
+			pickup = 0
+		}
+	}
+	if fromLat == nil || fromLon == nil {
+		return deliveryBuffer, nil, nil
+	}
+	km := math.Round(distanceKm(*fromLat, *fromLon, *order.DeliveryLatitude, *order.DeliveryLongitude)*1000) / 1000
+	ride := time.Duration(km * roadFactor / riderSpeedKmh * float64(time.Hour))
+	return pickup + ride, &km, nil
+}
+
+/*
+refreshETA runs inside a status transition: a new prediction is stored and becomes the
+order's estimated_delivery_time, and DELIVERED records the actual delivery time instead.
+It returns the new estimate, nil when there is none.
+*/
+func (s *orderService) refreshETA(tx *sql.Tx, order *models.Order, status string, now time.Time) (*time.Time, error) {
+	if status == models.OrderStatusDelivered {
+		if err := s.repo.SetDelivered(tx, order.ID, now); err != nil {
+			return nil, err
+		}
+		order.DeliveredAt = &now
+		return nil, nil
+	}
+	eta, err := s.estimateETA(order, order.Items, status, now)
+	if err != nil || eta == nil {
+		return nil, err
+	}
+	if err := s.repo.SetEstimatedDelivery(tx, order.ID, eta.EstimatedDeliveryTime); err != nil {
+		return nil, err
+	}
+	if err := s.repo.InsertETAPrediction(tx, eta); err != nil {
+		return nil, err
+	}
+	order.EstimatedDeliveryAt = &eta.EstimatedDeliveryTime
+	return order.EstimatedDeliveryAt, nil
+}
+
+func isDeliveryOrder(order *models.Order) bool {
+	return strings.EqualFold(order.OrderType, "DELIVERY") || order.OrderType == ""
+}
+
+// distanceKm is the great-circle distance between two points
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// minutes rounds a duration up to whole minutes
+func minutes(d time.Duration) int {
+	return int(math.Ceil(d.Minutes()))
+}
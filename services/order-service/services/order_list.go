@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+const maxOrderPageSize = 100
+
+// ListCustomerOrders lists the caller's own orders
+func (s *orderService) ListCustomerOrders(params repository.ListOrdersParams, cursor string, tokenUserID int64) ([]models.Order, string, error) {
+	if tokenUserID == 0 {
+		return nil, "", errors.New("forbidden")
+	}
+	params.UserID = &tokenUserID
+	params.RestaurantID = nil
+	return s.listOrders(params, cursor)
+}
+
+// ListRestaurantOrders lists a restaurant's orders for its owner or an admin
+func (s *orderService) ListRestaurantOrders(restaurantID int64, params repository.ListOrdersParams, cursor string, tokenUserID int64, role string) ([]models.Order, string, error) {
+	if err := s.AuthorizeRestaurantStaff(restaurantID, tokenUserID, role); err != nil {
+		return nil, "", err
+	}
+	params.RestaurantID = &restaurantID
+	params.UserID = nil
+	return s.listOrders(params, cursor)
+}
+
+// listOrders fetches one page plus its items and returns the cursor of the next page ("" on the last page)
+func (s *orderService) listOrders(params repository.ListOrdersParams, cursor string) ([]models.Order, string, error) {
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > maxOrderPageSize {
+		params.Limit = maxOrderPageSize
+	}
+	params.Status = strings.ToUpper(strings.TrimSpace(params.Status))
+	params.OrderType = strings.ToUpper(strings.TrimSpace(params.OrderType))
+	params.PaymentStatus = strings.ToUpper(strings.TrimSpace(params.PaymentStatus))
+	if cursor != "" {
+		createdAt, id, err := decodeOrderCursor(cursor)
+		if err != nil {
+			return nil, "", errors.New("invalid_cursor")
+		}
+		params.AfterCreatedAt = &createdAt
+		params.AfterID = id
+	}
+
+	// one extra row tells us whether another page exists
+	want := params.Limit
+	params.Limit++
+	orders, err := s.repo.ListOrders(params)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(orders) > want {
+		orders = orders[:want]
+		last := orders[want-1]
+		next = encodeOrderCursor(*last.CreatedAt, last.ID)
+	}
+
+	ids := make([]int64, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].ID
+	}
+	items, err := s.repo.GetItemsForOrders(ids)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range orders {
+		orders[i].Items = items[orders[i].ID]
+	}
+	return orders, next, nil
+}
+
+/* cursor is an opaque base64 of "<created_at RFC3339Nano>|<id>" */
+
+func encodeOrderCursor(createdAt time.Time, id int64) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeOrderCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return createdAt, id, nil
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+formatOrderNumber renders numbers like R42-261017-0031: restaurant, YYMMDD of the business
+day, daily sequence. The year keeps a number from coming back a year later.
+*/
+func formatOrderNumber(restaurantID int64, day time.Time, seq int) string {
+	return fmt.Sprintf("R%d-%s-%04d", restaurantID, day.Format("060102"), seq)
+}
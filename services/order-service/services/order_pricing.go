@@ -0,0 +1,214 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// OrderProblem describes one thing wrong with a submitted order
+type OrderProblem struct {
+	Line       int      `json:"line"` // index into items, -1 for order level amounts
+	MenuItemID *int64   `json:"menuItemId,omitempty"`
+	Field      string   `json:"field"`
+	Reason     string   `json:"reason"`
+	Expected   *float64 `json:"expected,omitempty"`
+	Got        *float64 `json:"got,omitempty"`
+}
+
+// OrderValidationError is returned by PlaceOrder when the basket does not match the menu
+type OrderValidationError struct {
+	Problems []OrderProblem
+}
+
+func (e *OrderValidationError) Error() string {
+	return fmt.Sprintf("order validation failed: %d problem(s)", len(e.Problems))
+}
+
+func (e *OrderValidationError) add(line int, menuItemID *int64, field, reason string) {
+	e.Problems = append(e.Problems, OrderProblem{Line: line, MenuItemID: menuItemID, Field: field, Reason: reason})
+}
+
+func (e *OrderValidationError) mismatch(line int, menuItemID *int64, field string, expected, got float64) {
+	e.Problems = append(e.Problems, OrderProblem{
+		Line: line, MenuItemID: menuItemID, Field: field, Reason: "price mismatch",
+		Expected: &expected, Got: &got,
+	})
+}
+
+/*
+priceOrder resolves every line against the menu and recomputes unit prices,
+line totals, the promotion discount, GST, subtotal and total on the server. Client supplied amounts are
+only used as assertions: a non-zero value that differs from ours is reported
+so the app can refresh its stale menu instead of silently charging a different price.
+The tip is the only amount taken from the client; the discount never exceeds the subtotal.
+Lines marked PriceLocked keep their unit price.
+On success order and items carry the server values. A preview stores nothing.
+*/
+func (s *orderService) priceOrder(order *models.Order, items []models.OrderItem, preview bool) error {
+	verr := &OrderValidationError{}
+
+	ids := make([]int64, 0, len(items))
+	for _, it := range items {
+		if it.MenuItemID != nil {
+			ids = append(ids, *it.MenuItemID)
+		}
+	}
+	menu, err := s.menuRepo.GetMenuItemsByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]models.MenuItem, len(menu))
+	for _, m := range menu {
+		byID[m.ID] = m
+	}
+
+	// items are checked against their serving windows at delivery time
+	servedTime := time.Now()
+	if order.ScheduledFor != nil {
+		servedTime = *order.ScheduledFor
+	}
+
+	subtotal := 0.0
+	for i := range items {
+		it := &items[i]
+		if it.PriceLocked {
+			// what the customer already agreed to, even if the menu has changed since
+			it.TotalPrice = roundMoney(it.UnitPrice * float64(it.Quantity))
+			subtotal += it.TotalPrice
+			continue
+		}
+		if it.MenuItemID == nil {
+			verr.add(i, nil, "menuItemId", "required")
+			continue
+		}
+		if it.Quantity <= 0 {
+			verr.add(i, it.MenuItemID, "qty", "must be at least 1")
+			continue
+		}
+		m, ok := byID[*it.MenuItemID]
+		if !ok {
+			verr.add(i, it.MenuItemID, "menuItemId", "menu item not found")
+			continue
+		}
+		if m.RestaurantID != order.RestaurantID {
+			verr.add(i, it.MenuItemID, "menuItemId", "item belongs to another restaurant")
+			continue
+		}
+		if m.Availability != models.AvailabilityInStock {
+			verr.add(i, it.MenuItemID, "menuItemId", "item is not available")
+			continue
+		}
+		// lines an amendment keeps were ordered in time
+		if it.ID == 0 && !servedAt(m, servedTime) {
+			verr.add(i, it.MenuItemID, "menuItemId", "item is not served at this time")
+			continue
+		}
+
+		// modifiers are priced by the menu as well; the line keeps what was picked, with names and prices
+		opts, delta, reason := resolveOptions(m, it.Options)
+		if reason != "" {
+			verr.add(i, it.MenuItemID, "options", reason)
+			continue
+		}
+		it.Options = opts
+
+		unit := roundMoney(m.Price + delta)
+		line := roundMoney(unit * float64(it.Quantity))
+		if it.UnitPrice != 0 && !moneyEqual(it.UnitPrice, unit) {
+			verr.mismatch(i, it.MenuItemID, "unitPrice", unit, it.UnitPrice)
+		}
+		if it.TotalPrice != 0 && !moneyEqual(it.TotalPrice, line) {
+			verr.mismatch(i, it.MenuItemID, "totalPrice", line, it.TotalPrice)
+		}
+		it.Name = m.Name
+		it.UnitPrice = unit
+		it.TotalPrice = line
+		subtotal += line
+	}
+	subtotal = roundMoney(subtotal)
+
+	// the GST place of supply, and the drop point if not given, come from the saved address
+	if len(verr.Problems) == 0 {
+		if err := s.applyDeliveryAddress(order, verr); err != nil {
+			return err
+		}
+	}
+
+	// the discount comes from promotions as well; a client discountAmount is only an assertion
+	var discountWeights []float64
+	if len(verr.Problems) == 0 {
+		discountWeights, err = s.applyPromotion(order, items, byID, subtotal, time.Now().UTC(), verr)
+		if err != nil {
+			return err
+		}
+	}
+
+	// so is the delivery fee; the thresholds go by the subtotal before discounts
+	if len(verr.Problems) == 0 {
+		if err := s.applyDeliveryFee(order, subtotal, time.Now().UTC(), preview, verr); err != nil {
+			return err
+		}
+	}
+
+	// an amended basket can shrink below the discount it kept
+	if order.DiscountAmount > subtotal {
+		order.DiscountAmount = subtotal
+	}
+
+	// GST is ours to work out as well; a client taxAmount is only an assertion
+	tax := 0.0
+	if len(verr.Problems) == 0 {
+		rest, err := s.restRepo.GetByID(order.RestaurantID)
+		if err != nil {
+			return err
+		}
+		if rest != nil {
+			rules, err := s.taxes.ListRules(order.RestaurantID)
+			if err != nil {
+				return err
+			}
+			tax = applyTax(rules, rest, order, items, byID, discountWeights)
+			order.RestaurantGSTIN = nil
+			if rest.GSTIN != "" {
+				gstin := rest.GSTIN
+				order.RestaurantGSTIN = &gstin
+			}
+		}
+	}
+
+	if order.TipAmount < 0 {
+		verr.add(-1, nil, "tipAmount", "must be >= 0")
+	}
+
+	total := roundMoney(subtotal + tax + order.DeliveryFee + order.TipAmount - order.DiscountAmount)
+	if order.SubtotalAmount != 0 && !moneyEqual(order.SubtotalAmount, subtotal) {
+		verr.mismatch(-1, nil, "subtotal", subtotal, order.SubtotalAmount)
+	}
+	if order.TaxAmount != 0 && !moneyEqual(order.TaxAmount, tax) {
+		verr.mismatch(-1, nil, "taxAmount", tax, order.TaxAmount)
+	}
+	if order.TotalAmount != 0 && !moneyEqual(order.TotalAmount, total) {
+		verr.mismatch(-1, nil, "totalAmount", total, order.TotalAmount)
+	}
+
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	order.SubtotalAmount = subtotal
+	order.TaxAmount = tax
+	order.TotalAmount = total
+	return nil
+}
+
+/* money helpers: amounts are rupees with paise precision */
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func moneyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+type fakeMenuRepo struct {
+	items []models.MenuItem
+}
+
+func (f *fakeMenuRepo) GetCategories(restaurantID int64) ([]models.MenuCategory, error) {
+	return nil, nil
+}
+
+func (f *fakeMenuRepo) GetMenuItemsByIDs(ids []int64) ([]models.MenuItem, error) {
+	var out []models.MenuItem
+	for _, m := range f.items {
+		for _, id := range ids {
+			if m.ID == id {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+type fakeRestaurantRepo struct {
+	restaurants []models.Restaurant
+}
+
+func (f *fakeRestaurantRepo) GetByID(id int64) (*models.Restaurant, error) {
+	for i := range f.restaurants {
+		if f.restaurants[i].ID == id {
+			rest := f.restaurants[i]
+			return &rest, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRestaurantRepo) GetHoursByRestaurant(restaurantID int64) ([]models.RestaurantHour, error) {
+	return nil, nil
+}
+
+func (f *fakeRestaurantRepo) GetTableByQRToken(token string) (*models.RestaurantTable, error) {
+	return nil, nil
+}
+
+// fakeTaxRepo serves ListRules; the other methods are not used by pricing
+type fakeTaxRepo struct {
+	repository.TaxRepo
+	rules []models.TaxRule
+}
+
+func (f *fakeTaxRepo) ListRules(restaurantID int64) ([]models.TaxRule, error) {
+	return f.rules, nil
+}
+
+// fakePromotionRepo serves the lookups pricing makes, for promotions without usage limits
+type fakePromotionRepo struct {
+	repository.PromotionRepo
+	codes  map[string]*models.Promotion
+	offers []models.Promotion
+}
+
+func (f *fakePromotionRepo) GetActiveByCode(code string) (*models.Promotion, error) {
+	return f.codes[code], nil
+}
+
+func (f *fakePromotionRepo) ListAutomatic(restaurantID int64) ([]models.Promotion, error) {
+	return f.offers, nil
+}
+
+func int64Ptr(v int64) *int64       { return &v }
+func float64Ptr(v float64) *float64 { return &v }
+func stringPtr(v string) *string    { return &v }
+
+func testMenu() []models.MenuItem {
+	size := models.ModifierGroup{
+		ID: 1, MenuItemID: 104, Name: "Size", MinSelect: 1, MaxSelect: 1,
+		Options: []models.ModifierOption{
+			{ID: 11, GroupID: 1, Name: "Regular", IsAvailable: true},
+			{ID: 12, GroupID: 1, Name: "Large", PriceDelta: 120, IsAvailable: true},
+		},
+	}
+	return []models.MenuItem{
+		{ID: 101, RestaurantID: 1, Name: "Paneer Tikka", Price: 249, Availability: models.AvailabilityInStock, Tags: []string{"veg"}},
+		{ID: 102, RestaurantID: 1, Name: "Butter Naan", Price: 45, Availability: models.AvailabilityInStock},
+		{ID: 103, RestaurantID: 1, Name: "Mango Lassi", Price: 90, Availability: models.AvailabilityOutOfStock},
+		{ID: 104, RestaurantID: 1, Name: "Pizza", Price: 300, Availability: models.AvailabilityInStock, ModifierGroups: []models.ModifierGroup{size}},
+		{ID: 201, RestaurantID: 2, Name: "Burger", Price: 100, Availability: models.AvailabilityInStock},
+	}
+}
+
+func newPricingService(offers []models.Promotion) *orderService {
+	return &orderService{
+		menuRepo: &fakeMenuRepo{items: testMenu()},
+		restRepo: &fakeRestaurantRepo{restaurants: []models.Restaurant{{ID: 1, Name: "Dhaba", State: "Punjab"}}},
+		taxes:    &fakeTaxRepo{rules: []models.TaxRule{{ID: 1, Label: "GST food", RatePercent: 5}}},
+		promos: &fakePromotionRepo{
+			codes: map[string]*models.Promotion{
+				"SAVE50": {ID: 7, Code: stringPtr("SAVE50"), DiscountType: models.PromotionFlat, DiscountValue: 50, MinSubtotal: 300, IsActive: true},
+			},
+			offers: offers,
+		},
+	}
+}
+
+func line(menuItemID int64, qty int) models.OrderItem {
+	return models.OrderItem{MenuItemID: int64Ptr(menuItemID), Quantity: qty}
+}
+
+func TestPriceOrder(t *testing.T) {
+	vegOffer := models.Promotion{
+		ID: 8, RestaurantID: int64Ptr(1), DiscountType: models.PromotionPercent, DiscountValue: 20,
+		MaxDiscount: float64Ptr(60), Tags: []string{"veg"}, IsActive: true,
+	}
+
+	tests := []struct {
+		name   string
+		order  models.Order
+		items  []models.OrderItem
+		offers []models.Promotion
+
+		wantProblems []string // "field: reason", in order
+		wantUnits    []float64
+		wantSubtotal float64
+		wantDiscount float64
+		wantTax      float64
+		wantTotal    float64
+	}{
+		{
+			name:         "lines priced from the menu",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{line(101, 2), line(102, 3)},
+			wantUnits:    []float64{249, 45},
+			wantSubtotal: 633, wantTax: 31.66, wantTotal: 664.66,
+		},
+		{
+			name:         "tip is the client's",
+			order:        models.Order{OrderType: "PICKUP", TipAmount: 20},
+			items:        []models.OrderItem{line(101, 2), line(102, 3)},
+			wantUnits:    []float64{249, 45},
+			wantSubtotal: 633, wantTax: 31.66, wantTotal: 684.66,
+		},
+		{
+			name:         "client amounts that match are accepted",
+			order:        models.Order{OrderType: "PICKUP", SubtotalAmount: 249, TaxAmount: 12.46, TotalAmount: 261.46},
+			items:        []models.OrderItem{{MenuItemID: int64Ptr(101), Quantity: 1, UnitPrice: 249, TotalPrice: 249}},
+			wantUnits:    []float64{249},
+			wantSubtotal: 249, wantTax: 12.46, wantTotal: 261.46,
+		},
+		{
+			name:         "stale unit price",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{{MenuItemID: int64Ptr(101), Quantity: 1, UnitPrice: 229}},
+			wantProblems: []string{"unitPrice: price mismatch"},
+		},
+		{
+			name:         "stale total",
+			order:        models.Order{OrderType: "PICKUP", TotalAmount: 600},
+			items:        []models.OrderItem{line(101, 2), line(102, 3)},
+			wantProblems: []string{"totalAmount: price mismatch"},
+		},
+		{
+			name:         "modifiers add to the unit price",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{{MenuItemID: int64Ptr(104), Quantity: 1, Options: json.RawMessage(`[{"group_id":1,"option_ids":[12]}]`)}},
+			wantUnits:    []float64{420},
+			wantSubtotal: 420, wantTax: 21, wantTotal: 441,
+		},
+		{
+			name:         "required modifier missing",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{line(104, 1)},
+			wantProblems: []string{`options: "Size" needs at least 1 choice(s)`},
+		},
+		{
+			name:         "locked line keeps its price",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{{MenuItemID: int64Ptr(101), Quantity: 2, UnitPrice: 199, PriceLocked: true}},
+			wantUnits:    []float64{199},
+			wantSubtotal: 398, wantTax: 19.9, wantTotal: 417.9,
+		},
+		{
+			name:         "coupon discount lowers the taxable value",
+			order:        models.Order{OrderType: "PICKUP", PromoCode: stringPtr(" save50 ")},
+			items:        []models.OrderItem{line(101, 2), line(102, 3)},
+			wantUnits:    []float64{249, 45},
+			wantSubtotal: 633, wantDiscount: 50, wantTax: 29.16, wantTotal: 612.16,
+		},
+		{
+			name:         "unknown coupon",
+			order:        models.Order{OrderType: "PICKUP", PromoCode: stringPtr("NOPE")},
+			items:        []models.OrderItem{line(101, 1)},
+			wantProblems: []string{"promoCode: unknown or expired code"},
+		},
+		{
+			name:         "automatic offer on tagged lines, capped",
+			order:        models.Order{OrderType: "PICKUP"},
+			items:        []models.OrderItem{line(101, 2), line(102, 1)},
+			offers:       []models.Promotion{vegOffer},
+			wantUnits:    []float64{249, 45},
+			wantSubtotal: 543, wantDiscount: 60, wantTax: 24.16, wantTotal: 507.16,
+		},
+		{
+			name:         "claimed discount without a promotion",
+			order:        models.Order{OrderType: "PICKUP", DiscountAmount: 10},
+			items:        []models.OrderItem{line(102, 1)},
+			wantProblems: []string{"discountAmount: price mismatch"},
+		},
+		{
+			name:  "bad lines are all reported",
+			order: models.Order{OrderType: "PICKUP"},
+			items: []models.OrderItem{{Quantity: 1}, line(102, 0), line(999, 1), line(201, 1), line(103, 1)},
+			wantProblems: []string{
+				"menuItemId: required",
+				"qty: must be at least 1",
+				"menuItemId: menu item not found",
+				"menuItemId: item belongs to another restaurant",
+				"menuItemId: item is not available",
+			},
+		},
+		{
+			name:         "delivery needs a saved address",
+			order:        models.Order{OrderType: "DELIVERY"},
+			items:        []models.OrderItem{line(101, 1)},
+			wantProblems: []string{"deliveryAddressId: required for delivery"},
+		},
+		{
+			name:         "negative tip",
+			order:        models.Order{OrderType: "PICKUP", TipAmount: -5},
+			items:        []models.OrderItem{line(101, 1)},
+			wantProblems: []string{"tipAmount: must be >= 0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newPricingService(tc.offers)
+			order := tc.order
+			order.UserID = 5
+			order.RestaurantID = 1
+
+			err := s.priceOrder(&order, tc.items, true)
+
+			if len(tc.wantProblems) > 0 {
+				verr, ok := err.(*OrderValidationError)
+				if !ok {
+					t.Fatalf("err = %v, want validation problems %v", err, tc.wantProblems)
+				}
+				got := make([]string, len(verr.Problems))
+				for i, p := range verr.Problems {
+					got[i] = p.Field + ": " + p.Reason
+				}
+				if fmt.Sprint(got) != fmt.Sprint(tc.wantProblems) {
+					t.Fatalf("problems = %q, want %q", got, tc.wantProblems)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("priceOrder: %v", err)
+			}
+			for i, want := range tc.wantUnits {
+				if !moneyEqual(tc.items[i].UnitPrice, want) {
+					t.Errorf("line %d unit price = %v, want %v", i, tc.items[i].UnitPrice, want)
+				}
+				if tc.items[i].Tax == nil {
+					t.Errorf("line %d has no tax breakdown", i)
+				}
+			}
+			amounts := []struct {
+				field     string
+				got, want float64
+			}{
+				{"subtotal", order.SubtotalAmount, tc.wantSubtotal},
+				{"discount", order.DiscountAmount, tc.wantDiscount},
+				{"tax", order.TaxAmount, tc.wantTax},
+				{"total", order.TotalAmount, tc.wantTotal},
+			}
+			for _, a := range amounts {
+				if !moneyEqual(a.got, a.want) {
+					t.Errorf("%s = %v, want %v", a.field, a.got, a.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+const (
+	// how far ahead an order can be scheduled
+	maxScheduleAhead = 7 * 24 * time.Hour
+	// used for items without a prep time on the menu
+	defaultPrepTime = 15 * time.Minute
+	// slack for the restaurant to confirm before cooking starts
+	confirmBuffer = 10 * time.Minute
+	// rough rider pick-up and travel time for DELIVERY orders
+	deliveryBuffer = 30 * time.Minute
+	// orders released per scheduler pass
+	scheduledReleaseBatch = 100
+)
+
+/*
+scheduleOrder validates order.ScheduledFor and turns the order into a SCHEDULED one.
+The kitchen gets it at ReleaseAt = ScheduledFor - (longest item prep time + confirm
+buffer + travel for deliveries). The restaurant has to be open both when cooking starts
+and when the food is ready. Problems are added to verr.
+*/
+func (s *orderService) scheduleOrder(order *models.Order, items []models.OrderItem, now time.Time, verr *OrderValidationError) error {
+	at := order.ScheduledFor.UTC()
+	lead, err := s.kitchenLeadTime(order, items)
+	if err != nil {
+		return err
+	}
+	release := at.Add(-lead)
+	readyAt := at
+	if isDeliveryOrder(order) {
+		readyAt = at.Add(-deliveryBuffer)
+	}
+
+	switch {
+	case release.Before(now):
+		verr.add(-1, nil, "scheduledFor", "must be at least "+lead.String()+" from now")
+	case at.After(now.Add(maxScheduleAhead)):
+		verr.add(-1, nil, "scheduledFor", "cannot be more than 7 days ahead")
+	default:
+		hours, err := s.restRepo.GetHoursByRestaurant(order.RestaurantID)
+		if err != nil {
+			return err
+		}
+		if !isOpenAt(hours, release) || !isOpenAt(hours, readyAt) {
+			verr.add(-1, nil, "scheduledFor", "restaurant is closed at that time")
+		}
+	}
+
+	order.ScheduledFor = &at
+	order.ReleaseAt = &release
+	order.OrderStatus = models.OrderStatusScheduled
+	return nil
+}
+
+// kitchenLeadTime is how long before ScheduledFor the kitchen needs the order
+func (s *orderService) kitchenLeadTime(order *models.Order, items []models.OrderItem) (time.Duration, error) {
+	prep, err := s.prepTime(items)
+	if err != nil {
+		return 0, err
+	}
+	lead := prep + confirmBuffer
+	if isDeliveryOrder(order) {
+		lead += deliveryBuffer
+	}
+	return lead, nil
+}
+
+// prepTime is how long the kitchen takes to cook the items once it starts
+func (s *orderService) prepTime(items []models.OrderItem) (time.Duration, error) {
+	ids := make([]int64, 0, len(items))
+	for _, it := range items {
+		if it.MenuItemID != nil {
+			ids = append(ids, *it.MenuItemID)
+		}
+	}
+	menu, err := s.menuRepo.GetMenuItemsByIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	// items cook in parallel, the slowest one decides
+	prep := time.Duration(0)
+	for _, m := range menu {
+		p := time.Duration(m.PrepTimeMinutes) * time.Minute
+		if p <= 0 {
+			p = defaultPrepTime
+		}
+		if p > prep {
+			prep = p
+		}
+	}
+	if prep == 0 {
+		prep = defaultPrepTime
+	}
+	return prep, nil
+}
+
+/*
+isOpenAt reports whether t falls inside the opening hours, read in the business timezone.
+Weekday is 0 = Sunday. A close time at or before the open time runs past midnight into the
+next day, so the previous day's row is checked too. A restaurant without any hours
+configured is treated as always open.
+*/
+func isOpenAt(hours []models.RestaurantHour, t time.Time) bool {
+	if len(hours) == 0 {
+		return true
+	}
+	for _, h := range hours {
+		if !h.IsClosed && businesstime.InWeeklyWindow(h.Weekday, h.OpenTime, h.CloseTime, t) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ReleaseScheduledOrders moves SCHEDULED orders whose lead time has started to PLACED.
+An order that fails is logged and left for the next pass; the rest of the batch still goes.
+*/
+func (s *orderService) ReleaseScheduledOrders() error {
+	due, err := s.repo.ListDueScheduledOrders(time.Now().UTC(), scheduledReleaseBatch)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if err := s.releaseScheduled(&due[i]); err != nil {
+			if err.Error() == "conflict" {
+				// another replica released or someone cancelled it meanwhile
+				continue
+			}
+			log.Printf("scheduled release of order %d: %v", due[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// releaseScheduled hands one order to the kitchen; to the kitchen stream it looks like a new order
+func (s *orderService) releaseScheduled(order *models.Order) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := s.applyTransition(tx, order, models.OrderStatusPlaced, "scheduled release", ActorSystem, 0); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	order.OrderStatus = models.OrderStatusPlaced
+	items, err := s.repo.GetItemsForOrders([]int64{order.ID})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	order.Items = items[order.ID]
+	if err := s.recordEvent(tx, order, models.OrderEventPlaced, order); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// RunScheduledReleases calls ReleaseScheduledOrders every interval, forever
+func RunScheduledReleases(svc OrderService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := svc.ReleaseScheduledOrders(); err != nil {
+			log.Printf("scheduled release: %v", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// Actors recorded in order_status_history.actor_role
+const (
+	ActorCustomer   = "CUSTOMER"
+	ActorRestaurant = "RESTAURANT"
+	ActorRider      = "RIDER"
+	ActorAdmin      = "ADMIN"
+	ActorSystem     = "SYSTEM"
+)
+
+/*
+orderTransitions is the order status state machine:
+current status -> next status -> actors allowed to make that move.
+DELIVERED and CANCELLED are terminal. READY may go straight to DELIVERED
+for PICKUP / DINE_IN orders handed over at the counter. SCHEDULED orders are
+released to PLACED by the scheduler when their kitchen lead time starts.
+*/
+var orderTransitions = map[string]map[string][]string{
+	models.OrderStatusScheduled: {
+		models.OrderStatusPlaced:    {ActorSystem, ActorAdmin},
+		models.OrderStatusCancelled: {ActorCustomer, ActorRestaurant, ActorAdmin},
+	},
+	models.OrderStatusPlaced: {
+		models.OrderStatusConfirmed: {ActorRestaurant, ActorAdmin},
+		models.OrderStatusCancelled: {ActorCustomer, ActorRestaurant, ActorAdmin},
+	},
+	models.OrderStatusConfirmed: {
+		models.OrderStatusPreparing: {ActorRestaurant, ActorAdmin},
+		models.OrderStatusCancelled: {ActorRestaurant, ActorAdmin},
+	},
+	models.OrderStatusPreparing: {
+		models.OrderStatusReady:     {ActorRestaurant, ActorAdmin},
+		models.OrderStatusCancelled: {ActorAdmin},
+	},
+	models.OrderStatusReady: {
+		models.OrderStatusOutForDelivery: {ActorRider, ActorRestaurant, ActorAdmin},
+		models.OrderStatusDelivered:      {ActorRestaurant, ActorAdmin},
+		models.OrderStatusCancelled:      {ActorAdmin},
+	},
+	models.OrderStatusOutForDelivery: {
+		models.OrderStatusDelivered: {ActorRider, ActorAdmin},
+		models.OrderStatusCancelled: {ActorAdmin},
+	},
+	models.OrderStatusDelivered: {},
+	models.OrderStatusCancelled: {},
+}
+
+// isKnownOrderStatus reports whether status is part of the state machine
+func isKnownOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// IsTerminalOrderStatus reports whether no further transitions are possible
+func IsTerminalOrderStatus(status string) bool {
+	next, ok := orderTransitions[status]
+	return ok && len(next) == 0
+}
+
+/*
+checkOrderTransition returns "" when actor may move an order from -> to,
+otherwise "invalid_transition" (the move does not exist) or "forbidden".
+*/
+func checkOrderTransition(from, to, actor string) string {
+	allowed, ok := orderTransitions[from][to]
+	if !ok {
+		return "invalid_transition"
+	}
+	for _, a := range allowed {
+		if a == actor {
+			return ""
+		}
+	}
+	return "forbidden"
+}
+
+/*
+resolveOrderActor works out in which capacity the caller acts on an order.
+Admins win over ownership; an empty string means the caller has no relation to the order.
+*/
+func resolveOrderActor(order *models.Order, rest *models.Restaurant, tokenUserID int64, role string) string {
+	upper := strings.ToUpper(role)
+	if strings.Contains(upper, "ADMIN") {
+		return ActorAdmin
+	}
+	if tokenUserID != 0 && rest != nil && rest.OwnerAuthUserID != nil && *rest.OwnerAuthUserID == tokenUserID {
+		return ActorRestaurant
+	}
+	if tokenUserID != 0 && order.UserID == tokenUserID {
+		return ActorCustomer
+	}
+	// riders act only on orders assigned to them
+	if tokenUserID != 0 && order.RiderID != nil && *order.RiderID == tokenUserID {
+		return ActorRider
+	}
+	return ""
+}
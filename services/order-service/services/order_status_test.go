@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+func TestCheckOrderTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		actor    string
+		want     string
+	}{
+		{"restaurant confirms", models.OrderStatusPlaced, models.OrderStatusConfirmed, ActorRestaurant, ""},
+		{"customer cannot confirm", models.OrderStatusPlaced, models.OrderStatusConfirmed, ActorCustomer, "forbidden"},
+		{"customer cancels placed", models.OrderStatusPlaced, models.OrderStatusCancelled, ActorCustomer, ""},
+		{"customer cannot cancel confirmed", models.OrderStatusConfirmed, models.OrderStatusCancelled, ActorCustomer, "forbidden"},
+		{"scheduler releases scheduled", models.OrderStatusScheduled, models.OrderStatusPlaced, ActorSystem, ""},
+		{"restaurant cannot release scheduled", models.OrderStatusScheduled, models.OrderStatusPlaced, ActorRestaurant, "forbidden"},
+		{"customer cancels scheduled", models.OrderStatusScheduled, models.OrderStatusCancelled, ActorCustomer, ""},
+		{"kitchen starts", models.OrderStatusConfirmed, models.OrderStatusPreparing, ActorRestaurant, ""},
+		{"only admins cancel while preparing", models.OrderStatusPreparing, models.OrderStatusCancelled, ActorRestaurant, "forbidden"},
+		{"admin cancels while preparing", models.OrderStatusPreparing, models.OrderStatusCancelled, ActorAdmin, ""},
+		{"rider picks up", models.OrderStatusReady, models.OrderStatusOutForDelivery, ActorRider, ""},
+		{"counter hand-over", models.OrderStatusReady, models.OrderStatusDelivered, ActorRestaurant, ""},
+		{"rider cannot skip the road", models.OrderStatusReady, models.OrderStatusDelivered, ActorRider, "forbidden"},
+		{"rider delivers", models.OrderStatusOutForDelivery, models.OrderStatusDelivered, ActorRider, ""},
+		{"no skipping the kitchen", models.OrderStatusPlaced, models.OrderStatusReady, ActorAdmin, "invalid_transition"},
+		{"no going back", models.OrderStatusPreparing, models.OrderStatusConfirmed, ActorAdmin, "invalid_transition"},
+		{"delivered is terminal", models.OrderStatusDelivered, models.OrderStatusCancelled, ActorAdmin, "invalid_transition"},
+		{"cancelled is terminal", models.OrderStatusCancelled, models.OrderStatusPlaced, ActorAdmin, "invalid_transition"},
+		{"unknown status", "LOST", models.OrderStatusPlaced, ActorAdmin, "invalid_transition"},
+		{"no actor", models.OrderStatusPlaced, models.OrderStatusCancelled, "", "forbidden"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkOrderTransition(tc.from, tc.to, tc.actor); got != tc.want {
+				t.Errorf("checkOrderTransition(%s, %s, %s) = %q, want %q", tc.from, tc.to, tc.actor, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOrderStatusKinds(t *testing.T) {
+	tests := []struct {
+		status       string
+		wantKnown    bool
+		wantTerminal bool
+	}{
+		{models.OrderStatusScheduled, true, false},
+		{models.OrderStatusPlaced, true, false},
+		{models.OrderStatusReady, true, false},
+		{models.OrderStatusDelivered, true, true},
+		{models.OrderStatusCancelled, true, true},
+		{"LOST", false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.status, func(t *testing.T) {
+			if got := isKnownOrderStatus(tc.status); got != tc.wantKnown {
+				t.Errorf("isKnownOrderStatus = %v, want %v", got, tc.wantKnown)
+			}
+			if got := IsTerminalOrderStatus(tc.status); got != tc.wantTerminal {
+				t.Errorf("IsTerminalOrderStatus = %v, want %v", got, tc.wantTerminal)
+			}
+		})
+	}
+}
+
+func TestResolveOrderActor(t *testing.T) {
+	owner, rider := int64(20), int64(30)
+	order := &models.Order{UserID: 10, RiderID: &rider}
+	rest := &models.Restaurant{OwnerAuthUserID: &owner}
+
+	tests := []struct {
+		name   string
+		rest   *models.Restaurant
+		userID int64
+		role   string
+		want   string
+	}{
+		{"admin wins", rest, 10, "SUPERADMIN", ActorAdmin},
+		{"owner", rest, 20, "RESTAURANT_OWNER", ActorRestaurant},
+		{"customer", rest, 10, "CUSTOMER", ActorCustomer},
+		{"assigned rider", rest, 30, "RIDER", ActorRider},
+		{"another rider", rest, 31, "RIDER", ""},
+		{"restaurant unknown", nil, 20, "RESTAURANT_OWNER", ""},
+		{"anonymous", rest, 0, "", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveOrderActor(order, tc.rest, tc.userID, tc.role); got != tc.want {
+				t.Errorf("resolveOrderActor = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// replay is capped so a client that was away for days gets a bounded burst
+const maxReplayEvents = 500
+
+// AuthorizeRestaurantStaff allows the restaurant's owner and admins
+func (s *orderService) AuthorizeRestaurantStaff(restaurantID int64, tokenUserID int64, role string) error {
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	upper := strings.ToUpper(role)
+	if rest.OwnerAuthUserID == nil || (*rest.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// RestaurantEventsSince returns what a kitchen display missed after position afterSeq
+func (s *orderService) RestaurantEventsSince(restaurantID, afterSeq int64) ([]models.OrderEvent, error) {
+	return s.events.ListForRestaurantSince(restaurantID, afterSeq, maxReplayEvents)
+}
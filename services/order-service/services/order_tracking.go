@@ -0,0 +1,136 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+// positions closer together than this are dropped
+const riderLocationInterval = 5 * time.Second
+
+// a rider can be (re)assigned until the order leaves the restaurant
+var riderAssignableStatuses = []string{models.OrderStatusConfirmed, models.OrderStatusPreparing, models.OrderStatusReady}
+
+/*
+AssignRider puts a rider on a delivery order. The restaurant or an admin may assign
+any rider; a user with a rider role may claim an unassigned order for themselves.
+*/
+func (s *orderService) AssignRider(orderID int64, riderID *int64, tokenUserID int64, role string) (*models.Order, error) {
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return nil, err
+	}
+	if order.OrderType != "" && order.OrderType != "DELIVERY" {
+		return nil, errors.New("not_delivery")
+	}
+	upper := strings.ToUpper(role)
+	claim := false
+	switch {
+	case actor == ActorRestaurant || actor == ActorAdmin:
+		if riderID == nil {
+			return nil, errors.New("rider_id required")
+		}
+	case strings.Contains(upper, "RIDER") || strings.Contains(upper, "DELIVERY"):
+		if riderID != nil && *riderID != tokenUserID {
+			return nil, errors.New("forbidden")
+		}
+		if order.RiderID != nil {
+			return nil, errors.New("rider_taken")
+		}
+		riderID = &tokenUserID
+		claim = true
+	default:
+		return nil, errors.New("forbidden")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AssignRider(tx, orderID, *riderID, riderAssignableStatuses, claim); err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, repository.ErrRiderTaken) {
+			return nil, errors.New("rider_taken")
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invalid_transition")
+		}
+		return nil, err
+	}
+	if err := s.recordEvent(tx, order, models.OrderEventRiderAssigned, map[string]interface{}{
+		"rider_id": *riderID,
+	}); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	order.RiderID = riderID
+	return order, nil
+}
+
+// UpdateRiderLocation records the assigned rider's position while the order is on its way
+func (s *orderService) UpdateRiderLocation(orderID int64, lat, lon float64, tokenUserID int64, role string) error {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return errors.New("invalid_location")
+	}
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, role)
+	if err != nil {
+		return err
+	}
+	if actor != ActorRider {
+		return errors.New("forbidden")
+	}
+	if order.OrderStatus != models.OrderStatusReady && order.OrderStatus != models.OrderStatusOutForDelivery {
+		return errors.New("invalid_transition")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	updated, err := s.repo.UpdateRiderLocation(tx, orderID, tokenUserID, lat, lon, riderLocationInterval)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if !updated {
+		// throttled
+		return tx.Rollback()
+	}
+	if err := s.recordEvent(tx, order, models.OrderEventRiderLocation, map[string]interface{}{
+		"latitude":    lat,
+		"longitude":   lon,
+		"recorded_at": time.Now().UTC(),
+	}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// AuthorizeOrderTracking returns the order when the caller is the customer who placed it
+func (s *orderService) AuthorizeOrderTracking(orderID int64, tokenUserID int64) (*models.Order, error) {
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("not_found")
+	}
+	if tokenUserID == 0 || order.UserID != tokenUserID {
+		return nil, errors.New("forbidden")
+	}
+	return order, nil
+}
+
+// OrderEventsSince returns an order's events after position afterSeq (0 = from the beginning)
+func (s *orderService) OrderEventsSince(orderID, afterSeq int64) ([]models.OrderEvent, error) {
+	return s.events.ListForOrderSince(orderID, afterSeq, maxReplayEvents)
+}
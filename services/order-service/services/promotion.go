@@ -0,0 +1,436 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+// PromotionService manages coupons and automatic offers and previews them on a basket
+type PromotionService interface {
+	// restaurantID nil is a platform promotion, for admins only
+	CreatePromotion(p *models.Promotion, tokenUserID int64, role string) (*models.Promotion, error)
+	ListPromotions(restaurantID *int64, tokenUserID int64, role string) ([]models.Promotion, error)
+	DeactivatePromotion(restaurantID *int64, promotionID int64, tokenUserID int64, role string) error
+	// Validate prices the basket like PlaceOrder would, with the coupon or the best automatic offer
+	Validate(order *models.Order, items []models.OrderItem) (*models.Order, *models.Promotion, error)
+}
+
+type promotionService struct {
+	repo     repository.PromotionRepo
+	restRepo repository.RestaurantRepo
+	orders   OrderService
+}
+
+func NewPromotionService(r repository.PromotionRepo, restRepo repository.RestaurantRepo, orders OrderService) PromotionService {
+	return &promotionService{repo: r, restRepo: restRepo, orders: orders}
+}
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+func (s *promotionService) CreatePromotion(p *models.Promotion, tokenUserID int64, role string) (*models.Promotion, error) {
+	if err := s.authorize(p.RestaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return nil, errors.New("name_required")
+	}
+	p.DiscountType = strings.ToUpper(strings.TrimSpace(p.DiscountType))
+	switch p.DiscountType {
+	case models.PromotionFlat:
+		p.MaxDiscount = nil
+	case models.PromotionPercent:
+		if p.DiscountValue > 100 {
+			return nil, errors.New("invalid_discount")
+		}
+	default:
+		return nil, errors.New("invalid_discount_type")
+	}
+	if p.DiscountValue <= 0 || (p.MaxDiscount != nil && *p.MaxDiscount <= 0) || p.MinSubtotal < 0 {
+		return nil, errors.New("invalid_discount")
+	}
+	p.DiscountValue = roundMoney(p.DiscountValue)
+	p.MinSubtotal = roundMoney(p.MinSubtotal)
+	if p.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*p.Code))
+		if !promoCodePattern.MatchString(code) {
+			return nil, errors.New("invalid_code")
+		}
+		existing, err := s.repo.GetActiveByCode(code)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.New("code_taken")
+		}
+		p.Code = &code
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return nil, errors.New("invalid_window")
+	}
+	if (p.DailyStart == "") != (p.DailyEnd == "") {
+		return nil, errors.New("invalid_window")
+	}
+	if p.DailyStart != "" {
+		start, ok1 := businesstime.ParseTimeOfDay(p.DailyStart)
+		end, ok2 := businesstime.ParseTimeOfDay(p.DailyEnd)
+		if !ok1 || !ok2 || start == end {
+			return nil, errors.New("invalid_window")
+		}
+	}
+	if (p.UsageLimit != nil && *p.UsageLimit <= 0) || (p.PerUserLimit != nil && *p.PerUserLimit <= 0) {
+		return nil, errors.New("invalid_limit")
+	}
+	if p.RestaurantID != nil {
+		// a restaurant's promotion is its own by definition
+		p.RestaurantIDs = nil
+	}
+	tags := p.Tags[:0]
+	for _, t := range p.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	p.Tags = tags
+	p.IsActive = true
+	if err := s.repo.Create(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *promotionService) ListPromotions(restaurantID *int64, tokenUserID int64, role string) ([]models.Promotion, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.List(restaurantID)
+}
+
+// DeactivatePromotion ends a promotion; orders that already used it keep their discount
+func (s *promotionService) DeactivatePromotion(restaurantID *int64, promotionID int64, tokenUserID int64, role string) error {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return err
+	}
+	if err := s.repo.Deactivate(restaurantID, promotionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("not_found")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *promotionService) Validate(order *models.Order, items []models.OrderItem) (*models.Order, *models.Promotion, error) {
+	if order == nil || len(items) == 0 {
+		return nil, nil, errors.New("items_required")
+	}
+	if err := s.orders.PreviewOrder(order, items); err != nil {
+		return nil, nil, err
+	}
+	if order.PromotionID == nil {
+		return order, nil, nil
+	}
+	p, err := s.repo.GetByID(*order.PromotionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, p, nil
+}
+
+// authorize: restaurant promotions belong to the owner, platform ones to admins
+func (s *promotionService) authorize(restaurantID *int64, tokenUserID int64, role string) error {
+	upper := strings.ToUpper(role)
+	if restaurantID == nil {
+		if !strings.Contains(upper, "ADMIN") {
+			return errors.New("forbidden")
+		}
+		return nil
+	}
+	rest, err := s.restRepo.GetByID(*restaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	if rest.OwnerAuthUserID == nil || (*rest.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+/* ---------- pricing ---------- */
+
+/*
+applyPromotion sets the order's discount during pricing and returns the line amounts it
+is spread over for GST (nil: all lines). New orders get the coupon in PromoCode, or
+without one the automatic offer worth most; a coupon that does not apply is reported on
+verr. Amended orders keep their promotion, re-worked on the new basket without the usage
+and time checks they passed when placed; it drops off if the basket no longer qualifies.
+Orders without a promotion keep the discount they have.
+*/
+func (s *orderService) applyPromotion(order *models.Order, items []models.OrderItem, menu map[int64]models.MenuItem, subtotal float64, now time.Time, verr *OrderValidationError) ([]float64, error) {
+	if order.ID != 0 {
+		if order.PromotionID == nil {
+			return nil, nil
+		}
+		p, err := s.promos.GetByID(*order.PromotionID)
+		if err != nil {
+			return nil, err
+		}
+		discount, weights, reason := promotionDiscount(p, items, menu, subtotal)
+		if reason != "" {
+			order.DiscountAmount = 0
+			order.PromotionID = nil
+			order.PromoCode = nil
+			return nil, nil
+		}
+		order.DiscountAmount = discount
+		return weights, nil
+	}
+
+	claimed := order.DiscountAmount
+	order.DiscountAmount = 0
+	order.PromotionID = nil
+	var best *models.Promotion
+	var bestDiscount float64
+	var bestWeights []float64
+
+	code := ""
+	if order.PromoCode != nil {
+		code = strings.ToUpper(strings.TrimSpace(*order.PromoCode))
+	}
+	if code != "" {
+		p, err := s.promos.GetActiveByCode(code)
+		if err != nil {
+			return nil, err
+		}
+		reason := "unknown or expired code"
+		if p != nil {
+			reason = promotionAvailable(p, order.RestaurantID, now)
+		}
+		var discount float64
+		var weights []float64
+		if reason == "" {
+			discount, weights, reason = promotionDiscount(p, items, menu, subtotal)
+		}
+		if reason == "" {
+			if reason, err = s.promotionUsage(nil, p, order.UserID, 0); err != nil {
+				return nil, err
+			}
+		}
+		if reason != "" {
+			verr.add(-1, nil, "promoCode", reason)
+			return nil, nil
+		}
+		best, bestDiscount, bestWeights = p, discount, weights
+		order.PromoCode = &code
+	} else {
+		order.PromoCode = nil
+		offers, err := s.promos.ListAutomatic(order.RestaurantID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range offers {
+			p := &offers[i]
+			if promotionAvailable(p, order.RestaurantID, now) != "" {
+				continue
+			}
+			discount, weights, reason := promotionDiscount(p, items, menu, subtotal)
+			if reason != "" || discount <= bestDiscount {
+				continue
+			}
+			reason, err := s.promotionUsage(nil, p, order.UserID, 0)
+			if err != nil {
+				return nil, err
+			}
+			if reason == "" {
+				best, bestDiscount, bestWeights = p, discount, weights
+			}
+		}
+	}
+
+	if claimed != 0 && !moneyEqual(claimed, bestDiscount) {
+		verr.mismatch(-1, nil, "discountAmount", bestDiscount, claimed)
+	}
+	if best != nil {
+		order.DiscountAmount = bestDiscount
+		order.PromotionID = &best.ID
+	}
+	return bestWeights, nil
+}
+
+/*
+redeemPromotion records the order's discount inside the order transaction. The promotion
+row stays locked until commit, so two orders cannot both take the last use of a limited
+promotion; limits are checked again under the lock. First-order promotions lock the
+customer as well, so two first orders cannot each use a different one.
+*/
+func (s *orderService) redeemPromotion(tx *sql.Tx, order *models.Order) error {
+	if order.PromotionID == nil {
+		return nil
+	}
+	active, err := s.promos.LockPromotion(tx, *order.PromotionID)
+	if err != nil {
+		return err
+	}
+	p, err := s.promos.GetByID(*order.PromotionID)
+	if err != nil {
+		return err
+	}
+	reason := "promotion has ended"
+	if active && p != nil {
+		if reason, err = s.promotionUsage(tx, p, order.UserID, order.ID); err != nil {
+			return err
+		}
+	}
+	if reason != "" {
+		verr := &OrderValidationError{}
+		verr.add(-1, nil, "promoCode", reason)
+		return verr
+	}
+	return s.promos.InsertRedemption(tx, &models.PromotionRedemption{
+		PromotionID:    p.ID,
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		Code:           order.PromoCode,
+		DiscountAmount: order.DiscountAmount,
+	})
+}
+
+// promotionUsage checks usage limits and first-order promotions; a non-empty reason says why the customer cannot use it
+func (s *orderService) promotionUsage(tx *sql.Tx, p *models.Promotion, userID, orderID int64) (string, error) {
+	if p.UsageLimit != nil || p.PerUserLimit != nil {
+		total, byUser, err := s.promos.CountRedemptions(tx, p.ID, userID)
+		if err != nil {
+			return "", err
+		}
+		if p.UsageLimit != nil && total >= *p.UsageLimit {
+			return "promotion is fully redeemed", nil
+		}
+		if p.PerUserLimit != nil && byUser >= *p.PerUserLimit {
+			return "you have already used this promotion", nil
+		}
+	}
+	if p.FirstOrderOnly {
+		if tx != nil {
+			// the other order counts once its transaction commits
+			if err := s.promos.LockCustomer(tx, userID); err != nil {
+				return "", err
+			}
+		}
+		n, err := s.promos.CountCustomerOrders(tx, userID, orderID)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			return "only valid on your first order", nil
+		}
+	}
+	return "", nil
+}
+
+// promotionAvailable checks where and when p applies; a non-empty reason says why not
+func promotionAvailable(p *models.Promotion, restaurantID int64, now time.Time) string {
+	if !p.IsActive {
+		return "unknown or expired code"
+	}
+	if p.RestaurantID != nil && *p.RestaurantID != restaurantID {
+		return "not valid at this restaurant"
+	}
+	if p.RestaurantID == nil && len(p.RestaurantIDs) > 0 {
+		found := false
+		for _, id := range p.RestaurantIDs {
+			if id == restaurantID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "not valid at this restaurant"
+		}
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return "promotion has not started yet"
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return "unknown or expired code"
+	}
+	if p.DailyStart != "" && p.DailyEnd != "" {
+		start, ok1 := businesstime.ParseTimeOfDay(p.DailyStart)
+		end, ok2 := businesstime.ParseTimeOfDay(p.DailyEnd)
+		if ok1 && ok2 {
+			local := now.In(businesstime.Location)
+			tod := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+			inside := tod >= start && tod < end
+			if end <= start {
+				// runs past midnight
+				inside = tod >= start || tod < end
+			}
+			if !inside {
+				return "only valid between " + p.DailyStart + " and " + p.DailyEnd
+			}
+		}
+	}
+	return ""
+}
+
+/*
+promotionDiscount is what p takes off the basket. Lines with one of p's tags count
+towards it, every line when p has none; the minimum is checked on the whole subtotal.
+Percent discounts stop at MaxDiscount and nothing exceeds the qualifying amount.
+It returns the qualifying line amounts for spreading the discount, or a reason why p
+does not apply.
+*/
+func promotionDiscount(p *models.Promotion, items []models.OrderItem, menu map[int64]models.MenuItem, subtotal float64) (float64, []float64, string) {
+	if p == nil {
+		return 0, nil, "unknown or expired code"
+	}
+	if subtotal < p.MinSubtotal {
+		return 0, nil, "order subtotal is below the minimum for this promotion"
+	}
+	weights := make([]float64, len(items))
+	eligible := 0.0
+	for i, it := range items {
+		if len(p.Tags) > 0 {
+			if it.MenuItemID == nil || !hasAnyTag(menu[*it.MenuItemID].Tags, p.Tags) {
+				continue
+			}
+		}
+		weights[i] = it.TotalPrice
+		eligible += it.TotalPrice
+	}
+	eligible = roundMoney(eligible)
+	if eligible <= 0 {
+		return 0, nil, "no item in the order qualifies for this promotion"
+	}
+	discount := p.DiscountValue
+	if p.DiscountType == models.PromotionPercent {
+		discount = eligible * p.DiscountValue / 100
+		if p.MaxDiscount != nil && discount > *p.MaxDiscount {
+			discount = *p.MaxDiscount
+		}
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+	return roundMoney(discount), weights, ""
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, t := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(t, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
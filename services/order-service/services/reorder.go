@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+// where a reorder goes
+const (
+	ReorderToCart  = "CART"
+	ReorderToOrder = "ORDER"
+)
+
+// why a past line did not make it into the reorder, or what changed about it
+const (
+	ReorderItemDeleted    = "ITEM_DELETED"
+	ReorderOutOfStock     = "OUT_OF_STOCK"
+	ReorderOptionsInvalid = "OPTIONS_INVALID"
+	ReorderQuantityLimit  = "QUANTITY_LIMIT"
+	ReorderPriceChanged   = "PRICE_CHANGED"
+)
+
+// ReorderRequest says where the copied lines go; Checkout is only used for ORDER
+type ReorderRequest struct {
+	Target      string // CART (default) or ORDER
+	ReplaceCart bool   // empty a cart holding another restaurant's items
+	// AcceptChanges places an ORDER even when lines were dropped or repriced
+	AcceptChanges bool
+	Checkout      CartCheckout
+}
+
+// ReorderIssue is one line of the past order that was dropped or whose price moved
+type ReorderIssue struct {
+	Line         int      `json:"line"` // index into the past order's items
+	ItemID       int64    `json:"itemId"`
+	MenuItemID   *int64   `json:"menuItemId,omitempty"`
+	Name         string   `json:"name"`
+	Quantity     int      `json:"quantity"`
+	Reason       string   `json:"reason"`
+	OldUnitPrice *float64 `json:"oldUnitPrice,omitempty"`
+	NewUnitPrice *float64 `json:"newUnitPrice,omitempty"`
+}
+
+// ReorderResult is the cart or order the reorder produced plus everything that differs from the past order
+type ReorderResult struct {
+	SourceOrderID int64          `json:"sourceOrderId"`
+	Target        string         `json:"target"`
+	Cart          *models.Cart   `json:"cart,omitempty"`
+	Order         *models.Order  `json:"order,omitempty"`
+	Skipped       []ReorderIssue `json:"skipped"`
+	PriceChanges  []ReorderIssue `json:"priceChanges"`
+}
+
+// ReorderSource only lets the customer who placed the order copy it
+func (s *orderService) ReorderSource(orderID int64, tokenUserID int64) (*models.Order, error) {
+	order, actor, err := s.loadWithActor(orderID, tokenUserID, "")
+	if err != nil {
+		return nil, err
+	}
+	if actor != ActorCustomer {
+		return nil, errors.New("forbidden")
+	}
+	items, err := s.repo.GetItemsForOrders([]int64{order.ID})
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items[order.ID]
+	return order, nil
+}
+
+/*
+Reorder copies a past order's lines against today's menu. Lines whose menu item is gone,
+moved to another restaurant or out of stock, and lines whose options no longer validate,
+are dropped and reported in Skipped; lines that kept going but cost something else now
+are reported in PriceChanges. With target CART the rest is added to the user's cart (merged
+like POST /cart/items); with ORDER it is placed right away through PlaceOrder, but only if
+nothing changed or the caller accepted the changes. When nothing is left to order the
+error is "nothing_to_reorder" and the result still explains why.
+*/
+func (s *cartService) Reorder(userID, orderID int64, req ReorderRequest) (*ReorderResult, error) {
+	if req.Target == "" {
+		req.Target = ReorderToCart
+	}
+	if req.Target != ReorderToCart && req.Target != ReorderToOrder {
+		return nil, errors.New("invalid_target")
+	}
+	past, err := s.orders.ReorderSource(orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := &ReorderResult{
+		SourceOrderID: past.ID,
+		Target:        req.Target,
+		Skipped:       []ReorderIssue{},
+		PriceChanges:  []ReorderIssue{},
+	}
+	lines, err := s.reorderLines(past, res)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Target == ReorderToCart {
+		if len(lines) == 0 {
+			return res, errors.New("nothing_to_reorder")
+		}
+		cart, err := s.repo.GetOrCreate(userID)
+		if err != nil {
+			return nil, err
+		}
+		current, err := s.repo.GetItems(cart.ID)
+		if err != nil {
+			return nil, err
+		}
+		// decide up front so a mismatch leaves the cart untouched
+		if len(current) > 0 && cart.RestaurantID != nil && *cart.RestaurantID != past.RestaurantID && !req.ReplaceCart {
+			return res, errors.New("restaurant_mismatch")
+		}
+		var filled *models.Cart
+		replace := req.ReplaceCart
+		for _, l := range lines {
+			item := &models.CartItem{
+				MenuItemID:          *past.Items[l].MenuItemID,
+				Quantity:            past.Items[l].Quantity,
+				Options:             past.Items[l].Options,
+				SpecialInstructions: past.Items[l].SpecialInstructions,
+			}
+			updated, err := s.AddItem(userID, item, replace)
+			if err != nil {
+				switch err.Error() {
+				case "invalid_quantity":
+					res.Skipped = append(res.Skipped, reorderIssue(past, l, ReorderQuantityLimit))
+					continue
+				case "not_found", "item_unavailable":
+					// changed since we looked
+					res.Skipped = append(res.Skipped, reorderIssue(past, l, ReorderOutOfStock))
+					continue
+				}
+				return nil, err
+			}
+			filled = updated
+			replace = false
+		}
+		if filled == nil {
+			return res, errors.New("nothing_to_reorder")
+		}
+		res.Cart = filled
+		return res, nil
+	}
+
+	if len(lines) == 0 {
+		return res, errors.New("nothing_to_reorder")
+	}
+	if (len(res.Skipped) > 0 || len(res.PriceChanges) > 0) && !req.AcceptChanges {
+		return res, errors.New("reorder_changed")
+	}
+	now := time.Now().UTC()
+	order := newCheckoutOrder(userID, past.RestaurantID, req.Checkout, now)
+	items := make([]models.OrderItem, 0, len(lines))
+	for _, l := range lines {
+		it := past.Items[l]
+		items = append(items, models.OrderItem{
+			MenuItemID:          it.MenuItemID,
+			Quantity:            it.Quantity,
+			Options:             it.Options,
+			SpecialInstructions: it.SpecialInstructions,
+			CreatedAt:           &now,
+		})
+	}
+	id, err := s.orders.PlaceOrder(order, items)
+	if err != nil {
+		return res, err
+	}
+	order.ID = id
+	order.Items = items
+	res.Order = order
+	return res, nil
+}
+
+// reorderLines checks every past line against the menu and returns the indexes of those that can be ordered again
+func (s *cartService) reorderLines(past *models.Order, res *ReorderResult) ([]int, error) {
+	ids := make([]int64, 0, len(past.Items))
+	for _, it := range past.Items {
+		if it.MenuItemID != nil {
+			ids = append(ids, *it.MenuItemID)
+		}
+	}
+	menu, err := s.menuRepo.GetMenuItemsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.MenuItem, len(menu))
+	for _, m := range menu {
+		byID[m.ID] = m
+	}
+
+	var ok []int
+	for i := range past.Items {
+		it := &past.Items[i]
+		if it.MenuItemID == nil {
+			res.Skipped = append(res.Skipped, reorderIssue(past, i, ReorderItemDeleted))
+			continue
+		}
+		m, found := byID[*it.MenuItemID]
+		if !found || m.RestaurantID != past.RestaurantID {
+			res.Skipped = append(res.Skipped, reorderIssue(past, i, ReorderItemDeleted))
+			continue
+		}
+		if m.Availability != models.AvailabilityInStock {
+			res.Skipped = append(res.Skipped, reorderIssue(past, i, ReorderOutOfStock))
+			continue
+		}
+		// picks are checked against the item's modifier groups as they are now
+		opts, delta, reason := resolveOptions(m, it.Options)
+		if reason != "" {
+			res.Skipped = append(res.Skipped, reorderIssue(past, i, ReorderOptionsInvalid))
+			continue
+		}
+		it.Options = opts
+		if now := roundMoney(m.Price + delta); !moneyEqual(now, it.UnitPrice) {
+			issue := reorderIssue(past, i, ReorderPriceChanged)
+			old := it.UnitPrice
+			issue.OldUnitPrice = &old
+			issue.NewUnitPrice = &now
+			res.PriceChanges = append(res.PriceChanges, issue)
+		}
+		ok = append(ok, i)
+	}
+	return ok, nil
+}
+
+func reorderIssue(past *models.Order, line int, reason string) ReorderIssue {
+	it := past.Items[line]
+	return ReorderIssue{
+		Line:       line,
+		ItemID:     it.ID,
+		MenuItemID: it.MenuItemID,
+		Name:       it.Name,
+		Quantity:   it.Quantity,
+		Reason:     reason,
+	}
+}
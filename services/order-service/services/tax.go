@@ -0,0 +1,202 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/repository"
+)
+
+// TaxService manages the GST rates a restaurant's items are taxed at
+type TaxService interface {
+	ListRules(restaurantID int64, tokenUserID int64, role string) ([]models.TaxRule, error)
+	CreateRule(rule *models.TaxRule, tokenUserID int64, role string) (*models.TaxRule, error)
+	DeleteRule(restaurantID, ruleID int64, tokenUserID int64, role string) error
+}
+
+type taxService struct {
+	repo     repository.TaxRepo
+	restRepo repository.RestaurantRepo
+	menuRepo repository.MenuRepo
+}
+
+func NewTaxService(r repository.TaxRepo, restRepo repository.RestaurantRepo, menuRepo repository.MenuRepo) TaxService {
+	return &taxService{repo: r, restRepo: restRepo, menuRepo: menuRepo}
+}
+
+// ListRules is what the restaurant's items are taxed at: its own rules and the platform defaults
+func (s *taxService) ListRules(restaurantID int64, tokenUserID int64, role string) ([]models.TaxRule, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRules(restaurantID)
+}
+
+// CreateRule adds a restaurant rule for one category, one tag, or (with neither) all its other items
+func (s *taxService) CreateRule(rule *models.TaxRule, tokenUserID int64, role string) (*models.TaxRule, error) {
+	if rule.RestaurantID == nil {
+		return nil, errors.New("not_found")
+	}
+	if err := s.authorize(*rule.RestaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	rule.Label = strings.TrimSpace(rule.Label)
+	if rule.Label == "" {
+		return nil, errors.New("label_required")
+	}
+	if rule.RatePercent < 0 || rule.RatePercent > 100 {
+		return nil, errors.New("invalid_rate")
+	}
+	rule.RatePercent = roundMoney(rule.RatePercent)
+	if rule.Tag != nil {
+		tag := strings.TrimSpace(*rule.Tag)
+		if tag == "" {
+			rule.Tag = nil
+		} else {
+			rule.Tag = &tag
+		}
+	}
+	if rule.CategoryID != nil && rule.Tag != nil {
+		return nil, errors.New("category_or_tag")
+	}
+	if rule.CategoryID != nil {
+		cats, err := s.menuRepo.GetCategories(*rule.RestaurantID)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, c := range cats {
+			if c.ID == *rule.CategoryID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("category_not_found")
+		}
+	}
+	if err := s.repo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a restaurant rule; platform defaults cannot be deleted this way
+func (s *taxService) DeleteRule(restaurantID, ruleID int64, tokenUserID int64, role string) error {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRule(restaurantID, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("not_found")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *taxService) authorize(restaurantID int64, tokenUserID int64, role string) error {
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	upper := strings.ToUpper(role)
+	if rest.OwnerAuthUserID == nil || (*rest.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+/*
+matchTaxRule picks the rule for a menu item. The most specific match wins: category,
+then tag, then catch-all; at the same level the restaurant's rule beats the platform
+default, and the older rule beats the newer. nil when nothing matches.
+*/
+func matchTaxRule(rules []models.TaxRule, m models.MenuItem) *models.TaxRule {
+	var best *models.TaxRule
+	bestRank := 0
+	for i := range rules {
+		r := &rules[i]
+		rank := 0
+		switch {
+		case r.CategoryID != nil:
+			if m.CategoryID != nil && *m.CategoryID == *r.CategoryID {
+				rank = 6
+			}
+		case r.Tag != nil:
+			for _, t := range m.Tags {
+				if strings.EqualFold(t, *r.Tag) {
+					rank = 4
+					break
+				}
+			}
+		default:
+			rank = 2
+		}
+		if rank == 0 {
+			continue
+		}
+		if r.RestaurantID != nil {
+			rank++
+		}
+		if rank > bestRank || (rank == bestRank && r.ID < best.ID) {
+			best, bestRank = r, rank
+		}
+	}
+	return best
+}
+
+/*
+applyTax works out the GST of every priced line and returns the order's total tax.
+The taxable value of a line is its total less its share of the order discount, spread
+pro rata over discountWeights (the line totals when nil).
+When the place of supply (the delivery state, or the restaurant's own state for pickup,
+dine-in and orders without one) is the restaurant's state the rate is split evenly into
+CGST and SGST, otherwise it is charged as IGST. The delivery fee is not taxed here.
+*/
+func applyTax(rules []models.TaxRule, rest *models.Restaurant, order *models.Order, items []models.OrderItem, menu map[int64]models.MenuItem, discountWeights []float64) float64 {
+	supply := strings.TrimSpace(order.DeliveryState)
+	if supply == "" || !isDeliveryOrder(order) {
+		supply = rest.State
+	}
+	interState := supply != "" && strings.TrimSpace(rest.State) != "" && !strings.EqualFold(supply, strings.TrimSpace(rest.State))
+
+	weights := discountWeights
+	if weights == nil {
+		weights = make([]float64, len(items))
+		for i, it := range items {
+			weights[i] = it.TotalPrice
+		}
+	}
+	discounts := make([]float64, len(items))
+	if order.DiscountAmount > 0 {
+		discounts = allocateCents(order.DiscountAmount, weights)
+	}
+
+	total := 0.0
+	for i := range items {
+		it := &items[i]
+		tax := &models.LineTax{TaxableAmount: roundMoney(it.TotalPrice - discounts[i])}
+		if it.MenuItemID != nil {
+			if rule := matchTaxRule(rules, menu[*it.MenuItemID]); rule != nil {
+				tax.Label = rule.Label
+				tax.RatePercent = rule.RatePercent
+			}
+		}
+		if interState {
+			tax.IGSTAmount = roundMoney(tax.TaxableAmount * tax.RatePercent / 100)
+		} else {
+			tax.CGSTAmount = roundMoney(tax.TaxableAmount * tax.RatePercent / 200)
+			tax.SGSTAmount = tax.CGSTAmount
+		}
+		tax.TotalAmount = roundMoney(tax.CGSTAmount + tax.SGSTAmount + tax.IGSTAmount)
+		it.Tax = tax
+		total += tax.TotalAmount
+	}
+	return roundMoney(total)
+}
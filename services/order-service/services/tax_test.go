@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Gursevak56/food-delivery-platform/services/order-service/models"
+)
+
+func TestMatchTaxRule(t *testing.T) {
+	rules := []models.TaxRule{
+		{ID: 1, Label: "platform default", RatePercent: 5},
+		{ID: 2, RestaurantID: int64Ptr(1), Label: "restaurant default", RatePercent: 5},
+		{ID: 3, Tag: stringPtr("alcohol"), Label: "platform alcohol", RatePercent: 18},
+		{ID: 4, RestaurantID: int64Ptr(1), Tag: stringPtr("Alcohol"), Label: "restaurant alcohol", RatePercent: 28},
+		{ID: 5, CategoryID: int64Ptr(40), Label: "platform beverages", RatePercent: 12},
+		{ID: 6, Label: "newer platform default", RatePercent: 18},
+	}
+
+	tests := []struct {
+		name  string
+		rules []models.TaxRule
+		item  models.MenuItem
+		want  string
+	}{
+		{"restaurant catch-all beats the platform's", rules, models.MenuItem{}, "restaurant default"},
+		{"older rule wins a tie", []models.TaxRule{rules[5], rules[0]}, models.MenuItem{}, "platform default"},
+		{"tag beats catch-all, any case", rules, models.MenuItem{Tags: []string{"ALCOHOL"}}, "restaurant alcohol"},
+		{"category beats tag", rules, models.MenuItem{CategoryID: int64Ptr(40), Tags: []string{"alcohol"}}, "platform beverages"},
+		{"other category falls back", rules, models.MenuItem{CategoryID: int64Ptr(41)}, "restaurant default"},
+		{"no rules", nil, models.MenuItem{}, ""},
+		{"nothing matches", rules[2:3], models.MenuItem{}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ""
+			if r := matchTaxRule(tc.rules, tc.item); r != nil {
+				got = r.Label
+			}
+			if got != tc.want {
+				t.Errorf("matchTaxRule = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	rest := &models.Restaurant{ID: 1, State: "Punjab"}
+	rules := []models.TaxRule{
+		{ID: 1, Label: "GST food", RatePercent: 5},
+		{ID: 2, Tag: stringPtr("alcohol"), Label: "GST liquor", RatePercent: 18},
+	}
+	menu := map[int64]models.MenuItem{
+		101: {ID: 101, Price: 200},
+		102: {ID: 102, Price: 100, Tags: []string{"alcohol"}},
+	}
+	lines := func() []models.OrderItem {
+		return []models.OrderItem{
+			{MenuItemID: int64Ptr(101), Quantity: 1, TotalPrice: 200},
+			{MenuItemID: int64Ptr(102), Quantity: 1, TotalPrice: 100},
+		}
+	}
+
+	type lineTax struct{ taxable, cgst, sgst, igst float64 }
+	tests := []struct {
+		name    string
+		order   models.Order
+		weights []float64
+		want    []lineTax
+		total   float64
+	}{
+		{
+			name:  "pickup is intra-state",
+			order: models.Order{OrderType: "PICKUP"},
+			want:  []lineTax{{200, 5, 5, 0}, {100, 9, 9, 0}},
+			total: 28,
+		},
+		{
+			name:  "delivery in the restaurant's state, any case",
+			order: models.Order{OrderType: "DELIVERY", DeliveryState: " punjab "},
+			want:  []lineTax{{200, 5, 5, 0}, {100, 9, 9, 0}},
+			total: 28,
+		},
+		{
+			name:  "delivery to another state is IGST",
+			order: models.Order{OrderType: "DELIVERY", DeliveryState: "Haryana"},
+			want:  []lineTax{{200, 0, 0, 10}, {100, 0, 0, 18}},
+			total: 28,
+		},
+		{
+			name:  "pickup ignores a delivery state",
+			order: models.Order{OrderType: "PICKUP", DeliveryState: "Haryana"},
+			want:  []lineTax{{200, 5, 5, 0}, {100, 9, 9, 0}},
+			total: 28,
+		},
+		{
+			name:  "discount spread over line totals",
+			order: models.Order{OrderType: "PICKUP", DiscountAmount: 30},
+			want:  []lineTax{{180, 4.5, 4.5, 0}, {90, 8.1, 8.1, 0}},
+			total: 25.2,
+		},
+		{
+			name:    "discount only on the lines it applies to",
+			order:   models.Order{OrderType: "PICKUP", DiscountAmount: 30},
+			weights: []float64{200, 0},
+			want:    []lineTax{{170, 4.25, 4.25, 0}, {100, 9, 9, 0}},
+			total:   26.5,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			items := lines()
+			order := tc.order
+			total := applyTax(rules, rest, &order, items, menu, tc.weights)
+			if !moneyEqual(total, tc.total) {
+				t.Errorf("total tax = %v, want %v", total, tc.total)
+			}
+			for i, want := range tc.want {
+				got := items[i].Tax
+				if got == nil {
+					t.Fatalf("line %d has no tax", i)
+				}
+				if !moneyEqual(got.TaxableAmount, want.taxable) || !moneyEqual(got.CGSTAmount, want.cgst) ||
+					!moneyEqual(got.SGSTAmount, want.sgst) || !moneyEqual(got.IGSTAmount, want.igst) {
+					t.Errorf("line %d = taxable %v cgst %v sgst %v igst %v, want %+v",
+						i, got.TaxableAmount, got.CGSTAmount, got.SGSTAmount, got.IGSTAmount, want)
+				}
+				if !moneyEqual(got.TotalAmount, want.cgst+want.sgst+want.igst) {
+					t.Errorf("line %d total = %v", i, got.TotalAmount)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ module github.com/Gursevak56/food-delivery-platform/services/payment-service
 
 go 1.24.2
 
-require go.mongodb.org/mongo-driver v1.17.4
+require (
+	github.com/Gursevak56/food-delivery-platform/services/shared v0.0.0
+	github.com/golang-jwt/jwt/v4 v4.5.2
+	go.mongodb.org/mongo-driver v1.17.4
+)
 
 require (
 	github.com/bytedance/sonic v1.11.6 // indirect
@@ -43,3 +47,5 @@ require (
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/Gursevak56/food-delivery-platform/services/shared => ../shared
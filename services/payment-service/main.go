@@ -1,9 +1,17 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/Gursevak56/food-delivery-platform/services/payment-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/payment-service/repository"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/idempotency"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -17,38 +25,218 @@ func init() {
 func main() {
 	r := gin.Default()
 
-	InitDB()
+	database := InitDB()
+	defer database.Close()
+	idemStore := idempotency.NewStore(database)
+	refundRepo := repository.NewRefundRepo(database)
+	paymentRepo := repository.NewPaymentRepo(database)
 	// Health check endpoint
 	// r.GET("/health", func(c *gin.Context) {
 	// 	healthCheckHandler(database)
 	// 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	// })
 
-	// Example user endpoints
-	r.POST("/", initiatePayment)
-	r.GET("/:id", getPaymentStatus)
-	r.PUT("/:id", updatePayment)
-	r.DELETE("/:id", cancelPayment)
+	// payments: created by the order side, settled by the gateway's signed callback on PUT
+	r.POST("/", middleware.AuthRequired(), idempotency.Middleware(idemStore), initiatePayment(paymentRepo))
+	r.GET("/:id", middleware.AuthRequired(), getPaymentStatus(paymentRepo))
+	r.PUT("/:id", middleware.GatewaySignature(), updatePayment(paymentRepo))
+	r.DELETE("/:id", middleware.AuthRequired(), cancelPayment(paymentRepo))
+
+	// refunds for cancelled or amended orders, requested by the order side only
+	r.POST("/refunds", middleware.AuthRequired(), idempotency.Middleware(idemStore), requestRefund(refundRepo))
 
 	r.Run(":8082")
 }
 
-func initiatePayment(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusCreated, gin.H{"message": "payment created"})
+// caller is the authenticated user, and whether it is a backend service or an admin that may act for anyone
+func caller(c *gin.Context) (int64, bool) {
+	var userID int64
+	if raw, ok := c.Get(middleware.ContextUserIDKey); ok && raw != nil {
+		userID = raw.(int64)
+	}
+	role := ""
+	if raw, ok := c.Get(middleware.ContextRoleKey); ok && raw != nil {
+		role = strings.ToUpper(raw.(string))
+	}
+	return userID, role == "SERVICE" || strings.Contains(role, "ADMIN")
+}
+
+type paymentReq struct {
+	Reference   string  `json:"reference" binding:"required"`
+	UserID      int64   `json:"user_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+type updatePaymentReq struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// initiatePayment opens a pending payment; the same reference always maps to the same payment
+func initiatePayment(payments repository.PaymentRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req paymentReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload", "error": err.Error()})
+			return
+		}
+		if req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "amount must be positive"})
+			return
+		}
+		// customers only pay for themselves
+		if userID, trusted := caller(c); !trusted {
+			req.UserID = userID
+		}
+		p, err := payments.Create(&repository.Payment{
+			Reference:   req.Reference,
+			UserID:      req.UserID,
+			Amount:      req.Amount,
+			Description: req.Description,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create payment", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "payment created", "payment": p})
+	}
+}
+
+func getPaymentStatus(payments repository.PaymentRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payment id"})
+			return
+		}
+		p, ok := callerPayment(c, payments, id)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"payment": p})
+	}
+}
+
+// callerPayment loads a payment the caller may see: their own, or any for a service or admin
+func callerPayment(c *gin.Context, payments repository.PaymentRepo, id int64) (*repository.Payment, bool) {
+	p, err := payments.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to fetch payment", "error": err.Error()})
+		return nil, false
+	}
+	if p == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "payment not found"})
+		return nil, false
+	}
+	if userID, trusted := caller(c); !trusted && p.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		return nil, false
+	}
+	return p, true
 }
 
-func getPaymentStatus(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "name": "example"})
+// updatePayment records the gateway outcome: PAID or FAILED, once
+func updatePayment(payments repository.PaymentRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payment id"})
+			return
+		}
+		var req updatePaymentReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload", "error": err.Error()})
+			return
+		}
+		status := strings.ToUpper(strings.TrimSpace(req.Status))
+		if status != repository.PaymentStatusPaid && status != repository.PaymentStatusFailed {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "status must be PAID or FAILED"})
+			return
+		}
+		setPaymentStatus(c, payments, id, status, "payment updated")
+	}
 }
 
-func updatePayment(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"message": "payment updated"})
+// cancelPayment withdraws a payment nobody has paid yet
+func cancelPayment(payments repository.PaymentRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payment id"})
+			return
+		}
+		if _, ok := callerPayment(c, payments, id); !ok {
+			return
+		}
+		setPaymentStatus(c, payments, id, repository.PaymentStatusCancelled, "payment canceled")
+	}
 }
 
-func cancelPayment(c *gin.Context) {
-	// TODO: business logic
-	c.JSON(http.StatusOK, gin.H{"message": "payment canceled"})
+func setPaymentStatus(c *gin.Context, payments repository.PaymentRepo, id int64, status, message string) {
+	p, err := payments.SetStatus(id, status)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update payment", "error": err.Error()})
+			return
+		}
+		existing, err := payments.GetByID(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update payment", "error": err.Error()})
+			return
+		}
+		if existing == nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "payment not found"})
+			return
+		}
+		if existing.Status == status {
+			// repeated callback
+			c.JSON(http.StatusOK, gin.H{"message": message, "payment": existing})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"message": "payment is already " + existing.Status, "payment": existing})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "payment": p})
+}
+
+type refundReq struct {
+	Reference  string  `json:"reference"` // defaults to the order's cancellation refund
+	OrderID    int64   `json:"order_id" binding:"required"`
+	UserID     int64   `json:"user_id" binding:"required"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+}
+
+// requestRefund queues the refund; the money movement itself happens asynchronously
+func requestRefund(refunds repository.RefundRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, trusted := caller(c); !trusted {
+			c.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+			return
+		}
+		var req refundReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload", "error": err.Error()})
+			return
+		}
+		if req.Amount < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "amount must not be negative"})
+			return
+		}
+		if req.Reference == "" {
+			req.Reference = fmt.Sprintf("order-%d-refund", req.OrderID)
+		}
+		rf, err := refunds.Create(&repository.Refund{
+			Reference:  req.Reference,
+			OrderID:    req.OrderID,
+			UserID:     req.UserID,
+			Amount:     req.Amount,
+			ReasonCode: req.ReasonCode,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record refund", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"message": "refund accepted", "refund": rf})
+	}
 }
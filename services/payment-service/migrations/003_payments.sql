@@ -0,0 +1,13 @@
+-- amounts to collect; reference is the caller's id (e.g. a dine-in bill share) and makes creation repeatable
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    reference VARCHAR(128) NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'PENDING', -- PENDING, PAID, FAILED, CANCELLED
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_status ON payments (status, created_at);
@@ -0,0 +1,7 @@
+-- an order can now have several refunds (amendments, then a cancellation); each has its own reference
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS reference VARCHAR(128);
+UPDATE refunds SET reference = 'order-' || order_id || '-refund' WHERE reference IS NULL;
+ALTER TABLE refunds ALTER COLUMN reference SET NOT NULL;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_order_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_reference ON refunds (reference);
+CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds (order_id);
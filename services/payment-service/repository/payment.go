@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending   = "PENDING"
+	PaymentStatusPaid      = "PAID"
+	PaymentStatusFailed    = "FAILED"
+	PaymentStatusCancelled = "CANCELLED"
+)
+
+// Payment is one amount to collect from one payer; Reference is the caller's own id for it
+type Payment struct {
+	ID          int64     `json:"id"`
+	Reference   string    `json:"reference"`
+	UserID      int64     `json:"user_id"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description,omitempty"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type PaymentRepo interface {
+	// Create records a pending payment; a repeat with the same reference returns the existing row
+	Create(p *Payment) (*Payment, error)
+	GetByID(id int64) (*Payment, error)
+	// SetStatus moves a PENDING payment to status; sql.ErrNoRows when it is not pending any more
+	SetStatus(id int64, status string) (*Payment, error)
+}
+
+type paymentRepo struct {
+	db *sql.DB
+}
+
+func NewPaymentRepo(db *sql.DB) PaymentRepo {
+	return &paymentRepo{db: db}
+}
+
+const paymentColumns = `id, reference, user_id, amount, description, status, created_at, updated_at`
+
+func scanPayment(row *sql.Row) (*Payment, error) {
+	var p Payment
+	if err := row.Scan(&p.ID, &p.Reference, &p.UserID, &p.Amount, &p.Description, &p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *paymentRepo) Create(p *Payment) (*Payment, error) {
+	now := time.Now().UTC()
+	return scanPayment(r.db.QueryRow(`
+		INSERT INTO payments (reference, user_id, amount, description, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$6)
+		ON CONFLICT (reference) DO UPDATE SET reference = EXCLUDED.reference
+		RETURNING `+paymentColumns,
+		p.Reference, p.UserID, p.Amount, p.Description, PaymentStatusPending, now))
+}
+
+func (r *paymentRepo) GetByID(id int64) (*Payment, error) {
+	p, err := scanPayment(r.db.QueryRow(`SELECT `+paymentColumns+` FROM payments WHERE id=$1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *paymentRepo) SetStatus(id int64, status string) (*Payment, error) {
+	return scanPayment(r.db.QueryRow(`
+		UPDATE payments SET status=$1, updated_at=$2
+		WHERE id=$3 AND status=$4
+		RETURNING `+paymentColumns,
+		status, time.Now().UTC(), id, PaymentStatusPending))
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Refund statuses
+const (
+	RefundStatusPending   = "PENDING"
+	RefundStatusCompleted = "COMPLETED"
+	RefundStatusFailed    = "FAILED"
+)
+
+// Refund is money owed back to a customer, for a cancelled order or an order amended to cost less
+type Refund struct {
+	ID         int64     `json:"id"`
+	Reference  string    `json:"reference"`
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	Amount     float64   `json:"amount"`
+	ReasonCode string    `json:"reason_code,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type RefundRepo interface {
+	// Create records a pending refund; a repeat with the same reference returns the existing row
+	Create(rf *Refund) (*Refund, error)
+}
+
+type refundRepo struct {
+	db *sql.DB
+}
+
+func NewRefundRepo(db *sql.DB) RefundRepo {
+	return &refundRepo{db: db}
+}
+
+func (r *refundRepo) Create(rf *Refund) (*Refund, error) {
+	now := time.Now().UTC()
+	out := *rf
+	err := r.db.QueryRow(`
+		INSERT INTO refunds (reference, order_id, user_id, amount, reason_code, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$7)
+		ON CONFLICT (reference) DO UPDATE SET reference = EXCLUDED.reference
+		RETURNING id, order_id, user_id, amount, reason_code, status, created_at, updated_at
+	`, rf.Reference, rf.OrderID, rf.UserID, rf.Amount, rf.ReasonCode, RefundStatusPending, now).Scan(
+		&out.ID, &out.OrderID, &out.UserID, &out.Amount, &out.ReasonCode, &out.Status, &out.CreatedAt, &out.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
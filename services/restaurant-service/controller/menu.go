@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/middleware"
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
@@ -47,7 +50,7 @@ func (mc *MenuController) CreateCategory(c *gin.Context) {
 
 	id, err := mc.svc.CreateCategory(&payload, tokenUID, roleStr)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to create category", err.Error())
+		sendMenuError(c, err, "failed to create category")
 		return
 	}
 	utils.SendSuccess(c, http.StatusCreated, "category created", gin.H{"categoryId": id})
@@ -97,13 +100,16 @@ func (mc *MenuController) CreateMenuItem(c *gin.Context) {
 	payload.RestaurantID = rid
 	id, err := mc.svc.CreateMenuItem(&payload, tokenUID, roleStr)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to create menu item", err.Error())
+		sendMenuError(c, err, "failed to create menu item")
 		return
 	}
 	utils.SendSuccess(c, http.StatusCreated, "menu item created", gin.H{"itemId": id})
 }
 
-/* GET /restaurants/:id/menu/items */
+/*
+GET /restaurants/:id/menu/items - what is orderable now, or at ?at=RFC3339 for a scheduled order.
+?include_hidden=true is the owner's full menu, hidden items and all times included.
+*/
 func (mc *MenuController) GetMenuItems(c *gin.Context) {
 	ridStr := c.Param("id")
 	rid, err := strconv.ParseInt(ridStr, 10, 64)
@@ -111,10 +117,176 @@ func (mc *MenuController) GetMenuItems(c *gin.Context) {
 		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
 		return
 	}
-	rows, err := mc.svc.GetMenuItems(rid)
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	includeHidden, _ := strconv.ParseBool(c.Query("include_hidden"))
+	var at *time.Time
+	if v := c.Query("at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "at must be an RFC3339 time", err.Error())
+			return
+		}
+		at = &t
+	}
+	rows, err := mc.svc.GetMenuItems(rid, includeHidden, at, tokenUID, roleStr)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch menu items", err.Error())
+		sendMenuError(c, err, "failed to fetch menu items")
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "menu fetched", gin.H{"items": rows})
 }
+
+/* PUT /restaurants/:id/menu/items/:item_id */
+func (mc *MenuController) UpdateMenuItem(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var payload models.MenuItem
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	payload.ID = itemID
+	payload.RestaurantID = rid
+	if err := mc.svc.UpdateMenuItem(&payload, tokenUID, roleStr); err != nil {
+		sendMenuError(c, err, "failed to update menu item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "menu item updated", gin.H{"item": payload})
+}
+
+/* DELETE /restaurants/:id/menu/items/:item_id */
+func (mc *MenuController) DeleteMenuItem(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if err := mc.svc.DeleteMenuItem(rid, itemID, tokenUID, roleStr); err != nil {
+		sendMenuError(c, err, "failed to delete menu item")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "menu item deleted", nil)
+}
+
+type setAvailabilityReq struct {
+	ItemIds      []int64    `json:"itemIds" binding:"required"`
+	Availability string     `json:"availability" binding:"required"` // IN_STOCK | OUT_OF_STOCK | HIDDEN
+	RestockAt    *time.Time `json:"restockAt,omitempty"`             // OUT_OF_STOCK only: back in stock at this time
+}
+
+/* PUT /restaurants/:id/menu/availability - switch many items at once */
+func (mc *MenuController) SetAvailability(c *gin.Context) {
+	rid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req setAvailabilityReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	updated, err := mc.svc.SetAvailability(rid, req.ItemIds, req.Availability, req.RestockAt, tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to update availability")
+		return
+	}
+	changed := make(map[int64]bool, len(updated))
+	for _, id := range updated {
+		changed[id] = true
+	}
+	notFound := []int64{}
+	for _, id := range req.ItemIds {
+		if !changed[id] {
+			notFound = append(notFound, id)
+		}
+	}
+	utils.SendSuccess(c, http.StatusOK, "availability updated", gin.H{"updated": updated, "notFound": notFound})
+}
+
+// sendMenuError maps menu service errors to responses
+func sendMenuError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		// the item, group or an option went away while it was being saved
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch err.Error() {
+	case "not_found":
+		utils.SendError(c, http.StatusNotFound, "not found", nil)
+	case "forbidden":
+		utils.SendError(c, http.StatusForbidden, "forbidden", nil)
+	case "name_required":
+		utils.SendError(c, http.StatusBadRequest, "name required", nil)
+	case "invalid_price":
+		utils.SendError(c, http.StatusBadRequest, "price must be >= 0", nil)
+	case "invalid_availability":
+		utils.SendError(c, http.StatusBadRequest, "availability must be IN_STOCK, OUT_OF_STOCK or HIDDEN", nil)
+	case "invalid_restock":
+		utils.SendError(c, http.StatusBadRequest, "restockAt must be in the future and goes with OUT_OF_STOCK", nil)
+	case "invalid_window":
+		utils.SendError(c, http.StatusBadRequest, "windows need a weekday 0-6 (0 = Sunday) and startTime and endTime as HH:MM", nil)
+	case "too_many_windows":
+		utils.SendError(c, http.StatusBadRequest, "at most 28 windows", nil)
+	case "items_required":
+		utils.SendError(c, http.StatusBadRequest, "itemIds must list 1 to 500 items", nil)
+	case "options_required":
+		utils.SendError(c, http.StatusBadRequest, "a group needs at least one option", nil)
+	case "invalid_selection_limits":
+		utils.SendError(c, http.StatusBadRequest, "need 0 <= minSelect <= maxSelect <= number of options, maxSelect at least 1", nil)
+	case "duplicate_option":
+		utils.SendError(c, http.StatusBadRequest, "option names must be unique within a group", nil)
+	case "invalid_price_delta":
+		utils.SendError(c, http.StatusBadRequest, "priceDelta must be >= 0", nil)
+	case "unknown_option":
+		utils.SendError(c, http.StatusBadRequest, "option id does not belong to this group", nil)
+	case "empty_import":
+		utils.SendError(c, http.StatusBadRequest, "the menu file has no categories or items", nil)
+	case "too_many_rows":
+		utils.SendError(c, http.StatusBadRequest, "a menu file may have at most 5000 categories, items and options", nil)
+	case "sku_conflict":
+		utils.SendError(c, http.StatusConflict, "an sku is already used by another category or item", nil)
+	default:
+		utils.SendError(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/services"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// largest menu file an import reads
+const maxMenuImportBytes = 5 << 20
+
+// GET /restaurants/:id/menu/export?format=json|csv - json unless asked otherwise
+func (mc *MenuController) ExportMenu(c *gin.Context) {
+	rid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		utils.SendError(c, http.StatusBadRequest, "format must be json or csv", nil)
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	doc, err := mc.svc.ExportMenu(rid, tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to export menu")
+		return
+	}
+	if format == "json" {
+		utils.SendSuccess(c, http.StatusOK, "menu exported", gin.H{"menu": doc})
+		return
+	}
+	body, err := services.RenderMenuCSV(doc)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "failed to render menu", err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="menu-`+strconv.FormatInt(rid, 10)+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", body)
+}
+
+/*
+POST /restaurants/:id/menu/import?format=json|csv&dry_run=true - the body is the file, in
+the format of the query or else the Content-Type. With dry_run nothing is saved and the
+report says what would be; rows with problems fail the whole import with 422.
+*/
+func (mc *MenuController) ImportMenu(c *gin.Context) {
+	rid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = "json"
+		if strings.Contains(c.ContentType(), "csv") {
+			format = "csv"
+		}
+	}
+	if format != "json" && format != "csv" {
+		utils.SendError(c, http.StatusBadRequest, "format must be json or csv", nil)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxMenuImportBytes)
+	var doc *models.MenuDocument
+	if format == "csv" {
+		doc, err = services.ParseMenuCSV(body)
+	} else {
+		doc = &models.MenuDocument{}
+		err = json.NewDecoder(body).Decode(doc)
+	}
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid menu file", err.Error())
+		return
+	}
+
+	report, err := mc.svc.ImportMenu(rid, doc, dryRun, tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to import menu")
+		return
+	}
+	switch {
+	case len(report.Problems) > 0 && !dryRun:
+		utils.SendError(c, http.StatusUnprocessableEntity, "menu file has problems, nothing was imported", report)
+	case dryRun:
+		utils.SendSuccess(c, http.StatusOK, "menu file checked", gin.H{"report": report})
+	default:
+		utils.SendSuccess(c, http.StatusOK, "menu imported", gin.H{"report": report})
+	}
+}
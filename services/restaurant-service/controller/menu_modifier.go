@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type modifierGroupReq struct {
+	Name      string              `json:"name" binding:"required"`
+	MinSelect int                 `json:"minSelect"` // 0 makes the group optional
+	MaxSelect int                 `json:"maxSelect" binding:"required"`
+	SortOrder int                 `json:"sortOrder"`
+	Options   []modifierOptionReq `json:"options" binding:"required,dive"`
+}
+
+type modifierOptionReq struct {
+	Id          int64   `json:"id,omitempty"` // on update: keep this option
+	Name        string  `json:"name" binding:"required"`
+	PriceDelta  float64 `json:"priceDelta"`
+	IsAvailable *bool   `json:"isAvailable,omitempty"` // default true
+	SortOrder   int     `json:"sortOrder"`
+}
+
+func (r modifierGroupReq) group(menuItemID int64) *models.ModifierGroup {
+	g := &models.ModifierGroup{
+		MenuItemID: menuItemID,
+		Name:       r.Name,
+		MinSelect:  r.MinSelect,
+		MaxSelect:  r.MaxSelect,
+		SortOrder:  r.SortOrder,
+		Options:    make([]models.ModifierOption, 0, len(r.Options)),
+	}
+	for _, o := range r.Options {
+		available := true
+		if o.IsAvailable != nil {
+			available = *o.IsAvailable
+		}
+		g.Options = append(g.Options, models.ModifierOption{
+			ID:          o.Id,
+			Name:        o.Name,
+			PriceDelta:  o.PriceDelta,
+			IsAvailable: available,
+			SortOrder:   o.SortOrder,
+		})
+	}
+	return g
+}
+
+// menuItemParams parses :id and :item_id
+func menuItemParams(c *gin.Context) (int64, int64, bool) {
+	rid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return 0, 0, false
+	}
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid menu item id", err.Error())
+		return 0, 0, false
+	}
+	return rid, itemID, true
+}
+
+/* GET /restaurants/:id/menu/items/:item_id/modifier-groups */
+func (mc *MenuController) ListModifierGroups(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	groups, err := mc.svc.ListModifierGroups(rid, itemID)
+	if err != nil {
+		sendMenuError(c, err, "failed to fetch modifier groups")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "modifier groups fetched", gin.H{"groups": groups})
+}
+
+/* POST /restaurants/:id/menu/items/:item_id/modifier-groups */
+func (mc *MenuController) CreateModifierGroup(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req modifierGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	g, err := mc.svc.CreateModifierGroup(rid, req.group(itemID), tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to create modifier group")
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "modifier group created", gin.H{"group": g})
+}
+
+/* PUT /restaurants/:id/menu/items/:item_id/modifier-groups/:group_id - options without an id are added, options left out removed */
+func (mc *MenuController) UpdateModifierGroup(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	groupID, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid group id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req modifierGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	g := req.group(itemID)
+	g.ID = groupID
+	updated, err := mc.svc.UpdateModifierGroup(rid, g, tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to update modifier group")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "modifier group updated", gin.H{"group": updated})
+}
+
+/* DELETE /restaurants/:id/menu/items/:item_id/modifier-groups/:group_id */
+func (mc *MenuController) DeleteModifierGroup(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	groupID, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid group id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	if err := mc.svc.DeleteModifierGroup(rid, itemID, groupID, tokenUID, roleStr); err != nil {
+		sendMenuError(c, err, "failed to delete modifier group")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "modifier group deleted", nil)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/middleware"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type menuWindowsReq struct {
+	Windows []menuWindowReq `json:"windows"` // empty: served all day
+}
+
+type menuWindowReq struct {
+	Weekday   int    `json:"weekday"`   // 0 = Sunday
+	StartTime string `json:"startTime"` // "HH:MM"
+	EndTime   string `json:"endTime"`   // at or before startTime runs past midnight
+}
+
+func (r menuWindowsReq) windows() []models.MenuWindow {
+	out := make([]models.MenuWindow, 0, len(r.Windows))
+	for _, w := range r.Windows {
+		out = append(out, models.MenuWindow{Weekday: w.Weekday, StartTime: w.StartTime, EndTime: w.EndTime})
+	}
+	return out
+}
+
+/* PUT /restaurants/:id/categories/:category_id/windows - replaces when the category is served */
+func (mc *MenuController) SetCategoryWindows(c *gin.Context) {
+	rid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid restaurant id", err.Error())
+		return
+	}
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid category id", err.Error())
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req menuWindowsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	windows, err := mc.svc.SetCategoryWindows(rid, categoryID, req.windows(), tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to save serving windows")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "serving windows saved", gin.H{"windows": windows})
+}
+
+/* PUT /restaurants/:id/menu/items/:item_id/windows - replaces when the item is served */
+func (mc *MenuController) SetItemWindows(c *gin.Context) {
+	rid, itemID, ok := menuItemParams(c)
+	if !ok {
+		return
+	}
+	rawUID, _ := c.Get(middleware.ContextUserIDKey)
+	tokenUID := int64(0)
+	if rawUID != nil {
+		tokenUID = rawUID.(int64)
+	}
+	role, _ := c.Get(middleware.ContextRoleKey)
+	roleStr := ""
+	if role != nil {
+		roleStr = role.(string)
+	}
+	var req menuWindowsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid payload", err.Error())
+		return
+	}
+	windows, err := mc.svc.SetItemWindows(rid, itemID, req.windows(), tokenUID, roleStr)
+	if err != nil {
+		sendMenuError(c, err, "failed to save serving windows")
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "serving windows saved", gin.H{"windows": windows})
+}
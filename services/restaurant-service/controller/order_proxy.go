@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+/*
+OrderProxy keeps the ordering paths this service used to serve (orders, carts, group carts,
+dine-in, promotions, delivery fees, GST rules) answering for existing clients. order-service
+owns them now; requests and responses, event streams included, pass through unchanged.
+*/
+type OrderProxy struct {
+	proxy *httputil.ReverseProxy
+}
+
+func NewOrderProxy() (*OrderProxy, error) {
+	base := os.Getenv("ORDER_SERVICE_URL")
+	if base == "" {
+		base = "http://localhost:8081"
+	}
+	target, err := url.Parse(strings.TrimRight(base, "/"))
+	if err != nil {
+		return nil, err
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.FlushInterval = -1 // SSE: every event goes out as soon as order-service writes it
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(utils.APIResponse{
+			Status:     "error",
+			StatusCode: http.StatusBadGateway,
+			Message:    "order-service unavailable",
+			Error:      err.Error(),
+		})
+	}
+	return &OrderProxy{proxy: proxy}, nil
+}
+
+// Forward relays the request to order-service and its response back to the caller
+func (p *OrderProxy) Forward(c *gin.Context) {
+	p.proxy.ServeHTTP(c.Writer, c.Request)
+}
@@ -48,6 +48,10 @@ func (rc *RestaurantController) Create(c *gin.Context) {
 			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
 			return
 		}
+		if err.Error() == "invalid_gstin" {
+			utils.SendError(c, http.StatusBadRequest, "gstin is not a valid GST registration number", nil)
+			return
+		}
 		utils.SendError(c, http.StatusInternalServerError, "failed to create restaurant", err.Error())
 		return
 	}
@@ -160,6 +164,9 @@ func (rc *RestaurantController) Update(c *gin.Context) {
 		case "forbidden":
 			utils.SendError(c, http.StatusForbidden, "forbidden", nil)
 			return
+		case "invalid_gstin":
+			utils.SendError(c, http.StatusBadRequest, "gstin is not a valid GST registration number", nil)
+			return
 		default:
 			utils.SendError(c, http.StatusInternalServerError, "failed to update restaurant", err.Error())
 			return
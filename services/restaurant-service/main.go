@@ -26,8 +26,8 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"*"},
 		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders: []string{"Content-Length"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key", "Last-Event-ID", "X-Participant-Token"},
+		ExposeHeaders: []string{"Content-Length", "Idempotent-Replayed"},
 		MaxAge:        12 * time.Hour,
 	}))
 
@@ -79,6 +79,18 @@ func AuthRequired() gin.HandlerFunc {
 	}
 }
 
+// AuthOptional runs AuthRequired when an Authorization header is present and lets anonymous requests through
+func AuthOptional() gin.HandlerFunc {
+	required := AuthRequired()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
 // OwnerOrAdmin middleware checks that the :user_id param equals token sub or role contains "ADMIN" / "SUPERADMIN"
 func OwnerOrAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,13 @@
+-- order status timeline: one row per transition, written in the same tx as the status update
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    actor_user_id BIGINT,
+    actor_role VARCHAR(32),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order ON order_status_history (order_id, created_at);
@@ -0,0 +1,10 @@
+-- per restaurant, per business day counter behind order numbers like R42-1017-0031
+CREATE TABLE IF NOT EXISTS order_number_sequences (
+    restaurant_id BIGINT NOT NULL,
+    business_date DATE NOT NULL,
+    last_value INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (restaurant_id, business_date)
+);
+
+-- lookup for GET /orders?number=
+CREATE INDEX IF NOT EXISTS idx_orders_order_number ON orders (UPPER(order_number));
@@ -0,0 +1,4 @@
+-- keyset pagination on (created_at, id) for customer and restaurant order listings
+CREATE INDEX IF NOT EXISTS idx_orders_user_created_id ON orders (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_restaurant_created_id ON orders (restaurant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_order_items_order ON order_items (order_id);
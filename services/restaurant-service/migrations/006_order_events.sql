@@ -0,0 +1,13 @@
+-- append-only log behind the live order streams; ids double as SSE event ids
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_restaurant ON order_events (restaurant_id, id);
+CREATE INDEX IF NOT EXISTS idx_order_events_order ON order_events (order_id, id);
@@ -0,0 +1,7 @@
+-- rider assigned to a delivery order and their last reported position
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS rider_id BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS rider_latitude DOUBLE PRECISION;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS rider_longitude DOUBLE PRECISION;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS rider_location_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_orders_rider ON orders (rider_id) WHERE rider_id IS NOT NULL;
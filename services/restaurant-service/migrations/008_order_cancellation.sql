@@ -0,0 +1,9 @@
+-- why, by whom and when an order was cancelled; refund_requested_at is set once payment-service accepts the refund
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancel_reason_code VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancel_note TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_by VARCHAR(32);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS refund_requested_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_orders_pending_refunds ON orders (cancelled_at)
+    WHERE payment_status = 'REFUND_PENDING' AND refund_requested_at IS NULL;
@@ -0,0 +1,6 @@
+-- orders placed for later: scheduled_for is the requested time, release_at when the kitchen gets it
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS release_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_orders_scheduled_release ON orders (release_at)
+    WHERE order_status = 'SCHEDULED';
@@ -0,0 +1,44 @@
+-- shared carts for group orders; the join token doubles as the link secret
+CREATE TABLE IF NOT EXISTS group_carts (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL,
+    host_user_id BIGINT NOT NULL,
+    join_token VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(32) NOT NULL DEFAULT 'OPEN', -- OPEN, LOCKED, CHECKED_OUT, CANCELLED
+    order_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- signed-in participants are found by user_id, guests by their token
+CREATE TABLE IF NOT EXISTS group_cart_participants (
+    id BIGSERIAL PRIMARY KEY,
+    group_cart_id BIGINT NOT NULL REFERENCES group_carts(id) ON DELETE CASCADE,
+    user_id BIGINT,
+    nickname VARCHAR(64) NOT NULL,
+    is_host BOOLEAN NOT NULL DEFAULT FALSE,
+    token VARCHAR(64) NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (group_cart_id, token)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_cart_participants_user
+    ON group_cart_participants (group_cart_id, user_id) WHERE user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS group_cart_items (
+    id BIGSERIAL PRIMARY KEY,
+    group_cart_id BIGINT NOT NULL REFERENCES group_carts(id) ON DELETE CASCADE,
+    participant_id BIGINT NOT NULL REFERENCES group_cart_participants(id) ON DELETE CASCADE,
+    menu_item_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    options JSONB,
+    special_instructions TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_cart_items_cart ON group_cart_items (group_cart_id, participant_id, id);
+
+-- order lines remember who in the group asked for them
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS participant_id BIGINT;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS participant_name VARCHAR(64);
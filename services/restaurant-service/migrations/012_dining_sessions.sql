@@ -0,0 +1,25 @@
+-- dine-in sittings opened from a table's QR code; orders point here through orders.dining_session_id
+CREATE TABLE IF NOT EXISTS dining_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL,
+    table_id BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'OPEN', -- OPEN, CLOSED
+    opened_by BIGINT,
+    closed_by BIGINT,
+    -- consolidated bill, written on close
+    subtotal_amount DECIMAL(10, 2),
+    tax_amount DECIMAL(10, 2),
+    discount_amount DECIMAL(10, 2),
+    tip_amount DECIMAL(10, 2),
+    total_amount DECIMAL(10, 2),
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ
+);
+
+-- a table has at most one open session; scanning again joins it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dining_sessions_open_table
+    ON dining_sessions (table_id) WHERE status = 'OPEN';
+
+CREATE INDEX IF NOT EXISTS idx_dining_sessions_restaurant ON dining_sessions (restaurant_id, status, opened_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_orders_dining_session ON orders (dining_session_id) WHERE dining_session_id IS NOT NULL;
@@ -0,0 +1,24 @@
+-- split-bill settlement of closed dining sessions
+ALTER TABLE dining_sessions ADD COLUMN IF NOT EXISTS split_mode VARCHAR(16);
+ALTER TABLE dining_sessions ADD COLUMN IF NOT EXISTS settled_at TIMESTAMPTZ;
+
+-- one row per guest share; payment_id points at payment-service's payments.id
+CREATE TABLE IF NOT EXISTS dining_bill_shares (
+    id BIGSERIAL PRIMARY KEY,
+    session_id BIGINT NOT NULL REFERENCES dining_sessions(id) ON DELETE CASCADE,
+    label VARCHAR(64) NOT NULL,
+    payer_user_id BIGINT,
+    lines JSONB,
+    subtotal_amount DECIMAL(10, 2) NOT NULL,
+    tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    tip_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    payment_id BIGINT,
+    payment_attempt INTEGER NOT NULL DEFAULT 0,
+    payment_status VARCHAR(32) NOT NULL DEFAULT 'PENDING', -- PENDING, PAID, FAILED
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dining_bill_shares_session ON dining_bill_shares (session_id, id);
@@ -0,0 +1,19 @@
+-- changes to an order's lines while it is still PLACED, with before/after snapshots
+CREATE TABLE IF NOT EXISTS order_amendments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    actor_user_id BIGINT,
+    actor_role VARCHAR(32) NOT NULL,
+    before_snapshot JSONB NOT NULL,
+    after_snapshot JSONB NOT NULL,
+    price_difference DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    payment_action VARCHAR(16) NOT NULL DEFAULT 'NONE', -- NONE, CHARGE, REFUND
+    payment_requested_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_amendments_order ON order_amendments (order_id, id);
+
+-- charges and refunds payment-service has not accepted yet
+CREATE INDEX IF NOT EXISTS idx_order_amendments_pending_payment ON order_amendments (created_at)
+    WHERE payment_action <> 'NONE' AND payment_requested_at IS NULL;
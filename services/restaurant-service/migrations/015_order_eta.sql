@@ -0,0 +1,24 @@
+-- the ETA columns from the initial schema become timezone aware like the rest of the order times
+ALTER TABLE orders ALTER COLUMN estimated_delivery_time TYPE TIMESTAMPTZ USING estimated_delivery_time AT TIME ZONE 'UTC';
+ALTER TABLE orders ALTER COLUMN actual_delivery_time TYPE TIMESTAMPTZ USING actual_delivery_time AT TIME ZONE 'UTC';
+
+-- every ETA prediction with its inputs, kept for offline calibration against actual_delivery_time
+CREATE TABLE IF NOT EXISTS order_eta_predictions (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_status VARCHAR(32) NOT NULL,
+    estimated_delivery_time TIMESTAMPTZ NOT NULL,
+    prep_minutes INT NOT NULL DEFAULT 0,
+    queue_depth INT NOT NULL DEFAULT 0,
+    queue_minutes INT NOT NULL DEFAULT 0,
+    travel_minutes INT NOT NULL DEFAULT 0,
+    distance_km DECIMAL(8, 3),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_eta_predictions_order ON order_eta_predictions (order_id, id);
+CREATE INDEX IF NOT EXISTS idx_order_eta_predictions_created ON order_eta_predictions (created_at);
+
+-- kitchen queue depth per restaurant
+CREATE INDEX IF NOT EXISTS idx_orders_restaurant_kitchen ON orders (restaurant_id)
+    WHERE order_status IN ('PLACED', 'CONFIRMED', 'PREPARING');
@@ -0,0 +1,37 @@
+-- GST registration of the restaurant, printed on invoices
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS gstin VARCHAR(15);
+
+-- GST rates by category or tag; restaurant_id NULL rows are the platform defaults
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT REFERENCES restaurants(id) ON DELETE CASCADE,
+    category_id BIGINT REFERENCES categories(id) ON DELETE CASCADE,
+    tag VARCHAR(64),
+    label VARCHAR(100) NOT NULL,
+    rate_percent DECIMAL(5, 2) NOT NULL CHECK (rate_percent >= 0 AND rate_percent <= 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (category_id IS NULL OR tag IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rules_restaurant ON tax_rules (restaurant_id);
+
+-- restaurant service food and packaged goods
+INSERT INTO tax_rules (restaurant_id, category_id, tag, label, rate_percent)
+SELECT NULL, NULL, NULL, 'GST food', 5
+WHERE NOT EXISTS (SELECT 1 FROM tax_rules WHERE restaurant_id IS NULL AND category_id IS NULL AND tag IS NULL);
+INSERT INTO tax_rules (restaurant_id, category_id, tag, label, rate_percent)
+SELECT NULL, NULL, 'packaged', 'GST packaged goods', 18
+WHERE NOT EXISTS (SELECT 1 FROM tax_rules WHERE restaurant_id IS NULL AND tag = 'packaged');
+
+-- place of supply and the registration the order was billed under
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_state VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS restaurant_gstin VARCHAR(15);
+
+-- per-line GST breakdown
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_label VARCHAR(100);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS taxable_amount DECIMAL(10, 2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS cgst_amount DECIMAL(10, 2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS sgst_amount DECIMAL(10, 2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS igst_amount DECIMAL(10, 2);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10, 2);
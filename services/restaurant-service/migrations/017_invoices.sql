@@ -0,0 +1,21 @@
+-- per restaurant, per financial year counter behind invoice numbers like I42-2526-00031
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    restaurant_id BIGINT NOT NULL,
+    financial_year VARCHAR(4) NOT NULL,
+    last_value INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (restaurant_id, financial_year)
+);
+
+-- one invoice per delivered order; snapshot holds everything printed on it
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    restaurant_id BIGINT NOT NULL,
+    invoice_number VARCHAR(32) NOT NULL UNIQUE,
+    financial_year VARCHAR(4) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    snapshot JSONB NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_restaurant_issued ON invoices (restaurant_id, issued_at);
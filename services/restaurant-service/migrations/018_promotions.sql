@@ -0,0 +1,45 @@
+-- coupons (code) and automatic offers (no code); restaurant_id NULL rows are platform promotions
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT REFERENCES restaurants(id) ON DELETE CASCADE,
+    code VARCHAR(32),
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('FLAT', 'PERCENT')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    max_discount DECIMAL(10, 2),
+    min_subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    first_order_only BOOLEAN NOT NULL DEFAULT FALSE,
+    restaurant_ids BIGINT[],
+    tags TEXT[],
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    daily_start TIME,
+    daily_end TIME,
+    usage_limit INT CHECK (usage_limit > 0),
+    per_user_limit INT CHECK (per_user_limit > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (discount_type <> 'PERCENT' OR discount_value <= 100)
+);
+
+-- codes are case-insensitive and unique among live promotions
+CREATE UNIQUE INDEX IF NOT EXISTS uq_promotions_active_code ON promotions (UPPER(code)) WHERE code IS NOT NULL AND is_active;
+CREATE INDEX IF NOT EXISTS idx_promotions_automatic ON promotions (restaurant_id) WHERE code IS NULL AND is_active;
+
+-- one row per discounted order, written in the order's transaction; usage limits count these
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id),
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    code VARCHAR(32),
+    discount_amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promotion_id BIGINT REFERENCES promotions(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code VARCHAR(32);
@@ -0,0 +1,55 @@
+-- delivery pricing per city; city '' is the default for cities without their own
+CREATE TABLE IF NOT EXISTS delivery_fee_configs (
+    id BIGSERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    slabs JSONB NOT NULL, -- [{"up_to_km": 3, "fee": 25}, ...] ascending
+    small_order_threshold DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    small_order_surcharge DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    free_delivery_threshold DECIMAL(10, 2),
+    surge_max_multiplier DECIMAL(4, 2) NOT NULL DEFAULT 1 CHECK (surge_max_multiplier >= 1),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_fee_configs_city ON delivery_fee_configs (LOWER(city));
+
+INSERT INTO delivery_fee_configs (city, slabs, small_order_threshold, small_order_surcharge, free_delivery_threshold, surge_max_multiplier)
+SELECT '', '[{"up_to_km": 3, "fee": 25}, {"up_to_km": 6, "fee": 40}, {"up_to_km": 10, "fee": 60}]', 149, 20, 499, 2
+WHERE NOT EXISTS (SELECT 1 FROM delivery_fee_configs WHERE city = '');
+
+-- every delivery fee an order was charged, and quotes shown to customers
+CREATE TABLE IF NOT EXISTS delivery_fee_quotes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    city VARCHAR(100) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    distance_km DECIMAL(8, 3),
+    distance_fee DECIMAL(10, 2) NOT NULL,
+    surge_multiplier DECIMAL(4, 2) NOT NULL,
+    open_orders INT NOT NULL DEFAULT 0,
+    available_riders INT NOT NULL DEFAULT 0,
+    small_order_threshold DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    small_order_surcharge DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    free_delivery_threshold DECIMAL(10, 2),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- a quote prices one order only
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_fee_quote_id BIGINT REFERENCES delivery_fee_quotes(id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_orders_delivery_fee_quote ON orders (delivery_fee_quote_id) WHERE delivery_fee_quote_id IS NOT NULL;
+
+-- riders report availability; surge compares free riders with orders waiting for one
+CREATE TABLE IF NOT EXISTS rider_presence (
+    rider_id BIGINT PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    is_available BOOLEAN NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    last_seen_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rider_presence_city ON rider_presence (LOWER(city), last_seen_at);
+CREATE INDEX IF NOT EXISTS idx_orders_awaiting_rider ON orders (restaurant_id)
+    WHERE rider_id IS NULL AND order_status IN ('PLACED', 'CONFIRMED', 'PREPARING', 'READY');
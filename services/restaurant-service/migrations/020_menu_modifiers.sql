@@ -0,0 +1,26 @@
+-- modifier groups of a menu item, e.g. "Size" (exactly one) or "Toppings" (up to three)
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id BIGSERIAL PRIMARY KEY,
+    menu_item_id BIGINT NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    min_select INT NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INT NOT NULL CHECK (max_select >= 1),
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (min_select <= max_select)
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_item ON modifier_groups (menu_item_id, sort_order);
+
+-- choices of a group; price_delta is added to the item's price for each one picked
+CREATE TABLE IF NOT EXISTS modifier_options (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    is_available BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_options_group ON modifier_options (group_id, sort_order);
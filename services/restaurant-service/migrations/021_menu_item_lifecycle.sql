@@ -0,0 +1,5 @@
+-- sold out items can come back on their own; deleted items stay for past orders but leave the menu
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS restock_at TIMESTAMPTZ;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_restock ON menu_items (restock_at) WHERE restock_at IS NOT NULL;
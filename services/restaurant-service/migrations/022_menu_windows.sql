@@ -0,0 +1,13 @@
+-- when a category or item is served, e.g. breakfast 07:00-11:00 on weekdays; none means all day
+CREATE TABLE IF NOT EXISTS menu_windows (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT REFERENCES categories(id) ON DELETE CASCADE,
+    menu_item_id BIGINT REFERENCES menu_items(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Sunday
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL, -- at or before start_time runs past midnight
+    CHECK ((category_id IS NULL) <> (menu_item_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_windows_category ON menu_windows (category_id) WHERE category_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_menu_windows_item ON menu_windows (menu_item_id) WHERE menu_item_id IS NOT NULL;
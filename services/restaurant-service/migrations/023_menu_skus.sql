@@ -0,0 +1,7 @@
+-- the restaurant's own codes for categories and items; menu imports upsert by them
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS external_sku VARCHAR(64);
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS external_sku VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_categories_sku ON categories (restaurant_id, external_sku) WHERE external_sku IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_menu_items_sku ON menu_items (restaurant_id, external_sku)
+    WHERE external_sku IS NOT NULL AND deleted_at IS NULL;
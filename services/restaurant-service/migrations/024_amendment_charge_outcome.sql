@@ -0,0 +1,2 @@
+-- what became of an amendment charge once its order was cancelled: CAPTURED (refunded with the order) or VOIDED
+ALTER TABLE order_amendments ADD COLUMN IF NOT EXISTS charge_outcome VARCHAR(16);
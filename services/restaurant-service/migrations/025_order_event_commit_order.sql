@@ -0,0 +1,27 @@
+-- position of an order event in commit order; stream ids and Last-Event-ID resume from it.
+-- ids come from a sequence in insert order, so a slow transaction can commit an id lower
+-- than one a client already saw, and "id > Last-Event-ID" would skip it for good.
+ALTER TABLE order_events ADD COLUMN IF NOT EXISTS commit_seq BIGINT;
+CREATE SEQUENCE IF NOT EXISTS order_events_commit_seq;
+UPDATE order_events SET commit_seq = id WHERE commit_seq IS NULL;
+SELECT setval('order_events_commit_seq', COALESCE(MAX(commit_seq), 0) + 1, false) FROM order_events;
+
+-- runs while the inserting transaction commits; the lock is held until it is visible, so the
+-- next one draws its number only afterwards and numbers follow commit order
+CREATE OR REPLACE FUNCTION order_events_stamp_commit_seq() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('order_events_commit_seq'));
+    UPDATE order_events SET commit_seq = nextval('order_events_commit_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS order_events_commit_seq ON order_events;
+CREATE CONSTRAINT TRIGGER order_events_commit_seq
+    AFTER INSERT ON order_events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION order_events_stamp_commit_seq();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_events_commit_seq ON order_events (commit_seq);
+CREATE INDEX IF NOT EXISTS idx_order_events_restaurant_seq ON order_events (restaurant_id, commit_seq);
+CREATE INDEX IF NOT EXISTS idx_order_events_order_seq ON order_events (order_id, commit_seq);
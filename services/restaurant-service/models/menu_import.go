@@ -0,0 +1,72 @@
+package models
+
+/*
+MenuDocument is a whole menu as exported and imported. Categories and items are keyed by
+SKU: an import updates the category or item with that SKU and creates the rest. Parents
+and categories are referenced by SKU too. Rows are numbered from 1, for CSV the line.
+*/
+type MenuDocument struct {
+	Categories []MenuDocumentCategory `json:"categories"`
+	Items      []MenuDocumentItem     `json:"items"`
+
+	Problems []MenuImportProblem `json:"-"` // found while reading the file, e.g. a price that is not a number
+}
+
+type MenuDocumentCategory struct {
+	Row       int    `json:"-"`
+	SKU       string `json:"sku"`
+	ParentSKU string `json:"parent_sku,omitempty"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+	IsActive  *bool  `json:"is_active,omitempty"` // default true
+}
+
+type MenuDocumentItem struct {
+	Row             int                     `json:"-"`
+	SKU             string                  `json:"sku"`
+	CategorySKU     string                  `json:"category_sku,omitempty"`
+	Name            string                  `json:"name"`
+	Description     string                  `json:"description,omitempty"`
+	Price           float64                 `json:"price"`
+	Currency        string                  `json:"currency,omitempty"`
+	Availability    string                  `json:"availability,omitempty"`
+	IsVeg           bool                    `json:"is_veg"`
+	SpiceLevel      int                     `json:"spice_level,omitempty"`
+	PrepTimeMinutes int                     `json:"prep_time_minutes,omitempty"`
+	Tags            []string                `json:"tags,omitempty"`
+	ImageURL        string                  `json:"image_url,omitempty"`
+	ModifierGroups  []MenuDocumentModifiers `json:"modifier_groups,omitempty"` // the item's complete set
+}
+
+type MenuDocumentModifiers struct {
+	Row       int                  `json:"-"`
+	Name      string               `json:"name"`
+	MinSelect int                  `json:"min_select"`
+	MaxSelect int                  `json:"max_select"`
+	Options   []MenuDocumentOption `json:"options"`
+}
+
+type MenuDocumentOption struct {
+	Name        string  `json:"name"`
+	PriceDelta  float64 `json:"price_delta"`
+	IsAvailable *bool   `json:"is_available,omitempty"` // default true
+}
+
+// MenuImportProblem is one thing wrong with an import, pointing at its row
+type MenuImportProblem struct {
+	Section string `json:"section"` // categories | items | modifiers | file
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// MenuImportReport says what an import did, or with DryRun would do
+type MenuImportReport struct {
+	DryRun            bool                `json:"dry_run"`
+	CategoriesCreated int                 `json:"categories_created"`
+	CategoriesUpdated int                 `json:"categories_updated"`
+	ItemsCreated      int                 `json:"items_created"`
+	ItemsUpdated      int                 `json:"items_updated"`
+	Problems          []MenuImportProblem `json:"problems"`
+}
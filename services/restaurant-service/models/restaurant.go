@@ -17,6 +17,7 @@ type Restaurant struct {
 	City            string          `json:"city,omitempty"`
 	State           string          `json:"state,omitempty"`
 	Pincode         string          `json:"pincode,omitempty"`
+	GSTIN           string          `json:"gstin,omitempty"` // GST registration number printed on invoices
 	Latitude        *float64        `json:"latitude,omitempty"`
 	Longitude       *float64        `json:"longitude,omitempty"`
 	AvgRating       *float64        `json:"avg_rating,omitempty"`
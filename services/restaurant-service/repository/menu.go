@@ -16,15 +16,40 @@ type MenuRepo interface {
 
 	// menu items
 	CreateMenuItem(item *models.MenuItem) (int64, error)
-	GetMenuItems(restaurantID int64) ([]models.MenuItem, error)
+	// GetMenuItems lists the restaurant's menu: items in stock and sold out, and hidden ones too with includeHidden
+	GetMenuItems(restaurantID int64, includeHidden bool) ([]models.MenuItem, error)
+	GetMenuItemsByIDs(ids []int64) ([]models.MenuItem, error)
+	// UpdateMenuItem saves an item of item.RestaurantID; sql.ErrNoRows when there is none
+	UpdateMenuItem(item *models.MenuItem) error
+	// DeleteMenuItem takes an item off the menu for good; past orders keep pointing at it
+	DeleteMenuItem(restaurantID, id int64) (bool, error)
+	// SetAvailability switches items of the restaurant and returns the ids it changed
+	SetAvailability(restaurantID int64, ids []int64, availability string, restockAt *time.Time) ([]int64, error)
+	// RestockDue puts sold out items whose restock time has come back in stock
+	RestockDue(now time.Time) (int64, error)
+
+	// modifier groups, with their options
+	ListModifierGroups(menuItemIDs []int64) ([]models.ModifierGroup, error)
+	// GetModifierGroup returns nil when the group does not exist
+	GetModifierGroup(id int64) (*models.ModifierGroup, error)
+	CreateModifierGroup(tx *sql.Tx, g *models.ModifierGroup) error
+	// UpdateModifierGroup saves the group and makes its options g.Options: options with an ID are updated, the rest inserted, missing ones deleted
+	UpdateModifierGroup(tx *sql.Tx, g *models.ModifierGroup) error
+	// DeleteModifierGroup reports whether the item had the group
+	DeleteModifierGroup(menuItemID, id int64) (bool, error)
+
+	// ReplaceMenuWindows sets the serving windows of a category or, with categoryID nil, of an item
+	ReplaceMenuWindows(tx *sql.Tx, categoryID, menuItemID *int64, windows []models.MenuWindow) error
+
+	// menu imports: insert, or update by ID, within the import's transaction
+	SaveCategory(tx *sql.Tx, cat *models.MenuCategory) error
+	SaveMenuItem(tx *sql.Tx, item *models.MenuItem) error
+	DeleteModifierGroupsExcept(tx *sql.Tx, menuItemID int64, keep []int64) error
 
 	// (optional extras you can implement later)
 	// GetCategoryByID(id int64) (*models.MenuCategory, error)
 	// UpdateCategory(cat *models.MenuCategory) error
 	// DeleteCategory(id int64) error
-	// GetMenuItemByID(id int64) (*models.MenuItem, error)
-	// UpdateMenuItem(item *models.MenuItem) error
-	// DeleteMenuItem(id int64) error
 }
 
 type menuRepo struct {
@@ -63,7 +88,7 @@ func (m *menuRepo) CreateCategory(cat *models.MenuCategory) (int64, error) {
 
 func (m *menuRepo) GetCategories(restaurantID int64) ([]models.MenuCategory, error) {
 	rows, err := m.db.Query(`
-		SELECT id, restaurant_id, name, slug, parent_id, sort_order, is_active, metadata, created_at
+		SELECT id, restaurant_id, name, slug, parent_id, sort_order, is_active, metadata, created_at, external_sku
 		FROM categories
 		WHERE restaurant_id = $1
 		ORDER BY sort_order, created_at
@@ -81,10 +106,12 @@ func (m *menuRepo) GetCategories(restaurantID int64) ([]models.MenuCategory, err
 		var isActive sql.NullBool
 		var metadata sql.NullString
 		var createdAt time.Time
+		var sku sql.NullString
 
-		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.Name, &slug, &parentID, &c.SortOrder, &isActive, &metadata, &createdAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.Name, &slug, &parentID, &c.SortOrder, &isActive, &metadata, &createdAt, &sku); err != nil {
 			return nil, err
 		}
+		c.ExternalSKU = sku.String
 		if slug.Valid {
 			c.Slug = slug.String
 		}
@@ -101,7 +128,26 @@ func (m *menuRepo) GetCategories(restaurantID int64) ([]models.MenuCategory, err
 		c.CreatedAt = &createdAt
 		out = append(out, c)
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(out))
+	for _, c := range out {
+		ids = append(ids, c.ID)
+	}
+	windows, err := m.listMenuWindows("category_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		for _, w := range windows {
+			if *w.CategoryID == out[i].ID {
+				out[i].Windows = append(out[i].Windows, w)
+			}
+		}
+	}
+	return out, nil
 }
 
 /* ---------- Menu Items ---------- */
@@ -125,11 +171,11 @@ func (m *menuRepo) CreateMenuItem(item *models.MenuItem) (int64, error) {
 
 	err := m.db.QueryRow(`
 		INSERT INTO menu_items
-			(restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, metadata, image_url, created_at, updated_at)
+			(restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, metadata, image_url, created_at, updated_at, restock_at)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
 		RETURNING id
-	`, item.RestaurantID, nullableInt64(item.CategoryID), item.Name, nullString(item.Description), item.Price, item.Currency, item.Availability, item.IsVeg, item.SpiceLevel, item.PrepTimeMinutes, pq.Array(item.Tags), meta, nullString(item.ImageURL), item.CreatedAt, item.UpdatedAt).Scan(&id)
+	`, item.RestaurantID, nullableInt64(item.CategoryID), item.Name, nullString(item.Description), item.Price, item.Currency, item.Availability, item.IsVeg, item.SpiceLevel, item.PrepTimeMinutes, pq.Array(item.Tags), meta, nullString(item.ImageURL), item.CreatedAt, item.UpdatedAt, item.RestockAt).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -137,13 +183,13 @@ func (m *menuRepo) CreateMenuItem(item *models.MenuItem) (int64, error) {
 	return id, nil
 }
 
-func (m *menuRepo) GetMenuItems(restaurantID int64) ([]models.MenuItem, error) {
+func (m *menuRepo) GetMenuItems(restaurantID int64, includeHidden bool) ([]models.MenuItem, error) {
 	rows, err := m.db.Query(`
-		SELECT id, restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, metadata, image_url, created_at, updated_at
+		SELECT `+menuItemColumns+`
 		FROM menu_items
-		WHERE restaurant_id = $1 AND availability = 'IN_STOCK'
+		WHERE restaurant_id = $1 AND deleted_at IS NULL AND ($2 OR availability IN ($3, $4))
 		ORDER BY created_at DESC
-	`, restaurantID)
+	`, restaurantID, includeHidden, models.AvailabilityInStock, models.AvailabilityOutOfStock)
 	if err != nil {
 		return nil, err
 	}
@@ -151,62 +197,183 @@ func (m *menuRepo) GetMenuItems(restaurantID int64) ([]models.MenuItem, error) {
 
 	var out []models.MenuItem
 	for rows.Next() {
-		var itm models.MenuItem
-		var categoryID sql.NullInt64
-		var description sql.NullString
-		var currency sql.NullString
-		var availability sql.NullString
-		var isVeg sql.NullBool
-		var spiceLevel sql.NullInt64
-		var prep sql.NullInt64
-		var tags pq.StringArray
-		var metadata sql.NullString
-		var imageURL sql.NullString
-		var createdAt, updatedAt time.Time
-
-		if err := rows.Scan(
-			&itm.ID, &itm.RestaurantID, &categoryID, &itm.Name, &description, &itm.Price, &currency, &availability, &isVeg, &spiceLevel, &prep, &tags, &metadata, &imageURL, &createdAt, &updatedAt,
-		); err != nil {
+		itm, err := scanMenuItem(rows)
+		if err != nil {
 			return nil, err
 		}
-		if categoryID.Valid {
-			v := categoryID.Int64
-			itm.CategoryID = &v
-		}
-		if description.Valid {
-			itm.Description = description.String
-		}
-		if currency.Valid {
-			itm.Currency = currency.String
-		}
-		if availability.Valid {
-			itm.Availability = availability.String
-		}
-		if isVeg.Valid {
-			itm.IsVeg = isVeg.Bool
-		}
-		if spiceLevel.Valid {
-			itm.SpiceLevel = int(spiceLevel.Int64)
-		}
-		if prep.Valid {
-			itm.PrepTimeMinutes = int(prep.Int64)
-		}
-		if len(tags) > 0 {
-			itm.Tags = tags
-		}
-		if metadata.Valid {
-			_ = json.Unmarshal([]byte(metadata.String), &itm.Metadata)
-		}
-		if imageURL.Valid {
-			itm.ImageURL = imageURL.String
+		out = append(out, itm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := m.attachModifierGroups(out); err != nil {
+		return nil, err
+	}
+	return out, m.attachMenuWindows(out)
+}
+
+// GetMenuItemsByIDs loads items regardless of restaurant or availability; callers validate both. Deleted items are left out.
+func (m *menuRepo) GetMenuItemsByIDs(ids []int64) ([]models.MenuItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Query(`
+		SELECT `+menuItemColumns+`
+		FROM menu_items
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.MenuItem
+	for rows.Next() {
+		itm, err := scanMenuItem(rows)
+		if err != nil {
+			return nil, err
 		}
-		itm.CreatedAt = &createdAt
-		itm.UpdatedAt = &updatedAt
 		out = append(out, itm)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := m.attachModifierGroups(out); err != nil {
+		return nil, err
+	}
+	return out, m.attachMenuWindows(out)
+}
+
+const menuItemColumns = `id, restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, metadata, image_url, created_at, updated_at, restock_at, external_sku`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMenuItem(sc rowScanner) (models.MenuItem, error) {
+	var itm models.MenuItem
+	var categoryID sql.NullInt64
+	var description sql.NullString
+	var currency sql.NullString
+	var availability sql.NullString
+	var isVeg sql.NullBool
+	var spiceLevel sql.NullInt64
+	var prep sql.NullInt64
+	var tags pq.StringArray
+	var metadata sql.NullString
+	var imageURL sql.NullString
+	var createdAt, updatedAt time.Time
+	var restockAt sql.NullTime
+	var sku sql.NullString
+
+	if err := sc.Scan(
+		&itm.ID, &itm.RestaurantID, &categoryID, &itm.Name, &description, &itm.Price, &currency, &availability, &isVeg, &spiceLevel, &prep, &tags, &metadata, &imageURL, &createdAt, &updatedAt, &restockAt, &sku,
+	); err != nil {
+		return itm, err
+	}
+	itm.ExternalSKU = sku.String
+	if categoryID.Valid {
+		v := categoryID.Int64
+		itm.CategoryID = &v
+	}
+	if description.Valid {
+		itm.Description = description.String
+	}
+	if currency.Valid {
+		itm.Currency = currency.String
+	}
+	if availability.Valid {
+		itm.Availability = availability.String
+	}
+	if isVeg.Valid {
+		itm.IsVeg = isVeg.Bool
+	}
+	if spiceLevel.Valid {
+		itm.SpiceLevel = int(spiceLevel.Int64)
+	}
+	if prep.Valid {
+		itm.PrepTimeMinutes = int(prep.Int64)
+	}
+	if len(tags) > 0 {
+		itm.Tags = tags
+	}
+	if metadata.Valid {
+		_ = json.Unmarshal([]byte(metadata.String), &itm.Metadata)
+	}
+	if imageURL.Valid {
+		itm.ImageURL = imageURL.String
+	}
+	if restockAt.Valid {
+		t := restockAt.Time
+		itm.RestockAt = &t
+	}
+	itm.CreatedAt = &createdAt
+	itm.UpdatedAt = &updatedAt
+	return itm, nil
+}
+
+func (m *menuRepo) UpdateMenuItem(item *models.MenuItem) error {
+	now := time.Now().UTC()
+	item.UpdatedAt = &now
+	meta := interface{}(nil)
+	if len(item.Metadata) > 0 {
+		meta = item.Metadata
+	}
+	return m.db.QueryRow(`
+		UPDATE menu_items SET category_id=$1, name=$2, description=$3, price=$4, currency=$5, availability=$6, is_veg=$7,
+		       spice_level=$8, prep_time_minutes=$9, tags=$10, metadata=$11, image_url=$12, updated_at=$13, restock_at=$14
+		WHERE id=$15 AND restaurant_id=$16 AND deleted_at IS NULL
+		RETURNING created_at
+	`, nullableInt64(item.CategoryID), item.Name, nullString(item.Description), item.Price, item.Currency, item.Availability, item.IsVeg,
+		item.SpiceLevel, item.PrepTimeMinutes, pq.Array(item.Tags), meta, nullString(item.ImageURL), now, item.RestockAt,
+		item.ID, item.RestaurantID).Scan(&item.CreatedAt)
+}
+
+func (m *menuRepo) DeleteMenuItem(restaurantID, id int64) (bool, error) {
+	now := time.Now().UTC()
+	res, err := m.db.Exec(`
+		UPDATE menu_items SET deleted_at=$1, availability=$2, restock_at=NULL, updated_at=$1
+		WHERE id=$3 AND restaurant_id=$4 AND deleted_at IS NULL
+	`, now, models.AvailabilityHidden, id, restaurantID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (m *menuRepo) SetAvailability(restaurantID int64, ids []int64, availability string, restockAt *time.Time) ([]int64, error) {
+	rows, err := m.db.Query(`
+		UPDATE menu_items SET availability=$1, restock_at=$2, updated_at=$3
+		WHERE restaurant_id=$4 AND id = ANY($5) AND deleted_at IS NULL
+		RETURNING id
+	`, availability, restockAt, time.Now().UTC(), restaurantID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
 	return out, rows.Err()
 }
 
+func (m *menuRepo) RestockDue(now time.Time) (int64, error) {
+	res, err := m.db.Exec(`
+		UPDATE menu_items SET availability=$1, restock_at=NULL, updated_at=$2
+		WHERE restock_at <= $2 AND availability=$3 AND deleted_at IS NULL
+	`, models.AvailabilityInStock, now, models.AvailabilityOutOfStock)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 /* ---------- helpers ---------- */
 
 func nullableInt64(p *int64) interface{} {
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/lib/pq"
+)
+
+// ErrSKUTaken is returned by import saves when another category or item of the restaurant has the SKU
+var ErrSKUTaken = errors.New("sku is taken")
+
+/* ---------- Menu imports ---------- */
+
+// SaveCategory inserts cat, or updates it when it has an ID
+func (m *menuRepo) SaveCategory(tx *sql.Tx, cat *models.MenuCategory) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if cat.ID == 0 {
+		now := time.Now().UTC()
+		cat.CreatedAt = &now
+		return skuTaken(tx.QueryRow(`
+			INSERT INTO categories (restaurant_id, name, parent_id, sort_order, is_active, created_at, external_sku)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)
+			RETURNING id
+		`, cat.RestaurantID, cat.Name, nullableInt64(cat.ParentID), cat.SortOrder, cat.IsActive, now, nullString(cat.ExternalSKU)).Scan(&cat.ID))
+	}
+	res, err := tx.Exec(`
+		UPDATE categories SET name=$1, parent_id=$2, sort_order=$3, is_active=$4, external_sku=$5
+		WHERE id=$6 AND restaurant_id=$7
+	`, cat.Name, nullableInt64(cat.ParentID), cat.SortOrder, cat.IsActive, nullString(cat.ExternalSKU), cat.ID, cat.RestaurantID)
+	if err != nil {
+		return skuTaken(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SaveMenuItem inserts item, or updates it when it has an ID; metadata is left as it is
+func (m *menuRepo) SaveMenuItem(tx *sql.Tx, item *models.MenuItem) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	item.UpdatedAt = &now
+	if item.ID == 0 {
+		item.CreatedAt = &now
+		return skuTaken(tx.QueryRow(`
+			INSERT INTO menu_items
+				(restaurant_id, category_id, name, description, price, currency, availability, is_veg, spice_level, prep_time_minutes, tags, image_url, created_at, updated_at, external_sku)
+			VALUES
+				($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$13,$14)
+			RETURNING id
+		`, item.RestaurantID, nullableInt64(item.CategoryID), item.Name, nullString(item.Description), item.Price, item.Currency, item.Availability,
+			item.IsVeg, item.SpiceLevel, item.PrepTimeMinutes, pq.Array(item.Tags), nullString(item.ImageURL), now, nullString(item.ExternalSKU)).Scan(&item.ID))
+	}
+	return skuTaken(tx.QueryRow(`
+		UPDATE menu_items SET category_id=$1, name=$2, description=$3, price=$4, currency=$5, availability=$6, is_veg=$7,
+		       spice_level=$8, prep_time_minutes=$9, tags=$10, image_url=$11, updated_at=$12, restock_at=$13, external_sku=$14
+		WHERE id=$15 AND restaurant_id=$16 AND deleted_at IS NULL
+		RETURNING created_at
+	`, nullableInt64(item.CategoryID), item.Name, nullString(item.Description), item.Price, item.Currency, item.Availability, item.IsVeg,
+		item.SpiceLevel, item.PrepTimeMinutes, pq.Array(item.Tags), nullString(item.ImageURL), now, item.RestockAt, nullString(item.ExternalSKU),
+		item.ID, item.RestaurantID).Scan(&item.CreatedAt))
+}
+
+// DeleteModifierGroupsExcept removes the item's modifier groups other than keep
+func (m *menuRepo) DeleteModifierGroupsExcept(tx *sql.Tx, menuItemID int64, keep []int64) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	_, err := tx.Exec(`DELETE FROM modifier_groups WHERE menu_item_id=$1 AND NOT (id = ANY($2))`, menuItemID, pq.Array(keep))
+	return err
+}
+
+// skuTaken turns a unique violation, which on these tables can only be the SKU, into ErrSKUTaken
+func skuTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrSKUTaken
+	}
+	return err
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/lib/pq"
+)
+
+/* ---------- Modifier groups ---------- */
+
+func (m *menuRepo) ListModifierGroups(menuItemIDs []int64) ([]models.ModifierGroup, error) {
+	if len(menuItemIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := m.db.Query(`
+		SELECT id, menu_item_id, name, min_select, max_select, sort_order, created_at, updated_at
+		FROM modifier_groups
+		WHERE menu_item_id = ANY($1)
+		ORDER BY menu_item_id, sort_order, id
+	`, pq.Array(menuItemIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.ModifierGroup
+	for rows.Next() {
+		g, err := scanModifierGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, m.attachModifierOptions(out)
+}
+
+func (m *menuRepo) GetModifierGroup(id int64) (*models.ModifierGroup, error) {
+	g, err := scanModifierGroup(m.db.QueryRow(`
+		SELECT id, menu_item_id, name, min_select, max_select, sort_order, created_at, updated_at
+		FROM modifier_groups WHERE id=$1
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups := []models.ModifierGroup{*g}
+	if err := m.attachModifierOptions(groups); err != nil {
+		return nil, err
+	}
+	return &groups[0], nil
+}
+
+func (m *menuRepo) CreateModifierGroup(tx *sql.Tx, g *models.ModifierGroup) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	g.CreatedAt = &now
+	g.UpdatedAt = &now
+	if err := tx.QueryRow(`
+		INSERT INTO modifier_groups (menu_item_id, name, min_select, max_select, sort_order, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING id
+	`, g.MenuItemID, g.Name, g.MinSelect, g.MaxSelect, g.SortOrder, now, now).Scan(&g.ID); err != nil {
+		return err
+	}
+	for i := range g.Options {
+		g.Options[i].ID = 0
+		if err := insertModifierOption(tx, g.ID, &g.Options[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *menuRepo) UpdateModifierGroup(tx *sql.Tx, g *models.ModifierGroup) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	now := time.Now().UTC()
+	g.UpdatedAt = &now
+	if err := tx.QueryRow(`
+		UPDATE modifier_groups SET name=$1, min_select=$2, max_select=$3, sort_order=$4, updated_at=$5
+		WHERE id=$6 AND menu_item_id=$7
+		RETURNING created_at
+	`, g.Name, g.MinSelect, g.MaxSelect, g.SortOrder, now, g.ID, g.MenuItemID).Scan(&g.CreatedAt); err != nil {
+		return err
+	}
+
+	keep := make([]int64, 0, len(g.Options))
+	for _, o := range g.Options {
+		if o.ID != 0 {
+			keep = append(keep, o.ID)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM modifier_options WHERE group_id=$1 AND NOT (id = ANY($2))`, g.ID, pq.Array(keep)); err != nil {
+		return err
+	}
+	for i := range g.Options {
+		o := &g.Options[i]
+		if o.ID == 0 {
+			if err := insertModifierOption(tx, g.ID, o); err != nil {
+				return err
+			}
+			continue
+		}
+		res, err := tx.Exec(`
+			UPDATE modifier_options SET name=$1, price_delta=$2, is_available=$3, sort_order=$4
+			WHERE id=$5 AND group_id=$6
+		`, o.Name, o.PriceDelta, o.IsAvailable, o.SortOrder, o.ID, g.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			// the option belongs to another group
+			return sql.ErrNoRows
+		}
+		o.GroupID = g.ID
+	}
+	return nil
+}
+
+func (m *menuRepo) DeleteModifierGroup(menuItemID, id int64) (bool, error) {
+	res, err := m.db.Exec(`DELETE FROM modifier_groups WHERE id=$1 AND menu_item_id=$2`, id, menuItemID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func insertModifierOption(tx *sql.Tx, groupID int64, o *models.ModifierOption) error {
+	o.GroupID = groupID
+	return tx.QueryRow(`
+		INSERT INTO modifier_options (group_id, name, price_delta, is_available, sort_order)
+		VALUES ($1,$2,$3,$4,$5)
+		RETURNING id
+	`, groupID, o.Name, o.PriceDelta, o.IsAvailable, o.SortOrder).Scan(&o.ID)
+}
+
+func scanModifierGroup(sc rowScanner) (*models.ModifierGroup, error) {
+	var g models.ModifierGroup
+	var createdAt, updatedAt time.Time
+	if err := sc.Scan(&g.ID, &g.MenuItemID, &g.Name, &g.MinSelect, &g.MaxSelect, &g.SortOrder, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	g.CreatedAt = &createdAt
+	g.UpdatedAt = &updatedAt
+	g.Options = []models.ModifierOption{}
+	return &g, nil
+}
+
+// attachModifierOptions loads the options of groups in one query
+func (m *menuRepo) attachModifierOptions(groups []models.ModifierGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(groups))
+	at := make(map[int64]int, len(groups))
+	for i, g := range groups {
+		ids = append(ids, g.ID)
+		at[g.ID] = i
+	}
+	rows, err := m.db.Query(`
+		SELECT id, group_id, name, price_delta, is_available, sort_order
+		FROM modifier_options
+		WHERE group_id = ANY($1)
+		ORDER BY group_id, sort_order, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o models.ModifierOption
+		if err := rows.Scan(&o.ID, &o.GroupID, &o.Name, &o.PriceDelta, &o.IsAvailable, &o.SortOrder); err != nil {
+			return err
+		}
+		g := &groups[at[o.GroupID]]
+		g.Options = append(g.Options, o)
+	}
+	return rows.Err()
+}
+
+// attachModifierGroups fills in ModifierGroups of menu items, so pricing sees them wherever items are loaded
+func (m *menuRepo) attachModifierGroups(items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(items))
+	at := make(map[int64]int, len(items))
+	for i, itm := range items {
+		ids = append(ids, itm.ID)
+		at[itm.ID] = i
+	}
+	groups, err := m.ListModifierGroups(ids)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		itm := &items[at[g.MenuItemID]]
+		itm.ModifierGroups = append(itm.ModifierGroups, g)
+	}
+	return nil
+}
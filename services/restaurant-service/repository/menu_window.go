@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/lib/pq"
+)
+
+/* ---------- Serving windows ---------- */
+
+func (m *menuRepo) ReplaceMenuWindows(tx *sql.Tx, categoryID, menuItemID *int64, windows []models.MenuWindow) error {
+	if tx == nil {
+		return errors.New("transaction required")
+	}
+	if (categoryID == nil) == (menuItemID == nil) {
+		return errors.New("category or menu item required")
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM menu_windows WHERE category_id IS NOT DISTINCT FROM $1::BIGINT AND menu_item_id IS NOT DISTINCT FROM $2::BIGINT
+	`, nullableInt64(categoryID), nullableInt64(menuItemID)); err != nil {
+		return err
+	}
+	for i := range windows {
+		w := &windows[i]
+		w.CategoryID, w.MenuItemID = categoryID, menuItemID
+		if err := tx.QueryRow(`
+			INSERT INTO menu_windows (category_id, menu_item_id, weekday, start_time, end_time)
+			VALUES ($1,$2,$3,$4,$5)
+			RETURNING id
+		`, nullableInt64(categoryID), nullableInt64(menuItemID), w.Weekday, w.StartTime, w.EndTime).Scan(&w.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listMenuWindows loads the windows of the given categories or items, in weekday order
+func (m *menuRepo) listMenuWindows(column string, ids []int64) ([]models.MenuWindow, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// column is "category_id" or "menu_item_id", never input
+	rows, err := m.db.Query(`
+		SELECT id, category_id, menu_item_id, weekday, start_time, end_time
+		FROM menu_windows WHERE `+column+` = ANY($1)
+		ORDER BY weekday, start_time, id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.MenuWindow
+	for rows.Next() {
+		var w models.MenuWindow
+		var categoryID, menuItemID sql.NullInt64
+		if err := rows.Scan(&w.ID, &categoryID, &menuItemID, &w.Weekday, &w.StartTime, &w.EndTime); err != nil {
+			return nil, err
+		}
+		if categoryID.Valid {
+			v := categoryID.Int64
+			w.CategoryID = &v
+		}
+		if menuItemID.Valid {
+			v := menuItemID.Int64
+			w.MenuItemID = &v
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// attachMenuWindows fills in the items' own windows and those of their categories
+func (m *menuRepo) attachMenuWindows(items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	itemIDs := make([]int64, 0, len(items))
+	var categoryIDs []int64
+	for _, itm := range items {
+		itemIDs = append(itemIDs, itm.ID)
+		if itm.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *itm.CategoryID)
+		}
+	}
+	own, err := m.listMenuWindows("menu_item_id", itemIDs)
+	if err != nil {
+		return err
+	}
+	byCategory, err := m.listMenuWindows("category_id", categoryIDs)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		itm := &items[i]
+		for _, w := range own {
+			if *w.MenuItemID == itm.ID {
+				itm.Windows = append(itm.Windows, w)
+			}
+		}
+		if itm.CategoryID == nil {
+			continue
+		}
+		for _, w := range byCategory {
+			if *w.CategoryID == *itm.CategoryID {
+				itm.CategoryWindows = append(itm.CategoryWindows, w)
+			}
+		}
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ type RestaurantRepo interface {
 	CreateTable(t *models.RestaurantTable) (*models.RestaurantTable, error)
 	GetTablesByRestaurant(restaurantID int64) ([]models.RestaurantTable, error)
 	GetTableByID(id int64) (*models.RestaurantTable, error)
+	GetTableByQRToken(token string) (*models.RestaurantTable, error)
 	UpdateTable(t *models.RestaurantTable) (*models.RestaurantTable, error)
 	DeleteTable(id int64) error
 }
@@ -60,18 +61,18 @@ func (r *restaurantRepo) Create(rest *models.Restaurant) (int64, error) {
 			owner_auth_user_id, name, slug, description, status,
 			address_line1, address_line2, city, state, pincode,
 			latitude, longitude, avg_rating, rating_count, tags, metadata,
-			created_at, updated_at
+			gstin, created_at, updated_at
 		) VALUES (
 			$1,$2,$3,$4,$5,
 			$6,$7,$8,$9,$10,
 			$11,$12,$13,$14,$15,$16,
-			$17,$18
+			$17,$18,$19
 		) RETURNING id
 	`,
 		rest.OwnerAuthUserID, rest.Name, rest.Slug, rest.Description, rest.Status,
 		rest.AddressLine1, rest.AddressLine2, rest.City, rest.State, rest.Pincode,
 		rest.Latitude, rest.Longitude, rest.AvgRating, rest.RatingCount, pq.Array(rest.Tags), meta,
-		rest.CreatedAt, rest.UpdatedAt,
+		nullString(rest.GSTIN), rest.CreatedAt, rest.UpdatedAt,
 	).Scan(&id)
 	if err != nil {
 		return 0, err
@@ -225,7 +226,7 @@ func (r *restaurantRepo) GetByID(id int64) (*models.Restaurant, error) {
 	query := `
 	SELECT id, owner_auth_user_id, name, slug, description, status,
 		   address_line1, address_line2, city, state, pincode,
-		   latitude, longitude, avg_rating, rating_count, tags, metadata, created_at, updated_at
+		   latitude, longitude, avg_rating, rating_count, tags, metadata, gstin, created_at, updated_at
 	FROM restaurants WHERE id=$1
 	`
 	var rest models.Restaurant
@@ -235,12 +236,13 @@ func (r *restaurantRepo) GetByID(id int64) (*models.Restaurant, error) {
 	var ratingCount sql.NullInt64
 	var tags pq.StringArray
 	var metadata sql.NullString
+	var gstin sql.NullString
 	var createdAt, updatedAt time.Time
 
 	err := r.db.QueryRow(query, id).Scan(
 		&rest.ID, &owner, &rest.Name, &rest.Slug, &rest.Description, &rest.Status,
 		&rest.AddressLine1, &rest.AddressLine2, &rest.City, &rest.State, &rest.Pincode,
-		&lat, &lon, &avgRating, &ratingCount, &tags, &metadata, &createdAt, &updatedAt,
+		&lat, &lon, &avgRating, &ratingCount, &tags, &metadata, &gstin, &createdAt, &updatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -274,6 +276,9 @@ func (r *restaurantRepo) GetByID(id int64) (*models.Restaurant, error) {
 	if metadata.Valid {
 		_ = json.Unmarshal([]byte(metadata.String), &rest.Metadata)
 	}
+	if gstin.Valid {
+		rest.GSTIN = gstin.String
+	}
 	rest.CreatedAt = &createdAt
 	rest.UpdatedAt = &updatedAt
 	return &rest, nil
@@ -292,13 +297,14 @@ func (r *restaurantRepo) Update(rest *models.Restaurant) error {
 	UPDATE restaurants SET
 		name=$1, slug=$2, description=$3, status=$4,
 		address_line1=$5, address_line2=$6, city=$7, state=$8, pincode=$9,
-		latitude=$10, longitude=$11, avg_rating=$12, rating_count=$13, tags=$14, metadata=$15, updated_at=$16
+		latitude=$10, longitude=$11, avg_rating=$12, rating_count=$13, tags=$14, metadata=$15, updated_at=$16,
+		gstin=$18
 	WHERE id=$17
 	`,
 		rest.Name, rest.Slug, rest.Description, rest.Status,
 		rest.AddressLine1, rest.AddressLine2, rest.City, rest.State, rest.Pincode,
 		rest.Latitude, rest.Longitude, rest.AvgRating, rest.RatingCount, pq.Array(rest.Tags), meta, rest.UpdatedAt,
-		rest.ID,
+		rest.ID, nullString(rest.GSTIN),
 	)
 	if err != nil {
 		return err
@@ -464,12 +470,21 @@ func (r *restaurantRepo) GetTablesByRestaurant(restaurantID int64) ([]models.Res
 }
 
 func (r *restaurantRepo) GetTableByID(id int64) (*models.RestaurantTable, error) {
+	return r.getTable(`id=$1`, id)
+}
+
+// GetTableByQRToken finds the table a scanned QR code belongs to
+func (r *restaurantRepo) GetTableByQRToken(token string) (*models.RestaurantTable, error) {
+	return r.getTable(`qr_token=$1`, token)
+}
+
+func (r *restaurantRepo) getTable(where string, arg interface{}) (*models.RestaurantTable, error) {
 	var t models.RestaurantTable
 	var qrToken, qrUrl sql.NullString
 	var createdAt time.Time
 	err := r.db.QueryRow(`
-	SELECT id, restaurant_id, table_identifier, seats, qr_token, qr_url, is_active, created_at FROM restaurant_tables WHERE id=$1
-	`, id).Scan(&t.ID, &t.RestaurantID, &t.TableIdentifier, &t.Seats, &qrToken, &qrUrl, &t.IsActive, &createdAt)
+	SELECT id, restaurant_id, table_identifier, seats, qr_token, qr_url, is_active, created_at FROM restaurant_tables WHERE `+where,
+		arg).Scan(&t.ID, &t.RestaurantID, &t.TableIdentifier, &t.Seats, &qrToken, &qrUrl, &t.IsActive, &createdAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
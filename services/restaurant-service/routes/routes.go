@@ -2,6 +2,8 @@ package routes
 
 import (
 	"database/sql"
+	"log"
+	"time"
 
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/controller"
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/middleware"
@@ -13,18 +15,24 @@ import (
 func Setup(r *gin.Engine, db *sql.DB) {
 	// repos
 	restRepo := repository.NewRestaurantRepo(db)
-	menuRepo := repository.NewMenuRepo(db)   // keep or implement separately
-	orderRepo := repository.NewOrderRepo(db) // keep or implement separately
+	menuRepo := repository.NewMenuRepo(db) // keep or implement separately
 
 	// services
 	restSvc := services.NewRestaurantService(restRepo)
-	menuSvc := services.NewMenuService(menuRepo)
-	orderSvc := services.NewOrderService(orderRepo, db)
+	menuSvc := services.NewMenuService(menuRepo, restRepo, db)
+
+	// sold out items with a restock time come back by themselves
+	go services.RunMenuRestocks(menuSvc, time.Minute)
 
 	// controllers
 	restC := controller.NewRestaurantController(restSvc)
 	menuC := controller.NewMenuController(menuSvc)
-	orderC := controller.NewOrderController(orderSvc)
+
+	// orders, carts and the rest of ordering live in order-service
+	orderP, err := controller.NewOrderProxy()
+	if err != nil {
+		log.Fatalf("invalid ORDER_SERVICE_URL: %v", err)
+	}
 
 	// restaurant routes
 	rest := r.Group("/restaurants")
@@ -53,16 +61,41 @@ func Setup(r *gin.Engine, db *sql.DB) {
 		auth.GET("/:id/tables", restC.ListTables)
 		auth.PUT("/:id/tables/:table_id", restC.UpdateTable)
 		auth.DELETE("/:id/tables/:table_id", restC.DeleteTable)
+
+		// menu: owners and admins only
+		auth.POST("/:id/categories", menuC.CreateCategory)
+		auth.POST("/:id/menu/items", menuC.CreateMenuItem)
+		auth.PUT("/:id/menu/items/:item_id", menuC.UpdateMenuItem)
+		auth.DELETE("/:id/menu/items/:item_id", menuC.DeleteMenuItem)
+		auth.PUT("/:id/menu/availability", menuC.SetAvailability)
+		auth.PUT("/:id/categories/:category_id/windows", menuC.SetCategoryWindows)
+		auth.PUT("/:id/menu/items/:item_id/windows", menuC.SetItemWindows)
+		auth.GET("/:id/menu/export", menuC.ExportMenu)
+		auth.POST("/:id/menu/import", menuC.ImportMenu)
+
+		// modifier groups (size, toppings) of a menu item
+		auth.POST("/:id/menu/items/:item_id/modifier-groups", menuC.CreateModifierGroup)
+		auth.PUT("/:id/menu/items/:item_id/modifier-groups/:group_id", menuC.UpdateModifierGroup)
+		auth.DELETE("/:id/menu/items/:item_id/modifier-groups/:group_id", menuC.DeleteModifierGroup)
 	}
 
-	// keep menu & order endpoints wiring if implemented elsewhere
-	rest.POST("/categories", menuC.CreateCategory)
+	// public menu; a logged in owner may ask for hidden items too
 	rest.GET("/:id/categories", menuC.GetCategories)
-	rest.POST("/:id/menu/items", menuC.CreateMenuItem)
-	rest.GET("/:id/menu/items", menuC.GetMenuItems)
+	rest.GET("/:id/menu/items", middleware.AuthOptional(), menuC.GetMenuItems)
+	rest.GET("/:id/menu/items/:item_id/modifier-groups", menuC.ListModifierGroups)
 
-	// orders / simple wiring example - implement order controller in order service file
-	r.POST("/orders", orderC.PlaceOrder)
-	r.GET("/orders/:id/status", orderC.GetStatus)
-	r.PUT("/orders/:id/status", orderC.UpdateStatus)
+	// a restaurant's orders, dine-in tabs, GST rates and offers are order-service's
+	for _, path := range []string{
+		"/:id/orders", "/:id/orders/stream", "/:id/dining-sessions",
+		"/:id/tax-rules", "/:id/tax-rules/:rule_id",
+		"/:id/promotions", "/:id/promotions/:promotion_id",
+	} {
+		rest.Any(path, orderP.Forward)
+	}
+	// and the rest of ordering; order-service checks tokens and idempotency keys itself
+	for _, prefix := range []string{"/orders", "/cart", "/group-carts", "/dining-sessions", "/dine-in", "/promotions", "/delivery-fee", "/riders"} {
+		r.Any(prefix, orderP.Forward)
+		r.Any(prefix+"/*path", orderP.Forward)
+	}
+	r.Any("/stream-tickets", orderP.Forward)
 }
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
@@ -13,20 +16,41 @@ type MenuService interface {
 	CreateCategory(cat *models.MenuCategory, tokenUserID int64, role string) (int64, error)
 	GetCategories(restaurantID int64) ([]models.MenuCategory, error)
 	CreateMenuItem(item *models.MenuItem, tokenUserID int64, role string) (int64, error)
-	GetMenuItems(restaurantID int64) ([]models.MenuItem, error)
+	GetMenuItems(restaurantID int64, includeHidden bool, at *time.Time, tokenUserID int64, role string) ([]models.MenuItem, error)
+	UpdateMenuItem(item *models.MenuItem, tokenUserID int64, role string) error
+	DeleteMenuItem(restaurantID, id int64, tokenUserID int64, role string) error
+	SetAvailability(restaurantID int64, ids []int64, availability string, restockAt *time.Time, tokenUserID int64, role string) ([]int64, error)
+	RestockDue() error
+
+	// serving windows, e.g. breakfast only in the morning
+	SetCategoryWindows(restaurantID, categoryID int64, windows []models.MenuWindow, tokenUserID int64, role string) ([]models.MenuWindow, error)
+	SetItemWindows(restaurantID, menuItemID int64, windows []models.MenuWindow, tokenUserID int64, role string) ([]models.MenuWindow, error)
+
+	// modifier groups of a menu item, e.g. size or toppings
+	ListModifierGroups(restaurantID, menuItemID int64) ([]models.ModifierGroup, error)
+	CreateModifierGroup(restaurantID int64, g *models.ModifierGroup, tokenUserID int64, role string) (*models.ModifierGroup, error)
+	UpdateModifierGroup(restaurantID int64, g *models.ModifierGroup, tokenUserID int64, role string) (*models.ModifierGroup, error)
+	DeleteModifierGroup(restaurantID, menuItemID, groupID int64, tokenUserID int64, role string) error
+
+	// the whole menu at once, keyed by SKU
+	ExportMenu(restaurantID int64, tokenUserID int64, role string) (*models.MenuDocument, error)
+	ImportMenu(restaurantID int64, doc *models.MenuDocument, dryRun bool, tokenUserID int64, role string) (*models.MenuImportReport, error)
 }
 
 type menuService struct {
-	repo repository.MenuRepo
-	// optionally inject restaurant repo for owner checks
+	repo     repository.MenuRepo
+	restRepo repository.RestaurantRepo // owner checks
+	db       *sql.DB
 }
 
-func NewMenuService(r repository.MenuRepo) MenuService {
-	return &menuService{repo: r}
+func NewMenuService(r repository.MenuRepo, restRepo repository.RestaurantRepo, db *sql.DB) MenuService {
+	return &menuService{repo: r, restRepo: restRepo, db: db}
 }
 
 func (s *menuService) CreateCategory(cat *models.MenuCategory, tokenUserID int64, role string) (int64, error) {
-	// TODO: optionally check ownership / role here (require ADMIN or restaurant owner)
+	if err := s.authorize(cat.RestaurantID, tokenUserID, role); err != nil {
+		return 0, err
+	}
 	cat.CreatedAt = timePtr(time.Now().UTC())
 	// default is active
 	if !cat.IsActive {
@@ -40,27 +64,193 @@ func (s *menuService) GetCategories(restaurantID int64) ([]models.MenuCategory,
 }
 
 func (s *menuService) CreateMenuItem(item *models.MenuItem, tokenUserID int64, role string) (int64, error) {
-	// TODO: owner/admin checks if needed (call restaurant repo)
+	if err := s.authorize(item.RestaurantID, tokenUserID, role); err != nil {
+		return 0, err
+	}
 	item.CreatedAt = timePtr(time.Now().UTC())
 	item.UpdatedAt = timePtr(time.Now().UTC())
+	if err := validateMenuItem(item); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateMenuItem(item)
+}
+
+/*
+GetMenuItems is the restaurant's menu as customers see it: items in stock and sold out
+that are served at at, or now when at is nil (pass the delivery time of a scheduled order).
+includeHidden is the owner's and admins' view: every item, whatever the time.
+*/
+func (s *menuService) GetMenuItems(restaurantID int64, includeHidden bool, at *time.Time, tokenUserID int64, role string) ([]models.MenuItem, error) {
+	if includeHidden {
+		if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+			return nil, err
+		}
+		return s.repo.GetMenuItems(restaurantID, true)
+	}
+	items, err := s.repo.GetMenuItems(restaurantID, false)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Now()
+	if at != nil {
+		t = *at
+	}
+	out := make([]models.MenuItem, 0, len(items))
+	for _, m := range items {
+		if servedAt(m, t) {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// UpdateMenuItem replaces an item's details; an empty availability keeps the current one
+func (s *menuService) UpdateMenuItem(item *models.MenuItem, tokenUserID int64, role string) error {
+	if err := s.authorize(item.RestaurantID, tokenUserID, role); err != nil {
+		return err
+	}
+	current, err := s.menuItem(item.RestaurantID, item.ID)
+	if err != nil {
+		return err
+	}
+	if item.Availability == "" {
+		item.Availability = current.Availability
+	}
+	item.ModifierGroups = current.ModifierGroups // edited on their own endpoints
+	if err := validateMenuItem(item); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateMenuItem(item); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("not_found")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteMenuItem is a soft delete: the item leaves the menu, carts and reorders, past orders keep it
+func (s *menuService) DeleteMenuItem(restaurantID, id int64, tokenUserID int64, role string) error {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return err
+	}
+	found, err := s.repo.DeleteMenuItem(restaurantID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("not_found")
+	}
+	return nil
+}
+
+/*
+SetAvailability switches many items at once, e.g. everything with paneer is sold out.
+restockAt, only for OUT_OF_STOCK, brings them back in stock by itself. It returns the ids
+that changed; ids that are not the restaurant's items are left out.
+*/
+func (s *menuService) SetAvailability(restaurantID int64, ids []int64, availability string, restockAt *time.Time, tokenUserID int64, role string) ([]int64, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 || len(ids) > maxAvailabilityBatch {
+		return nil, errors.New("items_required")
+	}
+	availability = strings.ToUpper(strings.TrimSpace(availability))
+	if !validAvailability(availability) {
+		return nil, errors.New("invalid_availability")
+	}
+	if restockAt != nil && (availability != models.AvailabilityOutOfStock || !restockAt.After(time.Now())) {
+		return nil, errors.New("invalid_restock")
+	}
+	return s.repo.SetAvailability(restaurantID, ids, availability, restockAt)
+}
+
+// RestockDue brings back sold out items whose restock time has passed
+func (s *menuService) RestockDue() error {
+	n, err := s.repo.RestockDue(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("menu restock: %d item(s) back in stock", n)
+	}
+	return nil
+}
+
+// RunMenuRestocks calls RestockDue every interval, forever
+func RunMenuRestocks(svc MenuService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := svc.RestockDue(); err != nil {
+			log.Printf("menu restock: %v", err)
+		}
+	}
+}
+
+// menuItem loads a menu item of the restaurant; not_found for other restaurants' items
+func (s *menuService) menuItem(restaurantID, menuItemID int64) (*models.MenuItem, error) {
+	items, err := s.repo.GetMenuItemsByIDs([]int64{menuItemID})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 || items[0].RestaurantID != restaurantID {
+		return nil, errors.New("not_found")
+	}
+	return &items[0], nil
+}
+
+// authorize lets the restaurant's owner and admins change its menu
+func (s *menuService) authorize(restaurantID int64, tokenUserID int64, role string) error {
+	rest, err := s.restRepo.GetByID(restaurantID)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		return errors.New("not_found")
+	}
+	upper := strings.ToUpper(role)
+	if rest.OwnerAuthUserID == nil || (*rest.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// most items one availability request switches
+const maxAvailabilityBatch = 500
+
+func validAvailability(v string) bool {
+	switch v {
+	case models.AvailabilityInStock, models.AvailabilityOutOfStock, models.AvailabilityHidden:
+		return true
+	}
+	return false
+}
+
+func validateMenuItem(item *models.MenuItem) error {
 	if item.Currency == "" {
 		item.Currency = "INR"
 	}
 	if item.Availability == "" {
-		item.Availability = "IN_STOCK"
+		item.Availability = models.AvailabilityInStock
 	}
-	// minimal validation
+	item.Availability = strings.ToUpper(item.Availability)
+	item.Name = strings.TrimSpace(item.Name)
 	if item.Name == "" {
-		return 0, errors.New("name required")
+		return errors.New("name_required")
 	}
 	if item.Price < 0 {
-		return 0, errors.New("price must be >= 0")
+		return errors.New("invalid_price")
 	}
-	return s.repo.CreateMenuItem(item)
-}
-
-func (s *menuService) GetMenuItems(restaurantID int64) ([]models.MenuItem, error) {
-	return s.repo.GetMenuItems(restaurantID)
+	if !validAvailability(item.Availability) {
+		return errors.New("invalid_availability")
+	}
+	// a restock time only means something while the item is sold out
+	if item.RestockAt != nil && (item.Availability != models.AvailabilityOutOfStock || !item.RestockAt.After(time.Now())) {
+		return errors.New("invalid_restock")
+	}
+	return nil
 }
 
 /* helpers */
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+)
+
+/*
+A menu CSV has one row per category, item and modifier option, told apart by record_type
+(CATEGORY, ITEM, MODIFIER). A MODIFIER row is an option of the group named in group, on
+the item whose SKU is in parent_sku; its price is the option's price delta and is_active
+whether it can be picked. min_select and max_select are read from a group's first row.
+Tags are separated by "|". Columns may come in any order and unknown ones are ignored.
+*/
+var menuCSVColumns = []string{
+	"record_type", "sku", "parent_sku", "name", "description", "price", "currency", "availability", "is_veg",
+	"spice_level", "prep_time_minutes", "tags", "image_url", "sort_order", "is_active", "group", "min_select", "max_select",
+}
+
+const (
+	menuCSVCategory = "CATEGORY"
+	menuCSVItem     = "ITEM"
+	menuCSVModifier = "MODIFIER"
+)
+
+// RenderMenuCSV writes an exported menu as CSV, each item followed by its options
+func RenderMenuCSV(doc *models.MenuDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(menuCSVColumns)
+	row := func(values map[string]string) {
+		rec := make([]string, len(menuCSVColumns))
+		for i, col := range menuCSVColumns {
+			rec[i] = values[col]
+		}
+		_ = w.Write(rec)
+	}
+	for _, c := range doc.Categories {
+		active := c.IsActive == nil || *c.IsActive
+		row(map[string]string{
+			"record_type": menuCSVCategory,
+			"sku":         c.SKU,
+			"parent_sku":  c.ParentSKU,
+			"name":        c.Name,
+			"sort_order":  strconv.Itoa(c.SortOrder),
+			"is_active":   strconv.FormatBool(active),
+		})
+	}
+	for _, m := range doc.Items {
+		row(map[string]string{
+			"record_type":       menuCSVItem,
+			"sku":               m.SKU,
+			"parent_sku":        m.CategorySKU,
+			"name":              m.Name,
+			"description":       m.Description,
+			"price":             strconv.FormatFloat(m.Price, 'f', -1, 64),
+			"currency":          m.Currency,
+			"availability":      m.Availability,
+			"is_veg":            strconv.FormatBool(m.IsVeg),
+			"spice_level":       strconv.Itoa(m.SpiceLevel),
+			"prep_time_minutes": strconv.Itoa(m.PrepTimeMinutes),
+			"tags":              strings.Join(m.Tags, "|"),
+			"image_url":         m.ImageURL,
+		})
+		for _, g := range m.ModifierGroups {
+			for _, o := range g.Options {
+				available := o.IsAvailable == nil || *o.IsAvailable
+				row(map[string]string{
+					"record_type": menuCSVModifier,
+					"parent_sku":  m.SKU,
+					"group":       g.Name,
+					"min_select":  strconv.Itoa(g.MinSelect),
+					"max_select":  strconv.Itoa(g.MaxSelect),
+					"name":        o.Name,
+					"price":       strconv.FormatFloat(o.PriceDelta, 'f', -1, 64),
+					"is_active":   strconv.FormatBool(available),
+				})
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+/*
+ParseMenuCSV reads a menu CSV into a document for ImportMenu. Values that do not parse,
+e.g. a price that is not a number, are reported on the document's Problems with their
+line rather than failing the read; an error means the file is not a menu CSV at all.
+*/
+func ParseMenuCSV(r io.Reader) (*models.MenuDocument, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv_header_required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	colAt := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		colAt[h] = i
+	}
+	if _, ok := colAt["record_type"]; !ok {
+		return nil, errors.New("csv_header_required")
+	}
+
+	doc := &models.MenuDocument{}
+	itemAt := make(map[string]int)
+	type groupKey struct{ item, group string }
+	groupAt := make(map[groupKey]int)
+	type modifierRow struct {
+		line   int
+		values func(string) string
+	}
+	var modifiers []modifierRow
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(col string) string {
+			if i, ok := colAt[col]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		p := &menuCSVRow{doc: doc, line: line, get: get}
+		switch strings.ToUpper(get("record_type")) {
+		case menuCSVCategory:
+			p.section = "categories"
+			p.sku = get("sku")
+			c := models.MenuDocumentCategory{
+				Row:       line,
+				SKU:       p.sku,
+				ParentSKU: get("parent_sku"),
+				Name:      get("name"),
+				SortOrder: p.intValue("sort_order"),
+				IsActive:  p.boolValue("is_active"),
+			}
+			doc.Categories = append(doc.Categories, c)
+		case menuCSVItem:
+			p.section = "items"
+			p.sku = get("sku")
+			m := models.MenuDocumentItem{
+				Row:             line,
+				SKU:             p.sku,
+				CategorySKU:     get("parent_sku"),
+				Name:            get("name"),
+				Description:     get("description"),
+				Price:           p.floatValue("price"),
+				Currency:        get("currency"),
+				Availability:    get("availability"),
+				SpiceLevel:      p.intValue("spice_level"),
+				PrepTimeMinutes: p.intValue("prep_time_minutes"),
+				ImageURL:        get("image_url"),
+			}
+			if get("price") == "" {
+				p.problem("price", "price is required")
+			}
+			if v := p.boolValue("is_veg"); v != nil {
+				m.IsVeg = *v
+			}
+			for _, t := range strings.Split(get("tags"), "|") {
+				if t = strings.TrimSpace(t); t != "" {
+					m.Tags = append(m.Tags, t)
+				}
+			}
+			if _, dup := itemAt[m.SKU]; !dup && m.SKU != "" {
+				itemAt[m.SKU] = len(doc.Items)
+			}
+			doc.Items = append(doc.Items, m)
+		case menuCSVModifier:
+			// items may come after their options
+			modifiers = append(modifiers, modifierRow{line: line, values: get})
+		case "":
+			// blank line
+		default:
+			doc.Problems = append(doc.Problems, models.MenuImportProblem{Section: "file", Row: line, Field: "record_type", Reason: "record_type must be CATEGORY, ITEM or MODIFIER"})
+		}
+	}
+
+	for _, mr := range modifiers {
+		get := mr.values
+		p := &menuCSVRow{doc: doc, line: mr.line, get: get, section: "modifiers", sku: get("parent_sku")}
+		i, ok := itemAt[p.sku]
+		if !ok {
+			doc.Problems = append(doc.Problems, models.MenuImportProblem{Section: "modifiers", Row: mr.line, SKU: p.sku, Field: "parent_sku", Reason: "no item in the file has this sku"})
+			continue
+		}
+		item := &doc.Items[i]
+		key := groupKey{p.sku, strings.ToLower(get("group"))}
+		gi, ok := groupAt[key]
+		if !ok {
+			gi = len(item.ModifierGroups)
+			groupAt[key] = gi
+			item.ModifierGroups = append(item.ModifierGroups, models.MenuDocumentModifiers{
+				Row:       mr.line,
+				Name:      get("group"),
+				MinSelect: p.intValue("min_select"),
+				MaxSelect: p.intValue("max_select"),
+			})
+		}
+		g := &item.ModifierGroups[gi]
+		g.Options = append(g.Options, models.MenuDocumentOption{Name: get("name"), PriceDelta: p.floatValue("price"), IsAvailable: p.boolValue("is_active")})
+	}
+	return doc, nil
+}
+
+// menuCSVRow parses a row's values, reporting the ones that do not parse
+type menuCSVRow struct {
+	doc     *models.MenuDocument
+	line    int
+	section string
+	sku     string
+	get     func(string) string
+}
+
+func (p *menuCSVRow) problem(field, reason string) {
+	p.doc.Problems = append(p.doc.Problems, models.MenuImportProblem{Section: p.section, Row: p.line, SKU: p.sku, Field: field, Reason: reason})
+}
+
+func (p *menuCSVRow) intValue(col string) int {
+	v := p.get(col)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		p.problem(col, "not a whole number")
+	}
+	return n
+}
+
+func (p *menuCSVRow) floatValue(col string) float64 {
+	v := p.get(col)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		p.problem(col, "not a number")
+		return 0
+	}
+	return f
+}
+
+// boolValue is nil for an empty value, so the default applies
+func (p *menuCSVRow) boolValue(col string) *bool {
+	v := p.get(col)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(strings.ToLower(v))
+	if err != nil {
+		p.problem(col, "must be true or false")
+		return nil
+	}
+	return &b
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/repository"
+)
+
+// most categories, items and modifier options one import may carry
+const maxMenuImportRows = 5000
+
+// external_sku column size
+const maxSKULength = 64
+
+/*
+ExportMenu is the restaurant's whole menu, hidden items included, in the form imports
+take. Categories and items without an SKU of their own get MC-<id> and MI-<id>, which
+an import matches back to them.
+*/
+func (s *menuService) ExportMenu(restaurantID int64, tokenUserID int64, role string) (*models.MenuDocument, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	cats, err := s.repo.GetCategories(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetMenuItems(restaurantID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	catSKU := make(map[int64]string, len(cats))
+	for _, c := range cats {
+		catSKU[c.ID] = categorySKU(c)
+	}
+	doc := &models.MenuDocument{
+		Categories: make([]models.MenuDocumentCategory, 0, len(cats)),
+		Items:      make([]models.MenuDocumentItem, 0, len(items)),
+	}
+	for _, c := range cats {
+		active := c.IsActive
+		dc := models.MenuDocumentCategory{SKU: catSKU[c.ID], Name: c.Name, SortOrder: c.SortOrder, IsActive: &active}
+		if c.ParentID != nil {
+			dc.ParentSKU = catSKU[*c.ParentID]
+		}
+		doc.Categories = append(doc.Categories, dc)
+	}
+	for _, m := range items {
+		di := models.MenuDocumentItem{
+			SKU:             itemSKU(m),
+			Name:            m.Name,
+			Description:     m.Description,
+			Price:           m.Price,
+			Currency:        m.Currency,
+			Availability:    m.Availability,
+			IsVeg:           m.IsVeg,
+			SpiceLevel:      m.SpiceLevel,
+			PrepTimeMinutes: m.PrepTimeMinutes,
+			Tags:            m.Tags,
+			ImageURL:        m.ImageURL,
+		}
+		if m.CategoryID != nil {
+			di.CategorySKU = catSKU[*m.CategoryID]
+		}
+		for _, g := range m.ModifierGroups {
+			dg := models.MenuDocumentModifiers{Name: g.Name, MinSelect: g.MinSelect, MaxSelect: g.MaxSelect, Options: make([]models.MenuDocumentOption, 0, len(g.Options))}
+			for _, o := range g.Options {
+				available := o.IsAvailable
+				dg.Options = append(dg.Options, models.MenuDocumentOption{Name: o.Name, PriceDelta: o.PriceDelta, IsAvailable: &available})
+			}
+			di.ModifierGroups = append(di.ModifierGroups, dg)
+		}
+		doc.Items = append(doc.Items, di)
+	}
+	return doc, nil
+}
+
+// plannedCategory and plannedItem are import rows checked and ready to save
+type plannedCategory struct {
+	cat       models.MenuCategory
+	parentSKU string
+}
+
+type plannedItem struct {
+	item        models.MenuItem
+	categorySKU string
+	groups      []models.ModifierGroup
+	current     []models.ModifierGroup // the item's groups before the import
+}
+
+/*
+ImportMenu creates and updates categories, items and their modifier groups from doc,
+matching them by SKU; whatever the file leaves out stays as it is, except that an item's
+modifier groups become exactly the ones listed for it. Nothing is saved when any row has
+a problem, and with dryRun nothing is saved at all; the report lists the problems and
+what would change. An item imported without an availability keeps its current one.
+*/
+func (s *menuService) ImportMenu(restaurantID int64, doc *models.MenuDocument, dryRun bool, tokenUserID int64, role string) (*models.MenuImportReport, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	rows := len(doc.Categories) + len(doc.Items)
+	for _, di := range doc.Items {
+		for _, g := range di.ModifierGroups {
+			rows += len(g.Options)
+		}
+	}
+	if rows == 0 {
+		return nil, errors.New("empty_import")
+	}
+	if rows > maxMenuImportRows {
+		return nil, errors.New("too_many_rows")
+	}
+
+	cats, err := s.repo.GetCategories(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetMenuItems(restaurantID, true)
+	if err != nil {
+		return nil, err
+	}
+	catBySKU := make(map[string]models.MenuCategory, len(cats))
+	catSKU := make(map[int64]string, len(cats))
+	for _, c := range cats {
+		catSKU[c.ID] = categorySKU(c)
+		catBySKU[catSKU[c.ID]] = c
+	}
+	itemBySKU := make(map[string]models.MenuItem, len(items))
+	for _, m := range items {
+		itemBySKU[itemSKU(m)] = m
+	}
+
+	report := &models.MenuImportReport{DryRun: dryRun, Problems: append([]models.MenuImportProblem{}, doc.Problems...)}
+	problem := func(section string, row int, sku, field, reason string) {
+		report.Problems = append(report.Problems, models.MenuImportProblem{Section: section, Row: row, SKU: sku, Field: field, Reason: reason})
+	}
+
+	// categories; parentOf is every category's parent as it will be after the import
+	parentOf := make(map[string]string, len(cats)+len(doc.Categories))
+	for _, c := range cats {
+		parentOf[catSKU[c.ID]] = ""
+		if c.ParentID != nil {
+			parentOf[catSKU[c.ID]] = catSKU[*c.ParentID]
+		}
+	}
+	var plannedCats []plannedCategory
+	catRow := make(map[string]int, len(doc.Categories))
+	for i := range doc.Categories {
+		dc := &doc.Categories[i]
+		if dc.Row == 0 {
+			dc.Row = i + 1
+		}
+		dc.SKU = strings.TrimSpace(dc.SKU)
+		dc.ParentSKU = strings.TrimSpace(dc.ParentSKU)
+		if reason := skuProblem(dc.SKU); reason != "" {
+			problem("categories", dc.Row, dc.SKU, "sku", reason)
+			continue
+		}
+		if _, dup := catRow[dc.SKU]; dup {
+			problem("categories", dc.Row, dc.SKU, "sku", "sku appears more than once")
+			continue
+		}
+		catRow[dc.SKU] = dc.Row
+		parentOf[dc.SKU] = dc.ParentSKU
+
+		cat, exists := catBySKU[dc.SKU]
+		if !exists {
+			cat = models.MenuCategory{RestaurantID: restaurantID, IsActive: true}
+		}
+		cat.ExternalSKU = dc.SKU
+		cat.Name = strings.TrimSpace(dc.Name)
+		cat.SortOrder = dc.SortOrder
+		if dc.IsActive != nil {
+			cat.IsActive = *dc.IsActive
+		}
+		if cat.Name == "" {
+			problem("categories", dc.Row, dc.SKU, "name", "name is required")
+			continue
+		}
+		if exists {
+			report.CategoriesUpdated++
+		} else {
+			report.CategoriesCreated++
+		}
+		plannedCats = append(plannedCats, plannedCategory{cat: cat, parentSKU: dc.ParentSKU})
+	}
+	for _, pc := range plannedCats {
+		sku := pc.cat.ExternalSKU
+		if pc.parentSKU == "" {
+			continue
+		}
+		if _, ok := parentOf[pc.parentSKU]; !ok {
+			problem("categories", catRow[sku], sku, "parent_sku", "no category has this sku")
+			continue
+		}
+		for p, n := pc.parentSKU, 0; p != "" && n <= len(parentOf); p, n = parentOf[p], n+1 {
+			if p == sku {
+				problem("categories", catRow[sku], sku, "parent_sku", "category would be its own parent")
+				break
+			}
+		}
+	}
+
+	// items and their modifier groups
+	var plannedItems []plannedItem
+	seen := make(map[string]bool, len(doc.Items))
+	for i := range doc.Items {
+		di := &doc.Items[i]
+		if di.Row == 0 {
+			di.Row = i + 1
+		}
+		di.SKU = strings.TrimSpace(di.SKU)
+		di.CategorySKU = strings.TrimSpace(di.CategorySKU)
+		if reason := skuProblem(di.SKU); reason != "" {
+			problem("items", di.Row, di.SKU, "sku", reason)
+			continue
+		}
+		if seen[di.SKU] {
+			problem("items", di.Row, di.SKU, "sku", "sku appears more than once")
+			continue
+		}
+		seen[di.SKU] = true
+
+		ok := true
+		m := models.MenuItem{
+			RestaurantID:    restaurantID,
+			ExternalSKU:     di.SKU,
+			Name:            di.Name,
+			Description:     strings.TrimSpace(di.Description),
+			Price:           di.Price,
+			Currency:        strings.ToUpper(strings.TrimSpace(di.Currency)),
+			Availability:    strings.TrimSpace(di.Availability),
+			IsVeg:           di.IsVeg,
+			SpiceLevel:      di.SpiceLevel,
+			PrepTimeMinutes: di.PrepTimeMinutes,
+			ImageURL:        strings.TrimSpace(di.ImageURL),
+		}
+		for _, t := range di.Tags {
+			if t = strings.TrimSpace(t); t != "" {
+				m.Tags = append(m.Tags, t)
+			}
+		}
+		current, exists := itemBySKU[di.SKU]
+		if exists {
+			m.ID = current.ID
+			if m.Availability == "" {
+				m.Availability = current.Availability
+			}
+			// still sold out: keep coming back in stock as planned
+			if strings.EqualFold(m.Availability, models.AvailabilityOutOfStock) && current.RestockAt != nil && current.RestockAt.After(time.Now()) {
+				m.RestockAt = current.RestockAt
+			}
+		}
+		if err := validateMenuItem(&m); err != nil {
+			field, reason := importProblem(err)
+			problem("items", di.Row, di.SKU, field, reason)
+			ok = false
+		}
+		if di.CategorySKU != "" {
+			if _, found := parentOf[di.CategorySKU]; !found {
+				problem("items", di.Row, di.SKU, "category_sku", "no category has this sku")
+				ok = false
+			}
+		}
+
+		groups := make([]models.ModifierGroup, 0, len(di.ModifierGroups))
+		names := make(map[string]bool, len(di.ModifierGroups))
+		for gi, dg := range di.ModifierGroups {
+			row := dg.Row
+			if row == 0 {
+				row = gi + 1
+			}
+			g := models.ModifierGroup{Name: dg.Name, MinSelect: dg.MinSelect, MaxSelect: dg.MaxSelect, SortOrder: gi}
+			for oi, o := range dg.Options {
+				available := true
+				if o.IsAvailable != nil {
+					available = *o.IsAvailable
+				}
+				g.Options = append(g.Options, models.ModifierOption{Name: o.Name, PriceDelta: o.PriceDelta, IsAvailable: available, SortOrder: oi})
+			}
+			if err := validateModifierGroup(&g); err != nil {
+				field, reason := importProblem(err)
+				problem("modifiers", row, di.SKU, field, reason)
+				ok = false
+				continue
+			}
+			if names[strings.ToLower(g.Name)] {
+				problem("modifiers", row, di.SKU, "group", "group appears more than once for the item")
+				ok = false
+				continue
+			}
+			names[strings.ToLower(g.Name)] = true
+			groups = append(groups, g)
+		}
+		if !ok {
+			continue
+		}
+		if exists {
+			report.ItemsUpdated++
+		} else {
+			report.ItemsCreated++
+		}
+		plannedItems = append(plannedItems, plannedItem{item: m, categorySKU: di.CategorySKU, groups: groups, current: current.ModifierGroups})
+	}
+
+	if len(report.Problems) > 0 || dryRun {
+		return report, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	catIDs := make(map[string]int64, len(cats)+len(plannedCats))
+	for sku, c := range catBySKU {
+		catIDs[sku] = c.ID
+	}
+	if err := s.saveImport(tx, plannedCats, plannedItems, catIDs); err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, repository.ErrSKUTaken) {
+			return nil, errors.New("sku_conflict")
+		}
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// saveImport writes checked rows: categories parents first, then items with their modifier groups
+func (s *menuService) saveImport(tx *sql.Tx, cats []plannedCategory, items []plannedItem, catIDs map[string]int64) error {
+	pending := make(map[string]bool, len(cats))
+	for _, pc := range cats {
+		pending[pc.cat.ExternalSKU] = true
+	}
+	for len(cats) > 0 {
+		var later []plannedCategory
+		for _, pc := range cats {
+			if pending[pc.parentSKU] {
+				later = append(later, pc)
+				continue
+			}
+			pc.cat.ParentID = nil
+			if pc.parentSKU != "" {
+				id := catIDs[pc.parentSKU]
+				pc.cat.ParentID = &id
+			}
+			if err := s.repo.SaveCategory(tx, &pc.cat); err != nil {
+				return err
+			}
+			catIDs[pc.cat.ExternalSKU] = pc.cat.ID
+			delete(pending, pc.cat.ExternalSKU)
+		}
+		if len(later) == len(cats) {
+			return errors.New("category parents form a cycle")
+		}
+		cats = later
+	}
+
+	for _, pi := range items {
+		m := pi.item
+		if pi.categorySKU != "" {
+			id := catIDs[pi.categorySKU]
+			m.CategoryID = &id
+		}
+		if err := s.repo.SaveMenuItem(tx, &m); err != nil {
+			return err
+		}
+		// groups and options keep their ids by name, so carts and reorders that picked them still work
+		keep := make([]int64, 0, len(pi.groups))
+		for _, g := range pi.groups {
+			g.MenuItemID = m.ID
+			for _, cur := range pi.current {
+				if strings.EqualFold(cur.Name, g.Name) {
+					g.ID = cur.ID
+					for i := range g.Options {
+						for _, o := range cur.Options {
+							if strings.EqualFold(o.Name, g.Options[i].Name) {
+								g.Options[i].ID = o.ID
+							}
+						}
+					}
+					break
+				}
+			}
+			save := s.repo.CreateModifierGroup
+			if g.ID != 0 {
+				save = s.repo.UpdateModifierGroup
+			}
+			if err := save(tx, &g); err != nil {
+				return err
+			}
+			keep = append(keep, g.ID)
+		}
+		if err := s.repo.DeleteModifierGroupsExcept(tx, m.ID, keep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func categorySKU(c models.MenuCategory) string {
+	if c.ExternalSKU != "" {
+		return c.ExternalSKU
+	}
+	return fmt.Sprintf("MC-%d", c.ID)
+}
+
+func itemSKU(m models.MenuItem) string {
+	if m.ExternalSKU != "" {
+		return m.ExternalSKU
+	}
+	return fmt.Sprintf("MI-%d", m.ID)
+}
+
+func skuProblem(sku string) string {
+	switch {
+	case sku == "":
+		return "sku is required"
+	case len(sku) > maxSKULength:
+		return fmt.Sprintf("sku is longer than %d characters", maxSKULength)
+	}
+	return ""
+}
+
+// importProblem puts a menu validation error as a field and a reason for the report
+func importProblem(err error) (string, string) {
+	switch err.Error() {
+	case "name_required":
+		return "name", "name is required"
+	case "invalid_price":
+		return "price", "price must be >= 0"
+	case "invalid_availability":
+		return "availability", "availability must be IN_STOCK, OUT_OF_STOCK or HIDDEN"
+	case "options_required":
+		return "options", "a group needs at least one option"
+	case "invalid_selection_limits":
+		return "max_select", "need 0 <= min_select <= max_select <= number of options, max_select at least 1"
+	case "duplicate_option":
+		return "name", "option names must be unique within a group"
+	case "invalid_price_delta":
+		return "price", "price delta must be >= 0"
+	}
+	return "", err.Error()
+}
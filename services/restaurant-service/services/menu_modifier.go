@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+)
+
+func (s *menuService) ListModifierGroups(restaurantID, menuItemID int64) ([]models.ModifierGroup, error) {
+	if _, err := s.menuItem(restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+	groups, err := s.repo.ListModifierGroups([]int64{menuItemID})
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		groups = []models.ModifierGroup{}
+	}
+	return groups, nil
+}
+
+func (s *menuService) CreateModifierGroup(restaurantID int64, g *models.ModifierGroup, tokenUserID int64, role string) (*models.ModifierGroup, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	if _, err := s.menuItem(restaurantID, g.MenuItemID); err != nil {
+		return nil, err
+	}
+	if err := validateModifierGroup(g); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateModifierGroup(tx, g); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+/*
+UpdateModifierGroup replaces a group and its options. Options sent with their id keep it,
+so past orders and carts that picked them still reorder; options left out are deleted.
+*/
+func (s *menuService) UpdateModifierGroup(restaurantID int64, g *models.ModifierGroup, tokenUserID int64, role string) (*models.ModifierGroup, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	if _, err := s.menuItem(restaurantID, g.MenuItemID); err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetModifierGroup(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.MenuItemID != g.MenuItemID {
+		return nil, errors.New("not_found")
+	}
+	if err := validateModifierGroup(g); err != nil {
+		return nil, err
+	}
+	known := make(map[int64]bool, len(current.Options))
+	for _, o := range current.Options {
+		known[o.ID] = true
+	}
+	for _, o := range g.Options {
+		if o.ID != 0 && !known[o.ID] {
+			return nil, errors.New("unknown_option")
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateModifierGroup(tx, g); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *menuService) DeleteModifierGroup(restaurantID, menuItemID, groupID int64, tokenUserID int64, role string) error {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return err
+	}
+	if _, err := s.menuItem(restaurantID, menuItemID); err != nil {
+		return err
+	}
+	found, err := s.repo.DeleteModifierGroup(menuItemID, groupID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("not_found")
+	}
+	return nil
+}
+
+func validateModifierGroup(g *models.ModifierGroup) error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return errors.New("name_required")
+	}
+	if len(g.Options) == 0 {
+		return errors.New("options_required")
+	}
+	if g.MinSelect < 0 || g.MaxSelect < 1 || g.MinSelect > g.MaxSelect || g.MaxSelect > len(g.Options) {
+		return errors.New("invalid_selection_limits")
+	}
+	names := make(map[string]bool, len(g.Options))
+	for i := range g.Options {
+		o := &g.Options[i]
+		o.Name = strings.TrimSpace(o.Name)
+		if o.Name == "" {
+			return errors.New("name_required")
+		}
+		if names[strings.ToLower(o.Name)] {
+			return errors.New("duplicate_option")
+		}
+		names[strings.ToLower(o.Name)] = true
+		if o.PriceDelta < 0 {
+			return errors.New("invalid_price_delta")
+		}
+		o.PriceDelta = roundMoney(o.PriceDelta)
+	}
+	return nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
+	"github.com/Gursevak56/food-delivery-platform/services/shared/businesstime"
+)
+
+// most windows a category or item may have, a few per weekday
+const maxMenuWindows = 28
+
+// SetCategoryWindows replaces when a category is served; no windows means all day
+func (s *menuService) SetCategoryWindows(restaurantID, categoryID int64, windows []models.MenuWindow, tokenUserID int64, role string) ([]models.MenuWindow, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	cats, err := s.repo.GetCategories(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, c := range cats {
+		if c.ID == categoryID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("not_found")
+	}
+	return s.replaceWindows(&categoryID, nil, windows)
+}
+
+// SetItemWindows replaces when an item is served; its category's windows apply as well
+func (s *menuService) SetItemWindows(restaurantID, menuItemID int64, windows []models.MenuWindow, tokenUserID int64, role string) ([]models.MenuWindow, error) {
+	if err := s.authorize(restaurantID, tokenUserID, role); err != nil {
+		return nil, err
+	}
+	if _, err := s.menuItem(restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+	return s.replaceWindows(nil, &menuItemID, windows)
+}
+
+func (s *menuService) replaceWindows(categoryID, menuItemID *int64, windows []models.MenuWindow) ([]models.MenuWindow, error) {
+	if err := validateMenuWindows(windows); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceMenuWindows(tx, categoryID, menuItemID, windows); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if windows == nil {
+		windows = []models.MenuWindow{}
+	}
+	return windows, nil
+}
+
+func validateMenuWindows(windows []models.MenuWindow) error {
+	if len(windows) > maxMenuWindows {
+		return errors.New("too_many_windows")
+	}
+	for i := range windows {
+		w := &windows[i]
+		start, ok1 := businesstime.ParseTimeOfDay(w.StartTime)
+		end, ok2 := businesstime.ParseTimeOfDay(w.EndTime)
+		if w.Weekday < 0 || w.Weekday > 6 || !ok1 || !ok2 {
+			return errors.New("invalid_window")
+		}
+		w.StartTime = formatTimeOfDay(start)
+		w.EndTime = formatTimeOfDay(end)
+	}
+	return nil
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04:05")
+}
+
+/*
+servedAt reports whether a menu item is served at t: inside one of its own windows and
+one of its category's. Either list being empty means no limit from that side.
+*/
+func servedAt(m models.MenuItem, t time.Time) bool {
+	return inMenuWindows(m.Windows, t) && inMenuWindows(m.CategoryWindows, t)
+}
+
+func inMenuWindows(windows []models.MenuWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if businesstime.InWeeklyWindow(w.Weekday, w.StartTime, w.EndTime, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/Gursevak56/food-delivery-platform/services/restaurant-service/models"
@@ -49,6 +50,9 @@ func (s *restaurantService) CreateRestaurant(req *models.Restaurant, tokenUserID
 	if req.Slug == "" {
 		req.Slug = slugify(req.Name)
 	}
+	if err := normalizeGSTIN(req); err != nil {
+		return 0, err
+	}
 	// set owner
 	req.OwnerAuthUserID = &tokenUserID
 
@@ -75,6 +79,9 @@ func (s *restaurantService) UpdateRestaurant(req *models.Restaurant, tokenUserID
 	if existing.OwnerAuthUserID == nil || (*existing.OwnerAuthUserID != tokenUserID && !strings.Contains(upper, "ADMIN")) {
 		return errors.New("forbidden")
 	}
+	if err := normalizeGSTIN(req); err != nil {
+		return err
+	}
 	// prevent changing owner via update
 	req.OwnerAuthUserID = existing.OwnerAuthUserID
 	return s.repo.Update(req)
@@ -226,6 +233,18 @@ func (s *restaurantService) DeleteTable(tableID int64, tokenUserID int64, role s
 
 /* helpers */
 
+// state code, PAN, entity number, "Z", checksum character
+var gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+// normalizeGSTIN upper-cases the registration number and rejects malformed ones; empty is allowed
+func normalizeGSTIN(req *models.Restaurant) error {
+	req.GSTIN = strings.ToUpper(strings.TrimSpace(req.GSTIN))
+	if req.GSTIN != "" && !gstinPattern.MatchString(req.GSTIN) {
+		return errors.New("invalid_gstin")
+	}
+	return nil
+}
+
 func slugify(s string) string {
 	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(s, " ", "-")))
 }
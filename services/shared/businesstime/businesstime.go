@@ -0,0 +1,74 @@
+/*
+Package businesstime holds the clock restaurants run on: the zone their business day
+rolls over in and the weekly windows (opening hours, menu and offer windows) they set.
+restaurant-service and order-service both read those windows, so they share the rules.
+*/
+package businesstime
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // the alpine runtime image ships without zoneinfo
+)
+
+// Location is the zone in which a restaurant's business day rolls over
+var Location = loadLocation()
+
+func loadLocation() *time.Location {
+	name := os.Getenv("BUSINESS_TIMEZONE")
+	if name == "" {
+		name = "Asia/Kolkata"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("invalid BUSINESS_TIMEZONE %q, falling back to UTC: %v", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// Day returns the calendar day t falls on in the business timezone
+func Day(t time.Time) time.Time {
+	y, m, d := t.In(Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location)
+}
+
+/*
+InWeeklyWindow reports whether t falls in weekday's start-end window, read in Location.
+Weekday is 0 for Sunday. A window that ends at or before its start runs past midnight
+into the next day; unparsable times never match.
+*/
+func InWeeklyWindow(weekday int, start, end string, t time.Time) bool {
+	open, ok1 := ParseTimeOfDay(start)
+	close, ok2 := ParseTimeOfDay(end)
+	if !ok1 || !ok2 {
+		return false
+	}
+	local := t.In(Location)
+	wd := int(local.Weekday())
+	prev := (wd + 6) % 7
+	tod := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+
+	overnight := close <= open
+	switch {
+	case weekday == wd && !overnight && tod >= open && tod < close:
+		return true
+	case weekday == wd && overnight && tod >= open:
+		return true
+	case weekday == prev && overnight && tod < close:
+		return true
+	}
+	return false
+}
+
+// ParseTimeOfDay reads "15:04:05" or "15:04" as an offset from midnight
+func ParseTimeOfDay(v string) (time.Duration, bool) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,138 @@
+/*
+Package idempotency lets clients retry requests safely with an Idempotency-Key header. It
+is shared by the services that take such keys; each keeps them in an idempotency_keys
+table of its own database and ships the migration that creates it.
+*/
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// where the services' auth middleware puts the caller's user id and role
+const (
+	ContextUserIDKey = "auth_user_id"
+	ContextRoleKey   = "auth_role"
+)
+
+// serviceRole is the role of backend services calling each other; they may act for nobody in particular
+const serviceRole = "SERVICE"
+
+const (
+	Header = "Idempotency-Key"
+	// keys are remembered for a day, long enough for any client retry loop
+	idempotencyTTL    = 24 * time.Hour
+	maxIdempotencyKey = 255
+)
+
+// responseRecorder keeps a copy of what the handler writes so it can be replayed
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+/*
+Middleware makes a handler safe to retry when the client sends an Idempotency-Key header.
+Keys are scoped per authenticated user, so it must run after the auth middleware; a key
+on a request without a user is refused rather than shared by everyone, except from a
+SERVICE caller, whose keys are its own references (user 0 stands for "no user"). The first
+successful (2xx) response is stored and replayed for any retry with the same body;
+reusing a key with a different body, or while the first request is still running, is a 409.
+Failed responses release the key so the client can fix the request and try again.
+Requests without the header pass straight through.
+*/
+func Middleware(store Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Idempotency-Key too long"})
+			return
+		}
+
+		var userID int64
+		if raw, ok := c.Get(ContextUserIDKey); ok && raw != nil {
+			userID = raw.(int64)
+		}
+		role := ""
+		if raw, ok := c.Get(ContextRoleKey); ok && raw != nil {
+			role = strings.ToUpper(raw.(string))
+		}
+		if userID == 0 && role != serviceRole {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Idempotency-Key needs an authenticated request"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to read body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"\n"), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		existing, err := store.Acquire(userID, key, hash, idempotencyTTL)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "idempotency store unavailable", "error": err.Error()})
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != hash:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "Idempotency-Key already used with a different request"})
+			case existing.StatusCode == 0:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "request with this Idempotency-Key is still in progress"})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		// a panicking handler must not leave the key stuck "in progress"
+		defer func() {
+			if p := recover(); p != nil {
+				_ = store.Release(userID, key)
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+
+		status := rec.Status()
+		if status >= 200 && status < 300 {
+			err = store.Complete(userID, key, status, rec.body.Bytes())
+		} else {
+			err = store.Release(userID, key)
+		}
+		if err != nil {
+			log.Printf("idempotency: failed to finalise key %q for user %d: %v", key, userID, err)
+		}
+	}
+}
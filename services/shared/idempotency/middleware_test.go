@@ -0,0 +1,193 @@
+package idempotency
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memStore is a Store kept in memory
+type memStore struct {
+	records map[string]*Record
+	err     error
+}
+
+func newMemStore() *memStore {
+	return &memStore{records: map[string]*Record{}}
+}
+
+func memKey(userID int64, key string) string { return fmt.Sprintf("%d/%s", userID, key) }
+
+func (s *memStore) Acquire(userID int64, key, requestHash string, ttl time.Duration) (*Record, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if rec, ok := s.records[memKey(userID, key)]; ok {
+		cp := *rec
+		return &cp, nil
+	}
+	s.records[memKey(userID, key)] = &Record{UserID: userID, Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+	return nil, nil
+}
+
+func (s *memStore) Complete(userID int64, key string, statusCode int, body []byte) error {
+	rec := s.records[memKey(userID, key)]
+	rec.StatusCode = statusCode
+	rec.ResponseBody = append([]byte(nil), body...)
+	return nil
+}
+
+func (s *memStore) Release(userID int64, key string) error {
+	delete(s.records, memKey(userID, key))
+	return nil
+}
+
+type idemRequest struct {
+	body         string
+	wantStatus   int
+	wantReplayed bool
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		key           string
+		userID        int64
+		role          string
+		handlerStatus int
+		storeErr      error
+		inProgress    bool // the key is already taken by a request that has not finished
+		requests      []idemRequest
+		wantCalls     int
+	}{
+		{
+			name:          "no key passes through every time",
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusCreated, false}, {`{"a":1}`, http.StatusCreated, false}},
+			wantCalls:     2,
+		},
+		{
+			name:          "retry replays the stored response",
+			key:           "k1",
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusCreated, false}, {`{"a":1}`, http.StatusCreated, true}},
+			wantCalls:     1,
+		},
+		{
+			name:          "same key with another body is a conflict",
+			key:           "k1",
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusCreated, false}, {`{"a":2}`, http.StatusConflict, false}},
+			wantCalls:     1,
+		},
+		{
+			name:          "key still in progress is a conflict",
+			key:           "k1",
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			inProgress:    true,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusConflict, false}},
+			wantCalls:     0,
+		},
+		{
+			name:          "failed response releases the key",
+			key:           "k1",
+			userID:        7,
+			handlerStatus: http.StatusUnprocessableEntity,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusUnprocessableEntity, false}, {`{"a":1}`, http.StatusUnprocessableEntity, false}},
+			wantCalls:     2,
+		},
+		{
+			name:          "anonymous key is refused",
+			key:           "k1",
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusUnauthorized, false}},
+			wantCalls:     0,
+		},
+		{
+			name:          "service caller may use a key without a user",
+			key:           "k1",
+			role:          "service",
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusCreated, false}, {`{"a":1}`, http.StatusCreated, true}},
+			wantCalls:     1,
+		},
+		{
+			name:          "key too long",
+			key:           strings.Repeat("k", maxIdempotencyKey+1),
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			requests:      []idemRequest{{`{"a":1}`, http.StatusBadRequest, false}},
+			wantCalls:     0,
+		},
+		{
+			name:          "store failure",
+			key:           "k1",
+			userID:        7,
+			handlerStatus: http.StatusCreated,
+			storeErr:      errors.New("connection refused"),
+			requests:      []idemRequest{{`{"a":1}`, http.StatusInternalServerError, false}},
+			wantCalls:     0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemStore()
+			store.err = tc.storeErr
+			if tc.inProgress {
+				store.records[memKey(tc.userID, tc.key)] = &Record{UserID: tc.userID, Key: tc.key, RequestHash: "other"}
+			}
+
+			calls := 0
+			r := gin.New()
+			r.POST("/orders", func(c *gin.Context) {
+				if tc.userID != 0 {
+					c.Set(ContextUserIDKey, tc.userID)
+				}
+				if tc.role != "" {
+					c.Set(ContextRoleKey, tc.role)
+				}
+			}, Middleware(store), func(c *gin.Context) {
+				calls++
+				c.JSON(tc.handlerStatus, gin.H{"call": calls})
+			})
+
+			var first string
+			for i, req := range tc.requests {
+				httpReq := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(req.body))
+				if tc.key != "" {
+					httpReq.Header.Set(Header, tc.key)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httpReq)
+
+				if w.Code != req.wantStatus {
+					t.Fatalf("request %d: status %d, want %d (%s)", i, w.Code, req.wantStatus, w.Body.String())
+				}
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != req.wantReplayed {
+					t.Errorf("request %d: replayed %v, want %v", i, replayed, req.wantReplayed)
+				}
+				if i == 0 {
+					first = w.Body.String()
+				} else if req.wantReplayed && w.Body.String() != first {
+					t.Errorf("request %d: replayed body %q, want %q", i, w.Body.String(), first)
+				}
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
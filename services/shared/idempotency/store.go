@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Record is a stored Idempotency-Key and, once finished, the response it produced
+type Record struct {
+	UserID       int64
+	Key          string
+	RequestHash  string
+	StatusCode   int // 0 while the first request is still running
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+type Store interface {
+	// Acquire claims (userID, key) for a new request. It returns (nil, nil) when the
+	// caller now owns the key, or the existing record when the key was already used.
+	Acquire(userID int64, key, requestHash string, ttl time.Duration) (*Record, error)
+	Complete(userID int64, key string, statusCode int, body []byte) error
+	Release(userID int64, key string) error
+}
+
+type store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) Store {
+	return &store{db: db}
+}
+
+func (s *store) Acquire(userID int64, key, requestHash string, ttl time.Duration) (*Record, error) {
+	now := time.Now().UTC()
+
+	// insert, or take over a row that has expired
+	var claimed int64
+	err := s.db.QueryRow(`
+		INSERT INTO idempotency_keys (user_id, idem_key, request_hash, status_code, response_body, created_at)
+		VALUES ($1,$2,$3,NULL,NULL,$4)
+		ON CONFLICT (user_id, idem_key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL, created_at = EXCLUDED.created_at
+			WHERE idempotency_keys.created_at < $5
+		RETURNING user_id
+	`, userID, key, requestHash, now, now.Add(-ttl)).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	rec := Record{UserID: userID, Key: key}
+	var status sql.NullInt64
+	var body []byte
+	err = s.db.QueryRow(`
+		SELECT request_hash, status_code, response_body, created_at
+		FROM idempotency_keys WHERE user_id=$1 AND idem_key=$2
+	`, userID, key).Scan(&rec.RequestHash, &status, &body, &rec.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if status.Valid {
+		rec.StatusCode = int(status.Int64)
+	}
+	rec.ResponseBody = body
+	return &rec, nil
+}
+
+func (s *store) Complete(userID int64, key string, statusCode int, body []byte) error {
+	_, err := s.db.Exec(`
+		UPDATE idempotency_keys SET status_code=$1, response_body=$2 WHERE user_id=$3 AND idem_key=$4
+	`, statusCode, body, userID, key)
+	return err
+}
+
+func (s *store) Release(userID int64, key string) error {
+	_, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE user_id=$1 AND idem_key=$2`, userID, key)
+	return err
+}